@@ -3,6 +3,7 @@ package main
 import (
 	"EduSync/internal/config"
 	"EduSync/internal/delivery/http"
+	auditHandle "EduSync/internal/delivery/http/audit"
 	chat3 "EduSync/internal/delivery/http/chat"
 	email3 "EduSync/internal/delivery/http/email"
 	favorite2 "EduSync/internal/delivery/http/favorite"
@@ -14,24 +15,30 @@ import (
 	subjectHandler "EduSync/internal/delivery/http/subject"
 	"EduSync/internal/delivery/http/user"
 	"EduSync/internal/delivery/ws"
-	groupParser "EduSync/internal/integration/parser/rksi/group"
-	scheduleParser "EduSync/internal/integration/parser/rksi/schedule"
-	teacherParser "EduSync/internal/integration/parser/rksi/teacher"
+	"EduSync/internal/integration/provider"
+	rksiProvider "EduSync/internal/integration/provider/rksi"
+	uploadProvider "EduSync/internal/integration/provider/upload"
+	"EduSync/internal/repository"
+	auditRepository "EduSync/internal/repository/audit"
 	"EduSync/internal/repository/chat"
 	email2 "EduSync/internal/repository/email"
 	favoriteRepository "EduSync/internal/repository/favorite"
 	groupRepository "EduSync/internal/repository/group"
 	institutionRepository "EduSync/internal/repository/institution"
 	materialRepository "EduSync/internal/repository/material"
+	ratelimitRepository "EduSync/internal/repository/ratelimit"
 	scheduleRepository "EduSync/internal/repository/schedule"
 	subjectRepository "EduSync/internal/repository/subject"
 	userRepository "EduSync/internal/repository/user"
+	"EduSync/internal/service"
+	auditServ "EduSync/internal/service/audit"
 	chat2 "EduSync/internal/service/chat"
 	"EduSync/internal/service/email"
 	"EduSync/internal/service/favorite"
 	groupServ "EduSync/internal/service/group"
 	institutionServ "EduSync/internal/service/institution"
 	materialServ "EduSync/internal/service/material"
+	ratelimitServ "EduSync/internal/service/ratelimit"
 	scheduleServ "EduSync/internal/service/schedule"
 	subjectServ "EduSync/internal/service/subject"
 	userService "EduSync/internal/service/user"
@@ -74,14 +81,29 @@ func main() {
 	// Применяем миграции
 	config.ApplyMigrations(db, logger)
 
-	// Инициализируем JWTManager с секретным ключом
-	jwtManager := util.NewJWTManager(cfg.JWTSecret)
+	// Инициализируем JWTManager: общий секрет HS256 или ключи RS256/EdDSA из каталога
+	var jwtManager *util.JWTManager
+	if cfg.JWTAlgorithm == util.AlgHS256 {
+		logger.Warn("JWT подписываются общим секретом HS256, JWKS будет пустым")
+		jwtManager = util.NewJWTManager(cfg.JWTSecret)
+	} else {
+		jwtKeys, err := util.LoadKeySet(cfg.JWTKeysDir, cfg.JWTAlgorithm, logger)
+		if err != nil {
+			logger.Fatalf("Ошибка загрузки ключей JWT: %v", err)
+		}
+		if cfg.JWTKeyRotation > 0 {
+			jwtKeys.StartRotation(cfg.JWTKeyRotation)
+		}
+		jwtManager = util.NewKeySetJWTManager(jwtKeys)
+	}
 
 	// Инициализируем репозитории и сервисы
 	userRepo := userRepository.NewUserRepository(db)
 	studentRepo := userRepository.NewStudentRepository(db)
 	teacherRepo := userRepository.NewTeacherRepository(db)
 	tokenRepo := userRepository.NewTokenRepository(db)
+	twoFactorRepo := userRepository.NewTwoFactorRepository(db)
+	exportRepo := userRepository.NewExportRepository(db)
 	groupRepo := groupRepository.NewGroupRepository(db)
 	subjectRepo := subjectRepository.NewSubjectRepository(db)
 	scheduleRepo := scheduleRepository.NewScheduleRepository(db)
@@ -94,66 +116,120 @@ func main() {
 	favoriteRepo := favoriteRepository.NewFileFavoriteRepository(db)
 	pollRepo := chat.NewPollRepository(db)
 	emailRepo := email2.NewEmailConfirmationsRepository(db)
+	emailOutboxRepo := email2.NewOutboxRepository(db)
+	emailChangeRepo := email2.NewEmailChangeRepository(db)
+	calendarTokenRepo := scheduleRepository.NewCalendarTokenRepository(db)
+	scheduleImportRepo := scheduleRepository.NewScheduleImportRepository(db)
+	classroomRepo := scheduleRepository.NewClassroomRepository(db)
+	bellRepo := scheduleRepository.NewBellScheduleRepository(db)
+	auditRepo := auditRepository.NewAuditRepository(db)
+
+	// Счётчики попыток: в памяти для одного экземпляра, в Postgres — общие для нескольких
+	var rateLimitRepo repository.RateLimitRepository
+	if cfg.RateLimitBackend == "postgres" {
+		rateLimitRepo = ratelimitRepository.NewPostgresRepository(db)
+	} else {
+		rateLimitRepo = ratelimitRepository.NewMemoryRepository()
+	}
+
+	// Источники расписания: учреждение выбирает свой в institutions.schedule_provider
+	providers := provider.NewRegistry()
+	providers.Register(rksiProvider.Kind, rksiProvider.NewFactory(cfg.UrlParserRKSI, logger))
+	providers.Register(uploadProvider.Kind, uploadProvider.NewFactory(scheduleImportRepo))
 
-	groupParse := groupParser.NewGroupParser(cfg.UrlParserRKSI, logger)
-	teacherParse := teacherParser.NewTeacherParser(cfg.UrlParserRKSI, logger)
-	scheduleParse := scheduleParser.NewScheduleParser(cfg.UrlParserRKSI, logger)
-	subjectService := subjectServ.NewSubjectService(subjectRepo, logger)
+	auditSvc := auditServ.NewAuditService(auditRepo, logger)
+	limiter := ratelimitServ.NewLimiter(rateLimitRepo, logger)
+	limiter.StartCleanup(time.Hour)
+	subjectService := subjectServ.NewSubjectService(subjectRepo, auditSvc, logger)
 	teacherInitionalsService := scheduleServ.NewTeacherInitialsService(teacherInitionalsRepo, logger)
-	emailSvc := email.NewSMTPEmailService(
-		cfg.SMTPHost, cfg.SMTPPort,
-		cfg.SMTPUser, cfg.SMTPPassword,
-		cfg.FromEmail,
-	)
-	emailConfirmSVC := email.NewConfirmationService(emailRepo, userRepo, emailSvc, cfg.BaseURL)
+	calendarTokenService := scheduleServ.NewCalendarTokenService(calendarTokenRepo, logger)
+	classroomService := scheduleServ.NewClassroomService(classroomRepo, logger)
+	// Транспорт писем: SMTP, а для разработки — файлы .eml или лог
+	var emailSvc service.EmailService
+	switch cfg.EmailTransport {
+	case "file":
+		if emailSvc, err = email.NewFileEmailService(cfg.EmailFileDir); err != nil {
+			logger.Fatalf("Ошибка настройки транспорта писем: %v", err)
+		}
+	case "log":
+		emailSvc = email.NewLogEmailService(logger)
+	default:
+		emailSvc = email.NewSMTPEmailService(
+			cfg.SMTPHost, cfg.SMTPPort,
+			cfg.SMTPUser, cfg.SMTPPassword,
+			cfg.FromEmail,
+		)
+	}
+	outboxSvc := email.NewOutboxService(emailOutboxRepo, emailSvc, logger)
+	outboxSvc.StartWorker(10 * time.Second)
+	emailTemplates, err := email.NewRenderer(cfg.EmailTemplatesDir)
+	if err != nil {
+		logger.Fatalf("Ошибка загрузки шаблонов писем: %v", err)
+	}
+	mailer := email.NewMailer(emailTemplates, emailOutboxRepo, studentRepo, teacherRepo, institutionRepo, logger)
+	emailConfirmSVC := email.NewConfirmationService(emailRepo, userRepo, mailer, cfg.BaseURL)
 	authService := userService.NewAuthService(userRepo,
 		studentRepo,
 		teacherRepo,
 		tokenRepo,
+		twoFactorRepo,
+		exportRepo,
+		emailChangeRepo,
 		emailMaskRepo,
 		emailConfirmSVC,
+		mailer,
+		auditSvc,
+		limiter,
 		jwtManager,
 		logger,
 	)
 	materialService := materialServ.NewFileService(materialRepo, messageRepo, chatRepo, logger)
-	groupService := groupServ.NewGroupService(groupRepo, groupParse, logger)
+	groupService := groupServ.NewGroupService(groupRepo, institutionRepo, providers, auditSvc, logger)
+	hub := ws.NewHub()
+
 	scheduleService := scheduleServ.NewScheduleService(
 		scheduleRepo,
-		scheduleParse,
-		teacherParse,
+		scheduleImportRepo,
+		institutionRepo,
+		providers,
 		subjectService,
 		authService,
 		groupRepo,
 		teacherInitionalsRepo,
+		classroomRepo,
+		bellRepo,
+		hub,
 		logger,
 	)
 
-	hub := ws.NewHub()
-
-	chatSvc := chat2.NewChatService(chatRepo, subjectRepo, userRepo, logger)
-	messageSvc := chat2.NewMessageService(messageRepo, logger, hub)
+	chatSvc := chat2.NewChatService(chatRepo, messageRepo, subjectRepo, userRepo, logger, hub)
+	messageSvc := chat2.NewMessageService(messageRepo, chatRepo, userRepo, auditSvc, mailer, logger, hub)
 	favoriteSvc := favorite.NewFileFavoriteService(favoriteRepo, materialRepo, messageRepo, chatRepo, logger)
-	emailMaskSvc := institutionServ.NewEmailMaskService(emailMaskRepo, logger)
+	emailMaskSvc := institutionServ.NewEmailMaskService(emailMaskRepo, auditSvc, logger)
 	pollSvc := chat2.NewPollService(pollRepo, chatRepo, logger, hub)
 
 	subjectHandle := subjectHandler.NewInstitutionHandler(subjectService)
 	authHandler := user.NewAuthHandler(authService)
 	groupHandle := groupHandler.NewGroupHandler(groupService)
 	go groupService.StartWorker(24 * time.Hour)
+	authService.StartPurgeWorker(time.Hour)
 	go scheduleService.StartWorkerInitials(24 * time.Hour)
 	go scheduleService.StartWorker(2 * time.Hour * 24)
-	institutionService := institutionServ.NewInstitutionService(institutionRepo, logger)
+	institutionService := institutionServ.NewInstitutionService(institutionRepo, providers, auditSvc, logger)
 	institutionHandler := institutionHandle.NewInstitutionHandler(institutionService, emailMaskSvc)
-	scheduleHandler := schedule2.NewScheduleHandler(scheduleService)
+	scheduleHandler := schedule2.NewScheduleHandler(scheduleService, calendarTokenService)
 	chatHandler := chat3.NewChatHandler(chatSvc)
 	messageHandler := chat4.NewMessageHandler(messageSvc)
 	materialHandler := materialHand.NewFileHandler(materialService)
 	teacherInitionalsHandler := schedule2.NewTeacherInitialsHandler(teacherInitionalsService)
+	classroomHandler := schedule2.NewClassroomHandler(classroomService)
 	favoriteHandler := favorite2.NewFileFavoriteHandler(favoriteSvc)
 	pollHandler := chat3.NewPollHandler(pollSvc)
 	emailHandler := email3.NewConfirmationHandler(emailConfirmSVC)
+	outboxHandler := email3.NewOutboxHandler(outboxSvc)
+	auditHandler := auditHandle.NewAuditHandler(auditSvc)
 	// Настраиваем маршруты через отдельную функцию в delivery слое
-	router := http.SetupRouter(tokenRepo, chatRepo,
+	router := http.SetupRouter(tokenRepo, chatRepo, calendarTokenRepo,
 		authHandler,
 		jwtManager,
 		groupHandle,
@@ -164,9 +240,13 @@ func main() {
 		messageHandler,
 		materialHandler,
 		teacherInitionalsHandler,
+		classroomHandler,
 		favoriteHandler,
 		pollHandler,
 		emailHandler,
+		outboxHandler,
+		auditHandler,
+		limiter,
 		logger,
 		hub,
 	)
@@ -2,26 +2,42 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"github.com/joho/godotenv"
 	"log"
 	"os"
 	"strconv"
+	"time"
 )
 
+// defaultJWTSecret — секрет из примера конфигурации, годится только для разработки.
+const defaultJWTSecret = "default-secret-key"
+
 // Config хранит настройки приложения
 type Config struct {
 	ServerPort    string
 	DatabaseURL   string
 	LogLevel      string
-	JWTSecret     []byte
+	AppEnv        string // dev — режим разработки
 	UrlParserRKSI string
 
+	JWTSecret      []byte
+	JWTAlgorithm   string        // HS256, RS256 или EdDSA
+	JWTKeysDir     string        // каталог ключей RS256/EdDSA
+	JWTKeyRotation time.Duration // 0 — без ротации
+
+	RateLimitBackend string // memory — в памяти процесса, postgres — общий для всех экземпляров
+
 	SMTPHost     string
 	SMTPPort     int
 	SMTPUser     string
 	SMTPPassword string
 	FromEmail    string
 	BaseURL      string
+
+	EmailTemplatesDir string // шаблоны писем, переопределяющие встроенные; пусто — только встроенные
+	EmailTransport    string // smtp, file (письма в EmailFileDir) или log
+	EmailFileDir      string
 }
 
 // LoadConfig загружает конфигурацию из .env или переменных окружения
@@ -33,8 +49,37 @@ func LoadConfig() (*Config, error) {
 	cfg := &Config{
 		ServerPort:    getEnv("SERVER_PORT", "8080"),
 		LogLevel:      getEnv("LOG_LEVEL", "info"),
-		JWTSecret:     []byte(getEnv("JWT_SECRET", "default-secret-key")),
+		AppEnv:        getEnv("APP_ENV", "production"),
 		UrlParserRKSI: getEnv("URL_PARSER_RKSI", ""),
+
+		JWTSecret:    []byte(getEnv("JWT_SECRET", defaultJWTSecret)),
+		JWTAlgorithm: getEnv("JWT_ALGORITHM", "EdDSA"),
+		JWTKeysDir:   getEnv("JWT_KEYS_DIR", "keys/jwt"),
+
+		RateLimitBackend: getEnv("RATE_LIMIT_BACKEND", "memory"),
+	}
+
+	rotation, err := time.ParseDuration(getEnv("JWT_KEY_ROTATION", "720h"))
+	if err != nil {
+		return nil, fmt.Errorf("неверный JWT_KEY_ROTATION: %w", err)
+	}
+	cfg.JWTKeyRotation = rotation
+
+	switch cfg.JWTAlgorithm {
+	case "RS256", "EdDSA":
+	case "HS256":
+		// С общеизвестным секретом токен может подделать кто угодно
+		secret := string(cfg.JWTSecret)
+		if !cfg.IsDev() && (secret == "" || secret == defaultJWTSecret) {
+			return nil, errors.New("JWT_SECRET не задан или равен значению по умолчанию: задайте свой секрет, " +
+				"выберите JWT_ALGORITHM=RS256/EdDSA или запустите с APP_ENV=dev")
+		}
+	default:
+		return nil, fmt.Errorf("неподдерживаемый JWT_ALGORITHM: %s", cfg.JWTAlgorithm)
+	}
+
+	if cfg.RateLimitBackend != "memory" && cfg.RateLimitBackend != "postgres" {
+		return nil, fmt.Errorf("неподдерживаемый RATE_LIMIT_BACKEND: %s", cfg.RateLimitBackend)
 	}
 
 	smtpPort, _ := strconv.Atoi(getEnv("SMTP_PORT", "587"))
@@ -45,6 +90,14 @@ func LoadConfig() (*Config, error) {
 	cfg.SMTPPassword = getEnv("SMTP_PASSWORD", "")
 	cfg.FromEmail = getEnv("SMTP_FROM", "no-reply@edusync.ru")
 	cfg.BaseURL = getEnv("APP_BASE_URL", "https://edusync.ru")
+	cfg.EmailTemplatesDir = getEnv("EMAIL_TEMPLATES_DIR", "")
+	cfg.EmailTransport = getEnv("EMAIL_TRANSPORT", "smtp")
+	cfg.EmailFileDir = getEnv("EMAIL_FILE_DIR", "tmp/emails")
+	switch cfg.EmailTransport {
+	case "smtp", "file", "log":
+	default:
+		return nil, fmt.Errorf("неподдерживаемый EMAIL_TRANSPORT: %s", cfg.EmailTransport)
+	}
 
 	// Формируем DatabaseURL из компонентов
 	dbUser := getEnv("DB_USER", "")
@@ -62,6 +115,11 @@ func LoadConfig() (*Config, error) {
 	return cfg, nil
 }
 
+// IsDev сообщает, запущен ли сервер в режиме разработки.
+func (c *Config) IsDev() bool {
+	return c.AppEnv == "dev"
+}
+
 // formatDatabaseURL формирует URL для подключения к БД
 func formatDatabaseURL(user, pass, host, port, dbname string) string {
 	auth := user
@@ -14,6 +14,14 @@ type CreateChatRequest struct {
 	SubjectID int `json:"subject_id" binding:"required"`
 }
 
+// MarkReadRequest модель отметки прочтения
+// swagger:model
+type MarkReadRequest struct {
+	// Последнее прочитанное сообщение; 0 или отсутствует — последнее сообщение чата
+	// example: 120
+	MessageID int `json:"message_id" binding:"omitempty,min=0"`
+}
+
 // ChatInfo модель чата
 // swagger:model
 type ChatInfo struct {
@@ -40,6 +48,17 @@ type ChatInfo struct {
 	// Название предмета
 	// example: УП
 	SubjectName string `json:"subject_name"`
+
+	// Сколько чужих сообщений пользователь ещё не прочитал
+	// example: 3
+	UnreadCount int `json:"unread_count"`
+
+	// Последнее прочитанное пользователем сообщение, 0 — ничего не прочитано
+	// example: 117
+	LastReadMessageID int `json:"last_read_message_id"`
+
+	// Последнее сообщение чата
+	LastMessage *domainChat.Message `json:"last_message,omitempty"`
 }
 
 func ConvertChatToDTO(chat *domainChat.Chat) *ChatInfo {
@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"EduSync/internal/delivery/middleware"
+	domainAudit "EduSync/internal/domain/audit"
+	"EduSync/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditHandler отдает журнал административных действий.
+type AuditHandler struct {
+	auditService service.AuditService
+}
+
+// NewAuditHandler создает обработчик журнала.
+func NewAuditHandler(auditService service.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// ListHandler возвращает журнал изменений
+// @Summary      Журнал изменений
+// @Description  Кто и когда менял учреждения, почтовые маски, группы, предметы и роли. Администратор учреждения видит только своё учреждение
+// @Tags         Admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        institution_id  query  int     false  "ID учреждения (для системного администратора)"
+// @Param        entity          query  string  false  "Тип сущности: institution, email_mask, group, subject, user"
+// @Param        entity_id       query  int     false  "ID сущности"
+// @Param        limit           query  int     false  "Количество записей (по умолчанию 50, не больше 200)"
+// @Param        offset          query  int     false  "Смещение"
+// @Success      200  {array}   audit.Entry
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/audit [get]
+func (h *AuditHandler) ListHandler(c *gin.Context) {
+	f := domainAudit.Filter{Entity: domainAudit.Entity(c.Query("entity"))}
+	for name, dst := range map[string]*int{
+		"institution_id": &f.InstitutionID,
+		"entity_id":      &f.EntityID,
+		"limit":          &f.Limit,
+		"offset":         &f.Offset,
+	} {
+		v := c.Query(name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный параметр " + name})
+			return
+		}
+		*dst = n
+	}
+
+	entries, err := h.auditService.List(c.Request.Context(), middleware.Actor(c), f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if entries == nil {
+		entries = []*domainAudit.Entry{}
+	}
+	c.JSON(http.StatusOK, entries)
+}
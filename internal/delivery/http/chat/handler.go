@@ -3,6 +3,7 @@ package chat
 import (
 	chatDTO "EduSync/internal/delivery/dto/chat"
 	domainChat "EduSync/internal/domain/chat"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -307,10 +308,50 @@ func (h *ChatHandler) LeaveChatHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Вы покинули чат"})
 }
 
+// MarkReadHandler отмечает сообщения прочитанными
+// @Summary      Отметить прочитанным
+// @Description  Сдвигает курсор прочтения текущего пользователя до message_id включительно; без message_id — до последнего сообщения. Курсор не двигается назад. Участники чата получают событие message:read с числом прочитавших
+// @Tags         Chats
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id     path  int              true   "ID чата"
+// @Param        input  body  MarkReadRequest  false  "Последнее прочитанное сообщение"
+// @Success      200  {object}  ReadReceipt
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /chats/{id}/read [post]
+func (h *ChatHandler) MarkReadHandler(c *gin.Context) {
+	chatID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор чата"})
+		return
+	}
+	var req chatDTO.MarkReadRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+			return
+		}
+	}
+
+	rr, err := h.chatService.MarkRead(c.Request.Context(), chatID, c.GetInt("user_id"), req.MessageID)
+	if errors.Is(err, domainChat.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Сообщение не найдено в чате"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rr)
+}
+
 // ListChatsHandler отдаёт все чаты для текущего пользователя.
 // swagger:route GET /chats Chats listChats
 // @Summary      Список чатов
-// @Description  Возвращает все чаты, в которых участвует текущий пользователь. Если пользователь — преподаватель, возвращаются все чаты, которые он создал или к которым присоединился.
+// @Description  Возвращает все чаты, в которых участвует текущий пользователь, с последним сообщением и числом непрочитанных. Если пользователь — преподаватель, возвращаются все чаты, которые он создал или к которым присоединился.
 // @Tags         Chats
 // @Security     BearerAuth
 // @Produce      json
@@ -24,10 +24,11 @@ func NewConfirmationHandler(svc service.ConfirmationService) *ConfirmationHandle
 // @Param        input  body  object{action=string}  true "Действие"
 // @Success      200  {object}  object{message=string}
 // @Failure      400  {object}  object{error=string}
+// @Failure      429  {object}  object{error=string}
 // @Router       /confirm/request [post]
 func (h *ConfirmationHandler) RequestCode(c *gin.Context) {
 	var req struct {
-		Action string `json:"action" binding:"required,oneof=register reset_password delete_account"`
+		Action string `json:"action" binding:"required,oneof=register reset_password"`
 		Email  string `json:"email" binding:"email"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -48,20 +49,22 @@ func (h *ConfirmationHandler) RequestCode(c *gin.Context) {
 // @Tags         Confirmation
 // @Accept       json
 // @Produce      json
-// @Param        input  body  object{action=string,code=string}  true "действие и код"
+// @Param        input  body  object{action=string,email=string,code=string}  true "действие, email и код"
 // @Success      200  {object}  object{message=string}
 // @Failure      400  {object}  object{error=string}
+// @Failure      429  {object}  object{error=string}
 // @Router       /confirm/verify [post]
 func (h *ConfirmationHandler) VerifyCode(c *gin.Context) {
 	var req struct {
-		Action string `json:"action" binding:"required,oneof=register reset_password delete_account"`
+		Action string `json:"action" binding:"required,oneof=register reset_password"`
+		Email  string `json:"email" binding:"required,email"`
 		Code   string `json:"code" binding:"required,len=6"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if err := h.svc.VerifyCode(c.Request.Context(), req.Action, req.Code, nil); err != nil {
+	if err := h.svc.VerifyEmailCode(c.Request.Context(), req.Email, req.Action, req.Code); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -79,6 +82,7 @@ func (h *ConfirmationHandler) VerifyCode(c *gin.Context) {
 // @Param code    query string true "Код активации"
 // @Success 200 {object} object{message=string}
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
 // @Router /confirm/activate [get]
 func (h *ConfirmationHandler) Activate(c *gin.Context) {
 	uid, err := strconv.Atoi(c.Query("user_id"))
@@ -91,7 +95,7 @@ func (h *ConfirmationHandler) Activate(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "code required"})
 		return
 	}
-	if err := h.svc.VerifyCode(c.Request.Context(), "register", code, &uid); err != nil {
+	if err := h.svc.VerifyCode(c.Request.Context(), uid, "register", code); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -104,12 +108,14 @@ func (h *ConfirmationHandler) Activate(c *gin.Context) {
 // @Tags Confirmation
 // @Accept json
 // @Produce json
-// @Param input body object{code=string,new_password=string} true "Код и новый пароль"
+// @Param input body object{email=string,code=string,new_password=string} true "Email, код и новый пароль"
 // @Success 200 {object} object{message=string}
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
 // @Router /confirm/reset [post]
 func (h *ConfirmationHandler) ResetPassword(c *gin.Context) {
 	var req struct {
+		Email       string `json:"email" binding:"required,email"`
 		Code        string `json:"code" binding:"required,len=6"`
 		NewPassword string `json:"new_password" binding:"required"`
 	}
@@ -117,7 +123,7 @@ func (h *ConfirmationHandler) ResetPassword(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if err := h.svc.ResetPassword(c.Request.Context(), req.Code, req.NewPassword); err != nil {
+	if err := h.svc.ResetPassword(c.Request.Context(), req.Email, req.Code, req.NewPassword); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
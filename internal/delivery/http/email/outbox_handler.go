@@ -0,0 +1,65 @@
+package email
+
+import (
+	"EduSync/internal/delivery/middleware"
+	domainEmail "EduSync/internal/domain/email"
+	"EduSync/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OutboxHandler отдаёт состояние очереди исходящих писем.
+type OutboxHandler struct {
+	outboxService service.OutboxService
+}
+
+// NewOutboxHandler создаёт обработчик очереди писем.
+func NewOutboxHandler(outboxService service.OutboxService) *OutboxHandler {
+	return &OutboxHandler{outboxService: outboxService}
+}
+
+// FailedHandler возвращает письма, которые не удалось доставить
+// @Summary      Недоставленные письма
+// @Description  Письма, для которых исчерпаны попытки отправки, с последней ошибкой. Администратор учреждения видит только своё учреждение
+// @Tags         Admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        institution_id  query  int  false  "ID учреждения (для системного администратора)"
+// @Param        limit           query  int  false  "Количество записей (по умолчанию 50, не больше 200)"
+// @Param        offset          query  int  false  "Смещение"
+// @Success      200  {array}   email.OutboxMessage
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/emails/failed [get]
+func (h *OutboxHandler) FailedHandler(c *gin.Context) {
+	f := domainEmail.OutboxFilter{Status: domainEmail.StatusFailed}
+	for name, dst := range map[string]*int{
+		"institution_id": &f.InstitutionID,
+		"limit":          &f.Limit,
+		"offset":         &f.Offset,
+	} {
+		v := c.Query(name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный параметр " + name})
+			return
+		}
+		*dst = n
+	}
+
+	messages, err := h.outboxService.List(c.Request.Context(), middleware.Actor(c), f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if messages == nil {
+		messages = []*domainEmail.OutboxMessage{}
+	}
+	c.JSON(http.StatusOK, messages)
+}
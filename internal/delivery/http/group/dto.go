@@ -0,0 +1,20 @@
+package group
+
+// CreateGroupReq — тело запроса на создание группы.
+// swagger:model
+type CreateGroupReq struct {
+	// Название группы
+	// required: true
+	Name string `json:"name" binding:"required,max=255" example:"ИС-42"`
+	// ID учебного заведения
+	// required: true
+	InstitutionID int `json:"institution_id" binding:"required,gt=0" example:"1"`
+}
+
+// RenameGroupReq — тело запроса на переименование группы.
+// swagger:model
+type RenameGroupReq struct {
+	// Новое название группы
+	// required: true
+	Name string `json:"name" binding:"required,max=255" example:"ИС-43"`
+}
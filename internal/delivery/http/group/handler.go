@@ -1,7 +1,11 @@
 package group
 
 import (
+	"EduSync/internal/delivery/middleware"
+	domainGroup "EduSync/internal/domain/group"
+	domainUser "EduSync/internal/domain/user"
 	"EduSync/internal/service"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -71,3 +75,107 @@ func (h *GroupHandler) GetGroupByID(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, groups)
 }
+
+// CreateGroupHandler добавляет группу вручную
+// @Summary      Создать группу
+// @Description  Добавляет группу, которой нет в источнике расписания
+// @Tags         Admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        input  body  CreateGroupReq  true  "Данные группы"
+// @Success      201  {object}  Group
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/groups [post]
+func (h *GroupHandler) CreateGroupHandler(c *gin.Context) {
+	var req CreateGroupReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+	g, err := h.service.Create(c.Request.Context(), middleware.Actor(c), req.Name, req.InstitutionID)
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, g)
+}
+
+// RenameGroupHandler переименовывает группу
+// @Summary      Переименовать группу
+// @Tags         Admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id     path  int             true  "ID группы"
+// @Param        input  body  RenameGroupReq  true  "Новое название"
+// @Success      200  {object}  Group
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/groups/{id} [patch]
+func (h *GroupHandler) RenameGroupHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный id группы"})
+		return
+	}
+	var req RenameGroupReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+	g, err := h.service.Rename(c.Request.Context(), middleware.Actor(c), id, req.Name)
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, g)
+}
+
+// DeleteGroupHandler удаляет группу
+// @Summary      Удалить группу
+// @Description  Удаляет группу без расписания и чатов
+// @Tags         Admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id  path  int  true  "ID группы"
+// @Success      200  {object}  object{message=string}
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/groups/{id} [delete]
+func (h *GroupHandler) DeleteGroupHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный id группы"})
+		return
+	}
+	if err := h.service.Delete(c.Request.Context(), middleware.Actor(c), id); err != nil {
+		respondAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "группа удалена"})
+}
+
+func respondAdminError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domainUser.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domainGroup.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domainGroup.ErrExists), errors.Is(err, domainGroup.ErrInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domainGroup.ErrInvalidName):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
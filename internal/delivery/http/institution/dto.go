@@ -0,0 +1,42 @@
+package institution
+
+import "encoding/json"
+
+// CreateInstitutionReq — тело запроса на создание учебного заведения.
+// swagger:model
+type CreateInstitutionReq struct {
+	// Название учреждения
+	// required: true
+	Name string `json:"name" binding:"required,max=255" example:"rk"`
+	// Источник расписания: rksi, upload или пусто
+	ScheduleProvider string `json:"schedule_provider" example:"upload"`
+	// Настройки источника расписания
+	ProviderConfig json.RawMessage `json:"provider_config" swaggertype:"object"`
+	// Логотип для писем
+	LogoURL string `json:"logo_url" binding:"omitempty,max=2048" example:"https://college.ru/logo.png"`
+	// Фирменный цвет писем
+	BrandColor string `json:"brand_color" example:"#1a73e8"`
+}
+
+// UpdateInstitutionReq — изменяемые поля учебного заведения.
+// swagger:model
+type UpdateInstitutionReq struct {
+	// Название учреждения
+	Name *string `json:"name" binding:"omitempty,max=255" example:"rk"`
+	// Источник расписания; пустая строка отключает синхронизацию
+	ScheduleProvider *string `json:"schedule_provider" example:"rksi"`
+	// Настройки источника расписания; меняет только системный администратор
+	ProviderConfig json.RawMessage `json:"provider_config" swaggertype:"object"`
+	// Логотип для писем; пустая строка возвращает логотип EduSync
+	LogoURL *string `json:"logo_url" binding:"omitempty,max=2048" example:"https://college.ru/logo.png"`
+	// Фирменный цвет писем; пустая строка возвращает цвет EduSync
+	BrandColor *string `json:"brand_color" example:"#1a73e8"`
+}
+
+// CreateEmailMaskReq — тело запроса на добавление почтовой маски.
+// swagger:model
+type CreateEmailMaskReq struct {
+	// Почтовый домен преподавателей
+	// required: true
+	EmailMask string `json:"email_mask" binding:"required,max=255" example:"college.ru"`
+}
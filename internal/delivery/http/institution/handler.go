@@ -1,9 +1,13 @@
 package institution
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"EduSync/internal/delivery/middleware"
+	domainInstitution "EduSync/internal/domain/institution"
+	domainUser "EduSync/internal/domain/user"
 	service "EduSync/internal/service"
 	"github.com/gin-gonic/gin"
 )
@@ -114,3 +118,181 @@ func (h *InstitutionHandler) GetMaskByValue(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, mask)
 }
+
+// CreateInstitutionHandler создает учебное заведение
+// @Summary      Создать учреждение
+// @Description  Добавляет учебное заведение (только системный администратор)
+// @Tags         Admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        input  body  CreateInstitutionReq  true  "Данные учреждения"
+// @Success      201  {object}  Institution
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/institutions [post]
+func (h *InstitutionHandler) CreateInstitutionHandler(c *gin.Context) {
+	var req CreateInstitutionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+	inst := &domainInstitution.Institution{
+		Name:             req.Name,
+		ScheduleProvider: req.ScheduleProvider,
+		ProviderConfig:   req.ProviderConfig,
+		LogoURL:          req.LogoURL,
+		BrandColor:       req.BrandColor,
+	}
+	if _, err := h.instService.Create(c.Request.Context(), middleware.Actor(c), inst); err != nil {
+		respondAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, inst)
+}
+
+// UpdateInstitutionHandler изменяет учебное заведение
+// @Summary      Изменить учреждение
+// @Description  Меняет название, источник расписания и оформление писем. Администратор учреждения может менять только своё учреждение; provider_config (в том числе адрес страницы РКСИ) меняет только системный администратор
+// @Tags         Admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id     path  int                   true  "ID учреждения"
+// @Param        input  body  UpdateInstitutionReq  true  "Изменяемые поля"
+// @Success      200  {object}  Institution
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/institutions/{id} [patch]
+func (h *InstitutionHandler) UpdateInstitutionHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный id учреждения"})
+		return
+	}
+	var req UpdateInstitutionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+	inst, err := h.instService.Update(c.Request.Context(), middleware.Actor(c), id, domainInstitution.Update{
+		Name:             req.Name,
+		ScheduleProvider: req.ScheduleProvider,
+		ProviderConfig:   req.ProviderConfig,
+		LogoURL:          req.LogoURL,
+		BrandColor:       req.BrandColor,
+	})
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, inst)
+}
+
+// DeleteInstitutionHandler удаляет учебное заведение
+// @Summary      Удалить учреждение
+// @Description  Удаляет учреждение без пользователей, групп и предметов (только системный администратор)
+// @Tags         Admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id  path  int  true  "ID учреждения"
+// @Success      200  {object}  object{message=string}
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/institutions/{id} [delete]
+func (h *InstitutionHandler) DeleteInstitutionHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный id учреждения"})
+		return
+	}
+	if err := h.instService.Delete(c.Request.Context(), middleware.Actor(c), id); err != nil {
+		respondAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "учреждение удалено"})
+}
+
+// CreateMaskHandler добавляет почтовую маску учреждению
+// @Summary      Добавить почтовую маску
+// @Description  Закрепляет почтовый домен за учреждением: по нему регистрируются преподаватели
+// @Tags         Admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id     path  int                 true  "ID учреждения"
+// @Param        input  body  CreateEmailMaskReq  true  "Маска"
+// @Success      201  {object}  EmailMask
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/institutions/{id}/masks [post]
+func (h *InstitutionHandler) CreateMaskHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный id учреждения"})
+		return
+	}
+	var req CreateEmailMaskReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+	mask, err := h.emailMaskService.Create(c.Request.Context(), middleware.Actor(c), id, req.EmailMask)
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, mask)
+}
+
+// DeleteMaskHandler удаляет почтовую маску
+// @Summary      Удалить почтовую маску
+// @Tags         Admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        mask_id  path  int  true  "ID маски"
+// @Success      200  {object}  object{message=string}
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/masks/{mask_id} [delete]
+func (h *InstitutionHandler) DeleteMaskHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("mask_id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный id маски"})
+		return
+	}
+	if err := h.emailMaskService.Delete(c.Request.Context(), middleware.Actor(c), id); err != nil {
+		respondAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "маска удалена"})
+}
+
+func respondAdminError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domainUser.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domainInstitution.ErrNotFound), errors.Is(err, domainInstitution.ErrMaskNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domainInstitution.ErrInUse), errors.Is(err, domainInstitution.ErrMaskTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domainInstitution.ErrInvalidName),
+		errors.Is(err, domainInstitution.ErrInvalidProvider),
+		errors.Is(err, domainInstitution.ErrInvalidBranding),
+		errors.Is(err, domainInstitution.ErrInvalidMask):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
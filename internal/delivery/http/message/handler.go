@@ -1,7 +1,10 @@
 package chat
 
 import (
+	"EduSync/internal/delivery/middleware"
 	domainChat "EduSync/internal/domain/chat"
+	"context"
+	"errors"
 	"fmt"
 	"mime/multipart"
 	"net/http"
@@ -9,6 +12,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	serviceChat "EduSync/internal/service"
 	"github.com/gin-gonic/gin"
@@ -25,14 +29,15 @@ func NewMessageHandler(messageService serviceChat.MessageService) *MessageHandle
 
 // GetMessagesHandler возвращает список сообщений
 // @Summary      Получить сообщения
-// @Description  Возвращает список сообщений чата с пагинацией
+// @Description  Возвращает страницу сообщений чата, новые первыми. Без курсора — последние сообщения; before_id — более старые (прокрутка вверх), after_id — более новые. Удалённые сообщения не возвращаются
 // @Tags         Messages
 // @Security     BearerAuth
 // @Accept       json
 // @Produce      json
-// @Param        id      path  int  true   "ID чата"
-// @Param        limit   query int  false "Лимит сообщений"  default(10)
-// @Param        offset  query int  false "Смещение"         default(0)
+// @Param        id         path  int  true   "ID чата"
+// @Param        before_id  query int  false  "Сообщения с id меньше указанного"
+// @Param        after_id   query int  false  "Сообщения с id больше указанного"
+// @Param        limit      query int  false  "Лимит сообщений, не больше 100"  default(10)
 // @Success      200  {array}   Message
 // @Failure      400  {object}  dto.ErrorResponse
 // @Failure      500  {object}  dto.ErrorResponse
@@ -44,23 +49,123 @@ func (h *MessageHandler) GetMessagesHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор чата"})
 		return
 	}
-	limitStr := c.DefaultQuery("limit", "10")
-	offsetStr := c.DefaultQuery("offset", "0")
-	limit, err := strconv.Atoi(limitStr)
+	q, ok := messageQuery(c)
+	if !ok {
+		return
+	}
+
+	messages, err := h.messageService.Messages(c.Request.Context(), chatID, q)
+	if errors.Is(err, domainChat.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить сообщения"})
+		return
+	}
+	c.JSON(http.StatusOK, messages)
+}
+
+// ThreadHandler возвращает ветку ответов
+// @Summary      Ветка ответов
+// @Description  Возвращает сообщение и страницу ответов на него, новые первыми. Пагинация как у списка сообщений: before_id — более старые ответы, after_id — более новые
+// @Tags         Messages
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id         path  int  true   "ID чата"
+// @Param        messageID  path  int  true   "ID сообщения"
+// @Param        before_id  query int  false  "Ответы с id меньше указанного"
+// @Param        after_id   query int  false  "Ответы с id больше указанного"
+// @Param        limit      query int  false  "Лимит ответов, не больше 100"  default(10)
+// @Success      200  {object}  Thread
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /chats/{id}/messages/{messageID}/thread [get]
+func (h *MessageHandler) ThreadHandler(c *gin.Context) {
+	chatID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор чата"})
+		return
+	}
+	messageID, err := strconv.Atoi(c.Param("messageID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор сообщения"})
+		return
+	}
+	q, ok := messageQuery(c)
+	if !ok {
+		return
+	}
+
+	thread, err := h.messageService.Thread(c.Request.Context(), chatID, messageID, q)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, thread)
+	case errors.Is(err, domainChat.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domainChat.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "сообщение не найдено"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить ответы"})
+	}
+}
+
+// messageQuery разбирает параметры страницы before_id, after_id и limit.
+// При ошибке отвечает 400 и возвращает false.
+func messageQuery(c *gin.Context) (domainChat.MessageQuery, bool) {
+	q := domainChat.MessageQuery{ViewerID: c.GetInt("user_id")}
+	for param, dst := range map[string]*int{"before_id": &q.BeforeID, "after_id": &q.AfterID, "limit": &q.Limit} {
+		v := c.Query(param)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный параметр " + param})
+			return q, false
+		}
+		*dst = n
+	}
+	return q, true
+}
+
+// SyncMessagesHandler возвращает изменения после курсора
+// @Summary      Синхронизировать сообщения
+// @Description  Возвращает новые и отредактированные сообщения и ID удалённых после версии since в порядке изменений. Для первой синхронизации since=0; дальше передаётся cursor из предыдущего ответа, пока has_more=true.
+// @Tags         Messages
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id     path  int  true   "ID чата"
+// @Param        since  query int  false  "Курсор из предыдущего ответа"  default(0)
+// @Param        limit  query int  false  "Лимит изменений, не больше 100"  default(10)
+// @Success      200  {object}  SyncResult
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /chats/{id}/messages/sync [get]
+func (h *MessageHandler) SyncMessagesHandler(c *gin.Context) {
+	chatID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		limit = 10
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор чата"})
+		return
 	}
-	offset, err := strconv.Atoi(offsetStr)
+	since, err := strconv.ParseInt(c.DefaultQuery("since", "0"), 10, 64)
+	if err != nil || since < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный параметр since"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	if err != nil {
-		offset = 0
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный параметр limit"})
+		return
 	}
 
-	messages, err := h.messageService.Messages(c.Request.Context(), chatID, limit, offset)
+	res, err := h.messageService.Sync(c.Request.Context(), chatID, c.GetInt("user_id"), since, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить сообщения"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить изменения"})
 		return
 	}
-	c.JSON(http.StatusOK, messages)
+	c.JSON(http.StatusOK, res)
 }
 
 // SendMessageHandler отправляет сообщение
@@ -74,10 +179,12 @@ func (h *MessageHandler) GetMessagesHandler(c *gin.Context) {
 // @Param        text               formData  string  false "Текст сообщения"
 // @Param        message_group_id   formData  int     false "ID группы сообщений"
 // @Param        parent_message_id  formData  int     false "ID родительского сообщения"
+// @Param        kind               formData  string  false "Вид сообщения: message или announcement (только владелец чата; участники получают письмо)"  default(message)
 // @Param        files              formData  []file  false "Прикрепленные файлы"
 // @Success      201  {object}  object{message_id=int}
 // @Failure      400  {object}  dto.ErrorResponse
 // @Failure      401  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
 // @Failure      500  {object}  dto.ErrorResponse
 // @Router       /chats/{id}/messages [post]
 func (h *MessageHandler) SendMessageHandler(c *gin.Context) {
@@ -157,9 +264,18 @@ func (h *MessageHandler) SendMessageHandler(c *gin.Context) {
 		}(),
 		MessageGroupID:  mgid,
 		ParentMessageID: pmid,
+		Kind:            c.PostForm("kind"),
 	}
 
 	messageID, err := h.messageService.SendMessageWithFiles(c.Request.Context(), msg, files, c)
+	if errors.Is(err, domainChat.ErrInvalidParent) || errors.Is(err, domainChat.ErrInvalidKind) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, domainChat.ErrPermissionDenied) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Объявления публикует только владелец чата"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось создать сообщение"})
 		return
@@ -170,7 +286,7 @@ func (h *MessageHandler) SendMessageHandler(c *gin.Context) {
 
 // DeleteMessageHandler удаляет сообщение
 // @Summary      Удалить сообщение
-// @Description  Удаляет сообщение (доступно только автору)
+// @Description  Удаляет сообщение (автору или преподавателю). В ленте остаётся надгробие без текста и файлов, ответы сохраняют ссылку на него; участники получают событие message:delete
 // @Tags         Messages
 // @Security     BearerAuth
 // @Accept       json
@@ -230,7 +346,7 @@ func (h *MessageHandler) ReplyMessageHandler(c *gin.Context) {
 
 // SearchMessagesHandler ищет сообщения
 // @Summary      Поиск сообщений
-// @Description  Осуществляет полнотекстовый поиск по сообщениям чата
+// @Description  Осуществляет полнотекстовый поиск по тексту сообщений чата (с учётом русской морфологии) и именам файлов; результаты упорядочены по релевантности
 // @Tags         Messages
 // @Security     BearerAuth
 // @Accept       json
@@ -256,7 +372,11 @@ func (h *MessageHandler) SearchMessagesHandler(c *gin.Context) {
 	limit, _ := strconv.Atoi(limitStr)
 	offset, _ := strconv.Atoi(offsetStr)
 
-	messages, err := h.messageService.SearchMessages(c.Request.Context(), chatID, query, limit, offset)
+	messages, err := h.messageService.SearchMessages(c.Request.Context(), chatID, c.GetInt("user_id"), query, limit, offset)
+	if errors.Is(err, domainChat.ErrEmptyQuery) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка поиска сообщений"})
 		return
@@ -264,6 +384,78 @@ func (h *MessageHandler) SearchMessagesHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, messages)
 }
 
+// SearchHandler ищет по всем чатам пользователя
+// @Summary      Поиск по всем чатам
+// @Description  Полнотекстовый поиск по сообщениям всех чатов, где пользователь участник или владелец. Запрос в синтаксисе поисковиков: слова, "точная фраза", -исключение, or. Результаты упорядочены по релевантности; headline содержит фрагменты с найденными словами в <mark>, остальной текст экранирован
+// @Tags         Messages
+// @Security     BearerAuth
+// @Produce      json
+// @Param        q          query string  true   "Поисковый запрос"
+// @Param        chat_id    query int     false  "Только в этом чате"
+// @Param        author_id  query int     false  "Только сообщения автора"
+// @Param        from       query string  false  "С даты (2006-01-02 или RFC 3339)"
+// @Param        to         query string  false  "По дату включительно (2006-01-02) или до момента (RFC 3339)"
+// @Param        has_files  query bool    false  "true — только с файлами, false — только без файлов"
+// @Param        limit      query int     false  "Лимит результатов, не больше 100"  default(10)
+// @Param        offset     query int     false  "Смещение"  default(0)
+// @Success      200  {array}   SearchHit
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /search [get]
+func (h *MessageHandler) SearchHandler(c *gin.Context) {
+	f := domainChat.SearchFilter{UserID: c.GetInt("user_id"), Query: c.Query("q")}
+	for param, dst := range map[string]*int{"chat_id": &f.ChatID, "author_id": &f.AuthorID, "limit": &f.Limit, "offset": &f.Offset} {
+		v := c.Query(param)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный параметр " + param})
+			return
+		}
+		*dst = n
+	}
+	for param, dst := range map[string]**time.Time{"from": &f.From, "to": &f.To} {
+		v := c.Query(param)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			t, err = time.Parse(time.DateOnly, v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный параметр " + param})
+				return
+			}
+			// Дата без времени в to включает весь день
+			if param == "to" {
+				t = t.AddDate(0, 0, 1)
+			}
+		}
+		*dst = &t
+	}
+	if v := c.Query("has_files"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный параметр has_files"})
+			return
+		}
+		f.HasFiles = &b
+	}
+
+	hits, err := h.messageService.Search(c.Request.Context(), f)
+	if errors.Is(err, domainChat.ErrEmptyQuery) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка поиска сообщений"})
+		return
+	}
+	c.JSON(http.StatusOK, hits)
+}
+
 func (h *MessageHandler) UpdateMessageHandler(c *gin.Context) {
 	// 1) Параметры
 	chatID, err := strconv.Atoi(c.Param("id"))
@@ -302,3 +494,276 @@ func (h *MessageHandler) UpdateMessageHandler(c *gin.Context) {
 	// 5) Ответ
 	c.JSON(http.StatusOK, updated)
 }
+
+// RevisionsHandler возвращает историю сообщения
+// @Summary      История сообщения
+// @Description  Прежние версии текста сообщения: до каждой правки и перед удалением. Доступно только владельцу чата
+// @Tags         Messages
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id         path  int  true  "ID чата"
+// @Param        messageID  path  int  true  "ID сообщения"
+// @Success      200  {array}   MessageRevision
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /chats/{id}/messages/{messageID}/revisions [get]
+func (h *MessageHandler) RevisionsHandler(c *gin.Context) {
+	chatID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор чата"})
+		return
+	}
+	messageID, err := strconv.Atoi(c.Param("messageID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор сообщения"})
+		return
+	}
+
+	revs, err := h.messageService.Revisions(c.Request.Context(), chatID, messageID, c.GetInt("user_id"))
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, revs)
+	case errors.Is(err, domainChat.ErrPermissionDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": "История доступна только владельцу чата"})
+	case errors.Is(err, domainChat.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "сообщение не найдено"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// PurgeMessageHandler безвозвратно удаляет сообщение
+// @Summary      Удалить сообщение безвозвратно
+// @Description  Стирает текст, историю, файлы, реакции и упоминания сообщения; остаётся надгробие, которое синхронизация возвращает в deleted_ids. Администратор учреждения — в чатах своего учреждения, системный администратор — в любых. Участники чата получают message:delete с purged=true
+// @Tags         Admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id  path  int  true  "ID сообщения"
+// @Success      200  {object}  object{message=string}
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/messages/{id} [delete]
+func (h *MessageHandler) PurgeMessageHandler(c *gin.Context) {
+	messageID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор сообщения"})
+		return
+	}
+
+	err = h.messageService.PurgeMessage(c.Request.Context(), middleware.Actor(c), messageID)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"message": "Сообщение удалено безвозвратно"})
+	case errors.Is(err, domainChat.ErrPermissionDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Сообщение принадлежит чату другого учреждения"})
+	case errors.Is(err, domainChat.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "сообщение не найдено"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// ReactionRequest — тело запроса на реакцию.
+type ReactionRequest struct {
+	// Эмодзи
+	// example: 👍
+	Emoji string `json:"emoji" binding:"required"`
+}
+
+// AddReactionHandler ставит реакцию на сообщение
+// @Summary      Поставить реакцию
+// @Description  Ставит эмодзи-реакцию на сообщение и возвращает сводку реакций с отметкой reacted_by_me. Реакция — одно эмодзи: с модификатором цвета кожи, флаг, keycap или ZWJ-последовательность; текст и знаки препинания отклоняются (400). Повторная реакция тем же эмодзи ничего не меняет. Участники чата получают событие message:reaction
+// @Tags         Messages
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id         path  int              true  "ID чата"
+// @Param        messageID  path  int              true  "ID сообщения"
+// @Param        input      body  ReactionRequest  true  "Реакция"
+// @Success      200  {array}   Reaction
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /chats/{id}/messages/{messageID}/reactions [post]
+func (h *MessageHandler) AddReactionHandler(c *gin.Context) {
+	var req ReactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректное содержание запроса"})
+		return
+	}
+	h.react(c, req.Emoji, h.messageService.AddReaction)
+}
+
+// RemoveReactionHandler снимает реакцию с сообщения
+// @Summary      Снять реакцию
+// @Description  Снимает свою реакцию с сообщения и возвращает сводку реакций. Участники чата получают событие message:reaction
+// @Tags         Messages
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id         path   int     true  "ID чата"
+// @Param        messageID  path   int     true  "ID сообщения"
+// @Param        emoji      query  string  true  "Эмодзи"
+// @Success      200  {array}   Reaction
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /chats/{id}/messages/{messageID}/reactions [delete]
+func (h *MessageHandler) RemoveReactionHandler(c *gin.Context) {
+	h.react(c, c.Query("emoji"), h.messageService.RemoveReaction)
+}
+
+// react разбирает путь, вызывает действие с реакцией и отвечает сводкой реакций.
+func (h *MessageHandler) react(
+	c *gin.Context,
+	emoji string,
+	action func(ctx context.Context, chatID, messageID, userID int, emoji string) ([]domainChat.Reaction, error),
+) {
+	chatID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор чата"})
+		return
+	}
+	messageID, err := strconv.Atoi(c.Param("messageID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор сообщения"})
+		return
+	}
+
+	reactions, err := action(c.Request.Context(), chatID, messageID, c.GetInt("user_id"), emoji)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, reactions)
+	case errors.Is(err, domainChat.ErrInvalidReaction):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domainChat.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "сообщение не найдено"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось изменить реакцию"})
+	}
+}
+
+// PinsHandler возвращает закреплённые сообщения
+// @Summary      Закреплённые сообщения
+// @Description  Возвращает закреплённые сообщения чата, последние закреплённые первыми
+// @Tags         Messages
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id  path  int  true  "ID чата"
+// @Success      200  {array}   Message
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /chats/{id}/pins [get]
+func (h *MessageHandler) PinsHandler(c *gin.Context) {
+	chatID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор чата"})
+		return
+	}
+
+	pins, err := h.messageService.Pins(c.Request.Context(), chatID, c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить закреплённые сообщения"})
+		return
+	}
+	c.JSON(http.StatusOK, pins)
+}
+
+// PinMessageHandler закрепляет сообщение
+// @Summary      Закрепить сообщение
+// @Description  Закрепляет сообщение в чате. Доступно только владельцу чата; участники получают событие message:pinned
+// @Tags         Messages
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id         path  int  true  "ID чата"
+// @Param        messageID  path  int  true  "ID сообщения"
+// @Success      200  {object}  Message
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /chats/{id}/messages/{messageID}/pin [post]
+func (h *MessageHandler) PinMessageHandler(c *gin.Context) {
+	h.pin(c, h.messageService.PinMessage)
+}
+
+// UnpinMessageHandler открепляет сообщение
+// @Summary      Открепить сообщение
+// @Description  Снимает закрепление сообщения. Доступно только владельцу чата; участники получают событие message:pinned с pinned=false
+// @Tags         Messages
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id         path  int  true  "ID чата"
+// @Param        messageID  path  int  true  "ID сообщения"
+// @Success      200  {object}  Message
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /chats/{id}/messages/{messageID}/pin [delete]
+func (h *MessageHandler) UnpinMessageHandler(c *gin.Context) {
+	h.pin(c, h.messageService.UnpinMessage)
+}
+
+// pin разбирает путь, вызывает закрепление или открепление и отвечает сообщением.
+func (h *MessageHandler) pin(
+	c *gin.Context,
+	action func(ctx context.Context, chatID, messageID, userID int) (*domainChat.Message, error),
+) {
+	chatID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор чата"})
+		return
+	}
+	messageID, err := strconv.Atoi(c.Param("messageID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор сообщения"})
+		return
+	}
+
+	msg, err := action(c.Request.Context(), chatID, messageID, c.GetInt("user_id"))
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, msg)
+	case errors.Is(err, domainChat.ErrPermissionDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Закреплять сообщения может только владелец чата"})
+	case errors.Is(err, domainChat.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "сообщение не найдено"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось изменить закрепление"})
+	}
+}
+
+// MentionsHandler возвращает упоминания текущего пользователя
+// @Summary      Мои упоминания
+// @Description  Сообщения из чатов пользователя, где он упомянут через @ФИО или @all, новые первыми. Упоминание ФИО, которое носят несколько участников, достаётся каждому из них. Новые упоминания приходят событием message:mention в личную комнату WebSocket без подписки на чат
+// @Tags         Messages
+// @Security     BearerAuth
+// @Produce      json
+// @Param        before_id  query int  false  "Упоминания в сообщениях с id меньше указанного"
+// @Param        after_id   query int  false  "Упоминания в сообщениях с id больше указанного"
+// @Param        limit      query int  false  "Лимит, не больше 100"  default(10)
+// @Success      200  {array}   Message
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /mentions [get]
+func (h *MessageHandler) MentionsHandler(c *gin.Context) {
+	q, ok := messageQuery(c)
+	if !ok {
+		return
+	}
+
+	msgs, err := h.messageService.Mentions(c.Request.Context(), c.GetInt("user_id"), q)
+	if errors.Is(err, domainChat.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить упоминания"})
+		return
+	}
+	c.JSON(http.StatusOK, msgs)
+}
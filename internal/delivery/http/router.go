@@ -2,6 +2,7 @@ package http
 
 import (
 	_ "EduSync/docs/swagger"
+	auditHandler "EduSync/internal/delivery/http/audit"
 	chatHandler "EduSync/internal/delivery/http/chat"
 	"EduSync/internal/delivery/http/email"
 	"EduSync/internal/delivery/http/favorite"
@@ -14,7 +15,10 @@ import (
 	"EduSync/internal/delivery/http/user"
 	"EduSync/internal/delivery/middleware"
 	"EduSync/internal/delivery/ws"
+	domainRateLimit "EduSync/internal/domain/ratelimit"
+	domainUser "EduSync/internal/domain/user"
 	"EduSync/internal/repository"
+	"EduSync/internal/service"
 	"EduSync/internal/util"
 
 	"github.com/gin-gonic/gin"
@@ -26,6 +30,7 @@ import (
 func SetupRouter(
 	tokenRepo repository.TokenRepository,
 	chatRepo repository.ChatRepository,
+	calendarTokenRepo repository.CalendarTokenRepository,
 	authHandler *user.AuthHandler,
 	jwtManager *util.JWTManager,
 	groupHandler *groupHandler.GroupHandler,
@@ -36,9 +41,13 @@ func SetupRouter(
 	messageHandler *messageHandler.MessageHandler,
 	materialHandler *materialHandler.MaterialHandler,
 	teacherInitHandler *scheduleHandler.TeacherInitialsHandler,
+	classroomHandler *scheduleHandler.ClassroomHandler,
 	fileFavHandler *favorite.FileFavoriteHandler,
 	pollHandler *chatHandler.PollHandler,
 	emailHandler *email.ConfirmationHandler,
+	outboxHandler *email.OutboxHandler,
+	auditHandler *auditHandler.AuditHandler,
+	limiter service.RateLimiter,
 	log *logrus.Logger,
 	hub *ws.Hub,
 ) *gin.Engine {
@@ -47,14 +56,28 @@ func SetupRouter(
 	router.SetTrustedProxies([]string{"127.0.0.1"})
 	api := router.Group("/api")
 	{
-		api.POST("/register", authHandler.RegisterHandler)
-		api.POST("/login", authHandler.LoginHandler)
-		api.POST("/refresh", authHandler.RefreshTokenHandler)
+		loginLimit := middleware.RateLimit(limiter, domainRateLimit.LoginIP)
+		confirmLimit := middleware.RateLimit(limiter, domainRateLimit.ConfirmIP)
+		api.POST("/register", middleware.RateLimit(limiter, domainRateLimit.RegisterIP), authHandler.RegisterHandler)
+		api.POST("/login", loginLimit, authHandler.LoginHandler)
+		api.POST("/login/2fa", loginLimit, authHandler.LoginTwoFactorHandler)
+		api.POST("/login/2fa/enroll", loginLimit, authHandler.EnrollForChallengeHandler)
+		api.POST("/login/2fa/email", loginLimit, authHandler.RequestTwoFactorResetHandler)
+		api.POST("/login/2fa/recover", loginLimit, authHandler.ResetTwoFactorHandler)
+		api.POST("/refresh", middleware.RateLimit(limiter, domainRateLimit.RefreshIP), authHandler.RefreshTokenHandler)
 
-		api.POST("/confirm/request", emailHandler.RequestCode)
-		api.GET("/confirm/activate", emailHandler.Activate)
-		api.POST("/confirm/verify", emailHandler.VerifyCode)
-		api.POST("/confirm/reset", emailHandler.ResetPassword)
+		api.POST("/confirm/request", confirmLimit, emailHandler.RequestCode)
+		api.GET("/confirm/activate", confirmLimit, emailHandler.Activate)
+		api.POST("/confirm/verify", confirmLimit, emailHandler.VerifyCode)
+		api.POST("/confirm/reset", confirmLimit, emailHandler.ResetPassword)
+
+		// Подписка на календарь: клиенты не передают Bearer, авторизация по токену в пути
+		calendar := api.Group("/calendar/:token")
+		calendar.Use(middleware.CalendarTokenMiddleware(calendarTokenRepo, log))
+		{
+			calendar.GET("/schedule.ics", scheduleHandler.GetScheduleICalHandler)
+			calendar.GET("/teacher_initials/:initials_id/schedule.ics", scheduleHandler.GetByTeacherInitialsICalHandler)
+		}
 
 		protected := api.Group("/")
 		protected.Use(middleware.JWTMiddleware(tokenRepo, jwtManager, log))
@@ -62,16 +85,71 @@ func SetupRouter(
 			protected.PUT("/profile", authHandler.UpdateProfileHandler)
 			protected.POST("/logout", authHandler.LogoutHandler)
 			protected.GET("/profile", authHandler.ProfileHandler)
+			protected.POST("/profile/delete", confirmLimit, authHandler.RequestDeleteProfileHandler)
 			protected.DELETE("/profile", authHandler.DeleteProfileHandler)
+			protected.GET("/profile/export", authHandler.ExportProfileHandler)
+			protected.POST("/profile/email", confirmLimit, authHandler.ChangeEmailHandler)
+			protected.POST("/profile/email/confirm", confirmLimit, authHandler.ConfirmEmailChangeHandler)
+			protected.GET("/sessions", authHandler.SessionsHandler)
+			protected.DELETE("/sessions", authHandler.RevokeOtherSessionsHandler)
+			protected.DELETE("/sessions/:id", authHandler.RevokeSessionHandler)
+			twoFactor := protected.Group("/2fa")
+			{
+				twoFactor.GET("", authHandler.TwoFactorStatusHandler)
+				twoFactor.POST("/enroll", authHandler.EnrollTwoFactorHandler)
+				twoFactor.POST("/enable", authHandler.EnableTwoFactorHandler)
+				twoFactor.POST("/disable", authHandler.DisableTwoFactorHandler)
+				twoFactor.POST("/recovery-codes", authHandler.RegenerateRecoveryCodesHandler)
+			}
 			schedule := protected.Group("/schedule")
 			{
 				schedule.GET("/", scheduleHandler.GetScheduleHandler)
-				schedule.POST("/", scheduleHandler.CreateHandler)
-				schedule.POST("/update", scheduleHandler.UpdateScheduleHandler)
+				schedule.GET("/ical", scheduleHandler.GetScheduleICalHandler)
+				schedule.GET("/changes", scheduleHandler.GetChangesHandler)
+				schedule.GET("/feed_token", scheduleHandler.FeedTokenHandler)
+				schedule.POST("/feed_token", scheduleHandler.RegenerateFeedTokenHandler)
+				schedule.POST("/", middleware.RequirePermission(domainUser.PermScheduleWrite), scheduleHandler.CreateHandler)
+				schedule.POST("/update", middleware.RequirePermission(domainUser.PermScheduleSync), scheduleHandler.UpdateScheduleHandler)
+				schedule.POST("/import", middleware.RequirePermission(domainUser.PermScheduleSync), scheduleHandler.ImportHandler)
 				schedule.GET("/initials", teacherInitHandler.ListHandler)
 				schedule.GET("/teacher_initials/:initials_id", scheduleHandler.GetByTeacherInitialsHandler)
-				schedule.PATCH("/:id", scheduleHandler.UpdateHandler)
-				schedule.DELETE("/:id", scheduleHandler.DeleteHandler)
+				schedule.GET("/teacher_initials/:initials_id/ical", scheduleHandler.GetByTeacherInitialsICalHandler)
+				schedule.GET("/classroom/:name", scheduleHandler.GetByClassroomHandler)
+				schedule.GET("/bells", scheduleHandler.GetBellsHandler)
+				schedule.PUT("/bells", middleware.RequirePermission(domainUser.PermInstitutionManage), scheduleHandler.SetBellsHandler)
+				schedule.GET("/classrooms", classroomHandler.ListHandler)
+				schedule.GET("/classrooms/free", classroomHandler.FreeHandler)
+				schedule.PATCH("/classrooms/:id", middleware.RequirePermission(domainUser.PermInstitutionManage), classroomHandler.UpdateHandler)
+				schedule.PATCH("/:id", middleware.RequirePermission(domainUser.PermScheduleWrite), scheduleHandler.UpdateHandler)
+				schedule.DELETE("/:id", middleware.RequirePermission(domainUser.PermScheduleWrite), scheduleHandler.DeleteHandler)
+			}
+			admin := protected.Group("/admin")
+			{
+				admin.PUT("/users/:id/role", middleware.RequirePermission(domainUser.PermRolesManage), authHandler.ChangeRoleHandler)
+				admin.GET("/audit", middleware.RequirePermission(domainUser.PermInstitutionManage), auditHandler.ListHandler)
+				admin.GET("/emails/failed", middleware.RequirePermission(domainUser.PermInstitutionManage), outboxHandler.FailedHandler)
+				admin.DELETE("/messages/:id", middleware.RequirePermission(domainUser.PermMessagesModerate), messageHandler.PurgeMessageHandler)
+
+				admin.POST("/institutions", middleware.RequirePermission(domainUser.PermSystemManage), instHandler.CreateInstitutionHandler)
+				admin.PATCH("/institutions/:id", middleware.RequirePermission(domainUser.PermInstitutionManage), instHandler.UpdateInstitutionHandler)
+				admin.DELETE("/institutions/:id", middleware.RequirePermission(domainUser.PermSystemManage), instHandler.DeleteInstitutionHandler)
+				admin.POST("/institutions/:id/masks", middleware.RequirePermission(domainUser.PermInstitutionManage), instHandler.CreateMaskHandler)
+				admin.GET("/institutions/:id/2fa-policy", middleware.RequirePermission(domainUser.PermInstitutionManage), authHandler.TwoFactorPolicyHandler)
+				admin.PUT("/institutions/:id/2fa-policy", middleware.RequirePermission(domainUser.PermInstitutionManage), authHandler.SetTwoFactorPolicyHandler)
+				admin.DELETE("/masks/:mask_id", middleware.RequirePermission(domainUser.PermInstitutionManage), instHandler.DeleteMaskHandler)
+
+				manage := admin.Group("/")
+				manage.Use(middleware.RequirePermission(domainUser.PermInstitutionManage))
+				{
+					manage.POST("/groups", groupHandler.CreateGroupHandler)
+					manage.PATCH("/groups/:id", groupHandler.RenameGroupHandler)
+					manage.DELETE("/groups/:id", groupHandler.DeleteGroupHandler)
+
+					manage.POST("/subjects", subjectHandler.CreateSubjectHandler)
+					manage.PATCH("/subjects/:id", subjectHandler.RenameSubjectHandler)
+					manage.DELETE("/subjects/:id", subjectHandler.DeleteSubjectHandler)
+					manage.POST("/subjects/:id/merge", subjectHandler.MergeSubjectsHandler)
+				}
 			}
 			subject := protected.Group("/subject")
 			{
@@ -84,6 +162,8 @@ func SetupRouter(
 				wsGroup.GET("", ws.HandleWebSocket(hub, jwtManager, tokenRepo, log))
 			}
 
+			protected.GET("/search", messageHandler.SearchHandler)
+			protected.GET("/mentions", messageHandler.MentionsHandler)
 			chatGroup := protected.Group("/chats")
 			chatGroup.GET("", chatHandler.ListChatsHandler)
 			chatGroup.POST("/join", chatHandler.JoinChatHandler)
@@ -95,15 +175,24 @@ func SetupRouter(
 				chatGroup.DELETE("/:id", chatHandler.DeleteChatHandler)
 				chatGroup.DELETE("/:id/participants/:userID", chatHandler.RemoveParticipantHandler)
 				chatGroup.DELETE("/:id/leave", chatHandler.LeaveChatHandler)
+				chatGroup.POST("/:id/read", chatHandler.MarkReadHandler)
+				chatGroup.GET("/:id/pins", messageHandler.PinsHandler)
 				//chatGroup.Static("/files", "./uploads")
 
 				messages := chatGroup.Group("/:id/messages")
 				{
 					messages.GET("", messageHandler.GetMessagesHandler)
+					messages.GET("/sync", messageHandler.SyncMessagesHandler)
 					messages.POST("", messageHandler.SendMessageHandler)
 					messages.PATCH("/:messageID", messageHandler.UpdateMessageHandler)
 					messages.DELETE("/:messageID", messageHandler.DeleteMessageHandler)
 					messages.POST("/:messageID/reply", messageHandler.ReplyMessageHandler)
+					messages.GET("/:messageID/revisions", messageHandler.RevisionsHandler)
+					messages.GET("/:messageID/thread", messageHandler.ThreadHandler)
+					messages.POST("/:messageID/reactions", messageHandler.AddReactionHandler)
+					messages.DELETE("/:messageID/reactions", messageHandler.RemoveReactionHandler)
+					messages.POST("/:messageID/pin", messageHandler.PinMessageHandler)
+					messages.DELETE("/:messageID/pin", messageHandler.UnpinMessageHandler)
 					messages.GET("/search", messageHandler.SearchMessagesHandler)
 				}
 				protected.GET("/files/:id", materialHandler.GetFileHandler)
@@ -136,6 +225,7 @@ func SetupRouter(
 		}
 
 	}
+	router.GET("/.well-known/jwks.json", authHandler.JWKSHandler)
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	return router
 }
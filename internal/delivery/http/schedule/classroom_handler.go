@@ -0,0 +1,134 @@
+package schedule
+
+import (
+	"EduSync/internal/delivery/http/schedule/dto"
+	domainSchedule "EduSync/internal/domain/schedule"
+	"EduSync/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClassroomHandler обрабатывает запросы к справочнику аудиторий.
+type ClassroomHandler struct {
+	svc service.ClassroomService
+}
+
+// NewClassroomHandler создает ClassroomHandler.
+func NewClassroomHandler(svc service.ClassroomService) *ClassroomHandler {
+	return &ClassroomHandler{svc: svc}
+}
+
+// ListHandler возвращает аудитории учебного заведения пользователя.
+// @Summary      Справочник аудиторий
+// @Description  Возвращает аудитории учебного заведения пользователя с корпусом и вместимостью
+// @Tags         Classrooms
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {array}   Classroom
+// @Failure      500  {object} dto.ErrorResponse
+// @Router       /schedule/classrooms [get]
+func (h *ClassroomHandler) ListHandler(c *gin.Context) {
+	list, err := h.svc.List(c.Request.Context(), c.GetInt("institution_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// FreeHandler ищет аудитории, свободные на заданной паре.
+// @Summary      Свободные аудитории
+// @Description  Возвращает аудитории учебного заведения, в которых нет занятий на паре pair в день date
+// @Tags         Classrooms
+// @Security     BearerAuth
+// @Produce      json
+// @Param        date            query  string  true   "Дата (ГГГГ-ММ-ДД)"
+// @Param        pair            query  int     true   "Номер пары"
+// @Param        institution_id  query  int     false  "ID учебного заведения (по умолчанию — заведение пользователя)"
+// @Param        building        query  string  false  "Корпус"
+// @Param        min_capacity    query  int     false  "Минимальная вместимость"
+// @Success      200  {array}   Classroom
+// @Failure      400  {object} dto.ErrorResponse
+// @Failure      500  {object} dto.ErrorResponse
+// @Router       /schedule/classrooms/free [get]
+func (h *ClassroomHandler) FreeHandler(c *gin.Context) {
+	date, err := time.Parse(queryDateLayout, c.Query("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат date, ожидается ГГГГ-ММ-ДД"})
+		return
+	}
+	pair, err := strconv.Atoi(c.Query("pair"))
+	if err != nil || pair <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный номер пары"})
+		return
+	}
+
+	f := domainSchedule.FreeClassroomsFilter{
+		InstitutionID: c.GetInt("institution_id"),
+		Date:          date,
+		PairNumber:    pair,
+		Building:      c.Query("building"),
+	}
+	if s := c.Query("institution_id"); s != "" {
+		if f.InstitutionID, err = strconv.Atoi(s); err != nil || f.InstitutionID <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный institution_id"})
+			return
+		}
+	}
+	if s := c.Query("min_capacity"); s != "" {
+		if f.MinCapacity, err = strconv.Atoi(s); err != nil || f.MinCapacity < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный min_capacity"})
+			return
+		}
+	}
+
+	list, err := h.svc.Free(c.Request.Context(), f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// UpdateHandler меняет корпус и вместимость аудитории.
+// @Summary      Изменить аудиторию
+// @Description  Задаёт корпус и вместимость аудитории своего учебного заведения
+// @Tags         Classrooms
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id     path  int                     true  "ID аудитории"
+// @Param        input  body  dto.UpdateClassroomReq  true  "Новые данные"
+// @Success      200  {object}  Classroom
+// @Failure      400  {object} dto.ErrorResponse
+// @Failure      403  {object} dto.ErrorResponse
+// @Failure      404  {object} dto.ErrorResponse
+// @Failure      500  {object} dto.ErrorResponse
+// @Router       /schedule/classrooms/{id} [patch]
+func (h *ClassroomHandler) UpdateHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID аудитории"})
+		return
+	}
+	var req dto.UpdateClassroomReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	room, err := h.svc.Update(c.Request.Context(), c.GetInt("institution_id"), id, req.Building, req.Capacity)
+	if errors.Is(err, domainSchedule.ErrClassroomNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, room)
+}
@@ -1,6 +1,10 @@
 package dto
 
-import "time"
+import (
+	domainSchedule "EduSync/internal/domain/schedule"
+	"fmt"
+	"time"
+)
 
 // TeacherInitialDTO — модель данных для инициалов преподавателя
 // swagger:model
@@ -48,6 +52,10 @@ type UpdateScheduleReq struct {
 	// Время окончания (RFC3339)
 	// example: "2023-12-25T10:30:00Z"
 	EndTime *time.Time `json:"end_time,omitempty"`
+
+	// Сохранить, несмотря на предупреждения (например, несовпадение со звонками)
+	// example: false
+	Force bool `json:"force,omitempty"`
 }
 
 // CreateScheduleReq — тело запроса для обновления расписания
@@ -84,4 +92,63 @@ type CreateScheduleReq struct {
 	// Время окончания (RFC3339)
 	// example: "2023-12-25T10:30:00Z"
 	EndTime time.Time `json:"end_time,omitempty"`
+
+	// Сохранить, несмотря на предупреждения (например, несовпадение со звонками)
+	// example: false
+	Force bool `json:"force,omitempty"`
+}
+
+// FeedTokenResp — токен и шаблоны ссылок для подписки на календарь
+// swagger:model
+type FeedTokenResp struct {
+	// Секретный токен подписки
+	// example: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	Token string `json:"token"`
+
+	// Ссылка на расписание группы, {group_id} подставляется клиентом
+	// example: "/api/calendar/9f86d0.../schedule.ics?group_id={group_id}"
+	GroupURL string `json:"group_url"`
+
+	// Ссылка на расписание преподавателя, {initials_id} подставляется клиентом
+	// example: "/api/calendar/9f86d0.../teacher_initials/{initials_id}/schedule.ics"
+	TeacherURL string `json:"teacher_url"`
+}
+
+// NewFeedTokenResp собирает ответ с готовыми шаблонами ссылок.
+func NewFeedTokenResp(token string) *FeedTokenResp {
+	return &FeedTokenResp{
+		Token:      token,
+		GroupURL:   fmt.Sprintf("/api/calendar/%s/schedule.ics?group_id={group_id}", token),
+		TeacherURL: fmt.Sprintf("/api/calendar/%s/teacher_initials/{initials_id}/schedule.ics", token),
+	}
+}
+
+// UpdateClassroomReq — тело запроса для изменения аудитории в справочнике
+// swagger:model
+type UpdateClassroomReq struct {
+	// Корпус
+	// example: "Главный корпус"
+	Building *string `json:"building,omitempty"`
+
+	// Вместимость; 0 — неизвестна
+	// example: 30
+	Capacity *int `json:"capacity,omitempty"`
+}
+
+// ConflictResponse — ответ при конфликтах в расписании
+// swagger:model
+type ConflictResponse struct {
+	// Общее описание
+	// example: "Запись конфликтует с расписанием"
+	Error string `json:"error"`
+
+	// Список конфликтов
+	Conflicts []*domainSchedule.Conflict `json:"conflicts"`
+}
+
+// SetBellsReq — тело запроса для замены расписания звонков
+// swagger:model
+type SetBellsReq struct {
+	// Звонки по номерам пар; пустой список отключает проверку
+	Bells []*domainSchedule.Bell `json:"bells"`
 }
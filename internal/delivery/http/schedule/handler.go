@@ -2,10 +2,17 @@ package schedule
 
 import (
 	"EduSync/internal/delivery/http/schedule/dto"
+	"EduSync/internal/delivery/middleware"
+	domainGroup "EduSync/internal/domain/group"
+	domainSchedule "EduSync/internal/domain/schedule"
 	"EduSync/internal/service"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,33 +21,61 @@ import (
 // swagger:model
 type ScheduleHandler struct {
 	scheduleService service.ScheduleService
+	calendarService service.CalendarTokenService
 }
 
 // NewScheduleHandler создает новый ScheduleHandler.
-func NewScheduleHandler(scheduleService service.ScheduleService) *ScheduleHandler {
-	return &ScheduleHandler{scheduleService: scheduleService}
+func NewScheduleHandler(
+	scheduleService service.ScheduleService,
+	calendarService service.CalendarTokenService,
+) *ScheduleHandler {
+	return &ScheduleHandler{
+		scheduleService: scheduleService,
+		calendarService: calendarService,
+	}
 }
 
 // UpdateScheduleHandler запускает обновление расписания для заданной группы.
 // @Summary      Обновить расписание группы
-// @Description  Запускает процесс обновления расписания для указанной группы
+// @Description  Запускает процесс обновления расписания для указанной группы. Группа ищется в учреждении institution_id, по умолчанию — в учреждении пользователя
 // @Tags         Schedule
 // @Security     BearerAuth
 // @Accept       json
 // @Produce      json
-// @Param        group_name  query  string  true  "Название группы"
+// @Param        group_name      query  string  true   "Название группы"
+// @Param        institution_id  query  int     false  "ID учреждения"
 // @Success      200  {object}  object{message=string}
 // @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
 // @Failure      500  {object} dto.ErrorResponse
-// @Router       /schedule/update [get]
+// @Router       /schedule/update [post]
 func (h *ScheduleHandler) UpdateScheduleHandler(c *gin.Context) {
 	groupName := c.Query("group_name")
 	if groupName == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "group_name обязательны"})
 		return
 	}
+	actor := middleware.Actor(c)
+	institutionID := actor.InstitutionID
+	if v := c.Query("institution_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный параметр institution_id"})
+			return
+		}
+		institutionID = id
+	}
 
-	err := h.scheduleService.Save(c.Request.Context(), groupName)
+	err := h.scheduleService.Save(c.Request.Context(), actor, institutionID, groupName)
+	if errors.Is(err, domainGroup.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, domainSchedule.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -51,12 +86,16 @@ func (h *ScheduleHandler) UpdateScheduleHandler(c *gin.Context) {
 
 // GetScheduleHandler возвращает расписание для заданной группы.
 // @Summary      Получить расписание группы
-// @Description  Возвращает полное расписание для указанной группы
+// @Description  Возвращает расписание группы за период. При view=days ответ сгруппирован по дням ([]Day)
 // @Tags         Schedule
 // @Security     BearerAuth
 // @Accept       json
 // @Produce      json
-// @Param        group_id  query  int  true  "ID группы"
+// @Param        group_id  query  int     true   "ID группы"
+// @Param        from      query  string  false  "Начало периода (ГГГГ-ММ-ДД)"
+// @Param        to        query  string  false  "Конец периода (ГГГГ-ММ-ДД)"
+// @Param        week      query  string  false  "Неделя: current, next или ISO-неделя ГГГГ-Wнн"
+// @Param        view      query  string  false  "Форма ответа: days — по дням"
 // @Success      200  {array}   Item
 // @Failure      400  {object} dto.ErrorResponse
 // @Failure      500  {object} dto.ErrorResponse
@@ -73,17 +112,53 @@ func (h *ScheduleHandler) GetScheduleHandler(c *gin.Context) {
 		return
 	}
 
-	scheduleEntries, err := h.scheduleService.ByGroupID(c.Request.Context(), groupID)
+	period, err := parsePeriod(c, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scheduleEntries, err := h.scheduleService.ByGroupID(c.Request.Context(), groupID, period)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, scheduleEntries)
+	respondSchedule(c, http.StatusOK, scheduleEntries)
+}
+
+// GetChangesHandler возвращает историю изменений расписания группы.
+// @Summary      История изменений расписания
+// @Description  Возвращает добавленные, изменённые и отменённые пары группы, новые записи первыми
+// @Tags         Schedule
+// @Security     BearerAuth
+// @Produce      json
+// @Param        group_id  query  int  true   "ID группы"
+// @Param        limit     query  int  false  "Лимит"     default(50)
+// @Param        offset    query  int  false  "Смещение"  default(0)
+// @Success      200  {array}   Change
+// @Failure      400  {object} dto.ErrorResponse
+// @Failure      500  {object} dto.ErrorResponse
+// @Router       /schedule/changes [get]
+func (h *ScheduleHandler) GetChangesHandler(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Query("group_id"))
+	if err != nil || groupID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный group_id"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	changes, err := h.scheduleService.Changes(c.Request.Context(), groupID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, changes)
 }
 
 // swagger:route PUT /schedule/{id} schedule updateScheduleEntry
 // @Summary      Обновить запись расписания
-// @Description  Обновляет существующую запись в расписании (только для преподавателей)
+// @Description  Обновляет существующую запись в расписании (только для преподавателей). Итоговая запись проверяется на конфликты так же, как при создании
 // @Tags         Schedule
 // @Security     BearerAuth
 // @Accept       json
@@ -93,8 +168,10 @@ func (h *ScheduleHandler) GetScheduleHandler(c *gin.Context) {
 // @Success      200  {object}  object{message=string}
 // @Failure      400  {object} dto.ErrorResponse
 // @Failure      403  {object} dto.ErrorResponse
+// @Failure      404  {object} dto.ErrorResponse
+// @Failure      409  {object} dto.ConflictResponse
 // @Failure      500  {object} dto.ErrorResponse
-// @Router       /schedule/{id} [put]
+// @Router       /schedule/{id} [patch]
 func (h *ScheduleHandler) UpdateHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil || id <= 0 {
@@ -102,21 +179,14 @@ func (h *ScheduleHandler) UpdateHandler(c *gin.Context) {
 		return
 	}
 
-	// Только преподаватель может обновлять
-	isTeacher, ok := c.Get("is_teacher")
-	if !ok || !isTeacher.(bool) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "доступ разрешён только преподавателям"})
-		return
-	}
-
 	var req dto.UpdateScheduleReq
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
 		return
 	}
 
-	if err := h.scheduleService.Update(c.Request.Context(), id, &req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка обновления записи"})
+	if err := h.scheduleService.Update(c.Request.Context(), middleware.Actor(c), id, &req); err != nil {
+		respondWriteError(c, err, "Ошибка обновления записи")
 		return
 	}
 
@@ -135,6 +205,7 @@ func (h *ScheduleHandler) UpdateHandler(c *gin.Context) {
 // @Success      200  {object}  object{message=string}
 // @Failure      400  {object} dto.ErrorResponse
 // @Failure      403  {object} dto.ErrorResponse
+// @Failure      404  {object} dto.ErrorResponse
 // @Failure      500  {object} dto.ErrorResponse
 // @Router       /schedule/{id} [delete]
 func (h *ScheduleHandler) DeleteHandler(c *gin.Context) {
@@ -144,15 +215,8 @@ func (h *ScheduleHandler) DeleteHandler(c *gin.Context) {
 		return
 	}
 
-	// Только преподаватель может удалять
-	isTeacher, ok := c.Get("is_teacher")
-	if !ok || !isTeacher.(bool) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "доступ разрешён только преподавателям"})
-		return
-	}
-
-	if err := h.scheduleService.Delete(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка удаления записи"})
+	if err := h.scheduleService.Delete(c.Request.Context(), middleware.Actor(c), id); err != nil {
+		respondWriteError(c, err, "Ошибка удаления записи")
 		return
 	}
 
@@ -161,12 +225,16 @@ func (h *ScheduleHandler) DeleteHandler(c *gin.Context) {
 
 // GetByTeacherInitialsHandler возвращает расписание по id инициалов преподавателя
 // @Summary      Получить расписание преподавателя
-// @Description  Возвращает расписание по идентификатору инициалов преподавателя
+// @Description  Возвращает расписание по идентификатору инициалов преподавателя за период. При view=days ответ сгруппирован по дням ([]Day)
 // @Tags         Schedule
 // @Security     BearerAuth
 // @Accept       json
 // @Produce      json
 // @Param        initials_id  path  int  true  "ID инициалов преподавателя"
+// @Param        from      query  string  false  "Начало периода (ГГГГ-ММ-ДД)"
+// @Param        to        query  string  false  "Конец периода (ГГГГ-ММ-ДД)"
+// @Param        week      query  string  false  "Неделя: current, next или ISO-неделя ГГГГ-Wнн"
+// @Param        view      query  string  false  "Форма ответа: days — по дням"
 // @Success      200  {array}   Item
 // @Failure      400  {object} dto.ErrorResponse
 // @Failure      500  {object} dto.ErrorResponse
@@ -179,17 +247,60 @@ func (h *ScheduleHandler) GetByTeacherInitialsHandler(c *gin.Context) {
 		return
 	}
 
-	entries, err := h.scheduleService.ByTeacherInitialsID(c.Request.Context(), initialsID)
+	period, err := parsePeriod(c, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := h.scheduleService.ByTeacherInitialsID(c.Request.Context(), initialsID, period)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить расписание"})
 		return
 	}
-	c.JSON(http.StatusOK, entries)
+	respondSchedule(c, http.StatusOK, entries)
+}
+
+// GetByClassroomHandler возвращает занятия в аудитории.
+// @Summary      Получить расписание аудитории
+// @Description  Возвращает занятия в аудитории учебного заведения пользователя за период. При view=days ответ сгруппирован по дням ([]Day)
+// @Tags         Schedule
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        name      path   string  true   "Название аудитории"
+// @Param        from      query  string  false  "Начало периода (ГГГГ-ММ-ДД)"
+// @Param        to        query  string  false  "Конец периода (ГГГГ-ММ-ДД)"
+// @Param        week      query  string  false  "Неделя: current, next или ISO-неделя ГГГГ-Wнн"
+// @Param        view      query  string  false  "Форма ответа: days — по дням"
+// @Success      200  {array}   Item
+// @Failure      400  {object} dto.ErrorResponse
+// @Failure      500  {object} dto.ErrorResponse
+// @Router       /schedule/classroom/{name} [get]
+func (h *ScheduleHandler) GetByClassroomHandler(c *gin.Context) {
+	name := strings.TrimSpace(c.Param("name"))
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Не указана аудитория"})
+		return
+	}
+
+	period, err := parsePeriod(c, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := h.scheduleService.ByClassroom(c.Request.Context(), c.GetInt("institution_id"), name, period)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	respondSchedule(c, http.StatusOK, entries)
 }
 
 // swagger:route POST /schedule schedule createScheduleEntry
 // @Summary      Добавить запись в расписание
-// @Description  Создаёт новую запись расписания (только для преподавателей)
+// @Description  Создаёт новую запись расписания (роли с правом schedule:write). Запись проверяется на конфликты: занятость группы, преподавателя и аудитории, корректность времени и совпадение со звонками; предупреждения можно пропустить, передав force=true
 // @Tags         Schedule
 // @Security     BearerAuth
 // @Accept       json
@@ -198,31 +309,270 @@ func (h *ScheduleHandler) GetByTeacherInitialsHandler(c *gin.Context) {
 // @Success      201 {object} object{message=string,id=int}
 // @Failure      400 {object} dto.ErrorResponse
 // @Failure      403 {object} dto.ErrorResponse
-// @Failure      409 {object} dto.ErrorResponse "Нарушение уникальности пары"
+// @Failure      409 {object} dto.ConflictResponse "Конфликты в расписании"
 // @Failure      500 {object} dto.ErrorResponse
 // @Router       /schedule [post]
 func (h *ScheduleHandler) CreateHandler(c *gin.Context) {
-	isTeacher, ok := c.Get("is_teacher")
-	if !ok || !isTeacher.(bool) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ разрешён только преподавателям"})
-		return
-	}
-
 	var req dto.CreateScheduleReq
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
 		return
 	}
 
-	id, err := h.scheduleService.Create(c.Request.Context(), &req)
+	id, err := h.scheduleService.Create(c.Request.Context(), middleware.Actor(c), &req)
 	if err != nil {
-		if strings.Contains(err.Error(), "duplicate key") {
-			c.JSON(http.StatusConflict, gin.H{"error": "Пара уже занята"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка создания записи"})
-		}
+		respondWriteError(c, err, "Ошибка создания записи")
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{"message": "расписание добавлено", "id": id})
 }
+
+// GetScheduleICalHandler отдаёт расписание группы в формате iCalendar.
+// @Summary      Расписание группы в iCalendar
+// @Description  Возвращает расписание группы в формате RFC 5545. Доступно по Bearer-токену или по ссылке /calendar/{token}/schedule.ics
+// @Tags         Schedule
+// @Security     BearerAuth
+// @Produce      text/calendar
+// @Param        group_id  query  int  true  "ID группы"
+// @Param        from      query  string  false  "Начало периода (ГГГГ-ММ-ДД)"
+// @Param        to        query  string  false  "Конец периода (ГГГГ-ММ-ДД)"
+// @Param        week      query  string  false  "Неделя: current, next или ISO-неделя ГГГГ-Wнн"
+// @Success      200  {string}  string  "VCALENDAR"
+// @Failure      400  {object} dto.ErrorResponse
+// @Failure      500  {object} dto.ErrorResponse
+// @Router       /schedule/ical [get]
+func (h *ScheduleHandler) GetScheduleICalHandler(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Query("group_id"))
+	if err != nil || groupID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный group_id"})
+		return
+	}
+
+	period, err := parsePeriod(c, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := h.scheduleService.ByGroupID(c.Request.Context(), groupID, period)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить расписание"})
+		return
+	}
+
+	calName := fmt.Sprintf("Расписание группы %d", groupID)
+	if len(entries) > 0 && entries[0].GroupName != "" {
+		calName = "Расписание " + entries[0].GroupName
+	}
+	writeICal(c, fmt.Sprintf("group_%d.ics", groupID), renderICal(calName, entries, time.Now()))
+}
+
+// GetByTeacherInitialsICalHandler отдаёт расписание преподавателя в формате iCalendar.
+// @Summary      Расписание преподавателя в iCalendar
+// @Description  Возвращает расписание по инициалам преподавателя в формате RFC 5545. Доступно по Bearer-токену или по ссылке /calendar/{token}/teacher_initials/{initials_id}/schedule.ics
+// @Tags         Schedule
+// @Security     BearerAuth
+// @Produce      text/calendar
+// @Param        initials_id  path  int  true  "ID инициалов преподавателя"
+// @Param        from      query  string  false  "Начало периода (ГГГГ-ММ-ДД)"
+// @Param        to        query  string  false  "Конец периода (ГГГГ-ММ-ДД)"
+// @Param        week      query  string  false  "Неделя: current, next или ISO-неделя ГГГГ-Wнн"
+// @Success      200  {string}  string  "VCALENDAR"
+// @Failure      400  {object} dto.ErrorResponse
+// @Failure      500  {object} dto.ErrorResponse
+// @Router       /schedule/teacher_initials/{initials_id}/ical [get]
+func (h *ScheduleHandler) GetByTeacherInitialsICalHandler(c *gin.Context) {
+	initialsID, err := strconv.Atoi(c.Param("initials_id"))
+	if err != nil || initialsID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор initials_id"})
+		return
+	}
+
+	period, err := parsePeriod(c, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := h.scheduleService.ByTeacherInitialsID(c.Request.Context(), initialsID, period)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить расписание"})
+		return
+	}
+
+	calName := "Расписание преподавателя"
+	if len(entries) > 0 && entries[0].TeacherInitials != "" {
+		calName = "Расписание " + entries[0].TeacherInitials
+	}
+	writeICal(c, fmt.Sprintf("teacher_%d.ics", initialsID), renderICal(calName, entries, time.Now()))
+}
+
+// FeedTokenHandler возвращает секретный токен для подписки на календарь.
+// @Summary      Токен подписки на календарь
+// @Description  Возвращает (и при необходимости создаёт) секретный токен для ссылок на iCalendar-ленты
+// @Tags         Schedule
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  dto.FeedTokenResp
+// @Failure      500  {object} dto.ErrorResponse
+// @Router       /schedule/feed_token [get]
+func (h *ScheduleHandler) FeedTokenHandler(c *gin.Context) {
+	token, err := h.calendarService.Token(c.Request.Context(), c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewFeedTokenResp(token))
+}
+
+// RegenerateFeedTokenHandler перевыпускает токен подписки на календарь.
+// @Summary      Перевыпустить токен подписки
+// @Description  Создаёт новый токен, ранее выданные ссылки на календарь перестают работать
+// @Tags         Schedule
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  dto.FeedTokenResp
+// @Failure      500  {object} dto.ErrorResponse
+// @Router       /schedule/feed_token [post]
+func (h *ScheduleHandler) RegenerateFeedTokenHandler(c *gin.Context) {
+	token, err := h.calendarService.Regenerate(c.Request.Context(), c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewFeedTokenResp(token))
+}
+
+// writeICal отдаёт календарь клиенту с нужными заголовками.
+func writeICal(c *gin.Context, filename string, body []byte) {
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", body)
+}
+
+// maxImportSize — предельный размер шаблона расписания.
+const maxImportSize = 10 << 20
+
+// ImportHandler загружает расписание учебного заведения из шаблона CSV/XLSX.
+// @Summary      Загрузить расписание из файла
+// @Description  Принимает шаблон CSV/XLSX с колонками группа, дата, начало, конец, дисциплина, преподаватель, аудитория и применяет его к расписанию учебного заведения пользователя. Доступно учреждениям с источником upload
+// @Tags         Schedule
+// @Security     BearerAuth
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file  formData  file  true  "Шаблон расписания (.csv или .xlsx)"
+// @Success      200  {object}  ImportResult
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /schedule/import [post]
+func (h *ScheduleHandler) ImportHandler(c *gin.Context) {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ожидается файл в поле file"})
+		return
+	}
+	if fh.Size > maxImportSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Файл слишком большой"})
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Не удалось прочитать файл"})
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxImportSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Не удалось прочитать файл"})
+		return
+	}
+
+	res, err := h.scheduleService.Import(c.Request.Context(), c.GetInt("institution_id"), fh.Filename, data)
+	switch {
+	case errors.Is(err, domainSchedule.ErrUploadNotAllowed):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, domainSchedule.ErrInvalidTemplate):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// respondWriteError отвечает на ошибку создания или изменения записи расписания.
+// Конфликты отдаются списком, чтобы клиент мог показать каждый.
+func respondWriteError(c *gin.Context, err error, fallback string) {
+	var conflictErr *domainSchedule.ConflictError
+	switch {
+	case errors.As(err, &conflictErr):
+		c.JSON(http.StatusConflict, dto.ConflictResponse{
+			Error:     "Запись конфликтует с расписанием",
+			Conflicts: conflictErr.Conflicts,
+		})
+	case errors.Is(err, domainSchedule.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domainSchedule.ErrGroupNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domainSchedule.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// GetBellsHandler возвращает расписание звонков учебного заведения пользователя.
+// @Summary      Расписание звонков
+// @Description  Возвращает время пар учебного заведения пользователя. Пустой список — звонки не заданы
+// @Tags         Schedule
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {array}   Bell
+// @Failure      500  {object} dto.ErrorResponse
+// @Router       /schedule/bells [get]
+func (h *ScheduleHandler) GetBellsHandler(c *gin.Context) {
+	bells, err := h.scheduleService.Bells(c.Request.Context(), c.GetInt("institution_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if bells == nil {
+		bells = []*domainSchedule.Bell{}
+	}
+	c.JSON(http.StatusOK, bells)
+}
+
+// SetBellsHandler заменяет расписание звонков учебного заведения пользователя.
+// @Summary      Задать расписание звонков
+// @Description  Заменяет время пар целиком. По нему проверяются вручную добавляемые пары (администратор учреждения)
+// @Tags         Schedule
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        input  body  dto.SetBellsReq  true  "Звонки"
+// @Success      200  {object}  object{message=string}
+// @Failure      400  {object} dto.ErrorResponse
+// @Failure      403  {object} dto.ErrorResponse
+// @Failure      500  {object} dto.ErrorResponse
+// @Router       /schedule/bells [put]
+func (h *ScheduleHandler) SetBellsHandler(c *gin.Context) {
+	var req dto.SetBellsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	err := h.scheduleService.SetBells(c.Request.Context(), c.GetInt("institution_id"), req.Bells)
+	if errors.Is(err, domainSchedule.ErrInvalidBells) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "расписание звонков сохранено"})
+}
@@ -0,0 +1,104 @@
+package schedule
+
+import (
+	domainSchedule "EduSync/internal/domain/schedule"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	icalTimezone   = "Europe/Moscow"
+	icalUIDDomain  = "edusync.ru"
+	icalLineLimit  = 75
+	icalDateLayout = "20060102"
+	icalTimeLayout = "150405"
+)
+
+// vtimezoneMoscow описывает Europe/Moscow: с 2014 года смещение постоянно +03:00.
+const vtimezoneMoscow = "BEGIN:VTIMEZONE\r\n" +
+	"TZID:" + icalTimezone + "\r\n" +
+	"BEGIN:STANDARD\r\n" +
+	"DTSTART:19700101T000000\r\n" +
+	"TZOFFSETFROM:+0300\r\n" +
+	"TZOFFSETTO:+0300\r\n" +
+	"TZNAME:MSK\r\n" +
+	"END:STANDARD\r\n" +
+	"END:VTIMEZONE\r\n"
+
+// renderICal формирует календарь iCalendar (RFC 5545) из элементов расписания.
+func renderICal(calName string, items []*domainSchedule.Item, now time.Time) []byte {
+	var b strings.Builder
+	b.WriteString("BEGIN:VCALENDAR\r\n")
+	b.WriteString("VERSION:2.0\r\n")
+	b.WriteString("PRODID:-//EduSync//Schedule//RU\r\n")
+	b.WriteString("CALSCALE:GREGORIAN\r\n")
+	b.WriteString("METHOD:PUBLISH\r\n")
+	writeICalLine(&b, "X-WR-CALNAME:"+escapeICalText(calName))
+	writeICalLine(&b, "X-WR-TIMEZONE:"+icalTimezone)
+	b.WriteString(vtimezoneMoscow)
+
+	stamp := now.UTC().Format(icalDateLayout + "T" + icalTimeLayout + "Z")
+	for _, it := range items {
+		day := it.Date.Format(icalDateLayout)
+		b.WriteString("BEGIN:VEVENT\r\n")
+		writeICalLine(&b, "UID:"+icalUID(it))
+		writeICalLine(&b, "DTSTAMP:"+stamp)
+		writeICalLine(&b, fmt.Sprintf("DTSTART;TZID=%s:%sT%s", icalTimezone, day, it.StartTime.Format(icalTimeLayout)))
+		writeICalLine(&b, fmt.Sprintf("DTEND;TZID=%s:%sT%s", icalTimezone, day, it.EndTime.Format(icalTimeLayout)))
+		writeICalLine(&b, "SUMMARY:"+escapeICalText(fmt.Sprintf("%d. %s", it.PairNumber, it.Subject)))
+		if it.Classroom != "" && it.Classroom != "-" {
+			writeICalLine(&b, "LOCATION:"+escapeICalText(it.Classroom))
+		}
+		writeICalLine(&b, "DESCRIPTION:"+escapeICalText(icalDescription(it)))
+		b.WriteString("END:VEVENT\r\n")
+	}
+	b.WriteString("END:VCALENDAR\r\n")
+	return []byte(b.String())
+}
+
+// icalUID строит UID, не зависящий от ID строки в БД: пара группы в конкретный день
+// остаётся тем же событием, даже если запись расписания была пересоздана.
+func icalUID(it *domainSchedule.Item) string {
+	return fmt.Sprintf("schedule-%d-%s-%d@%s", it.GroupID, it.Date.Format(icalDateLayout), it.PairNumber, icalUIDDomain)
+}
+
+func icalDescription(it *domainSchedule.Item) string {
+	lines := []string{fmt.Sprintf("Группа: %s", it.GroupName)}
+	if it.TeacherInitials != "" {
+		lines = append(lines, fmt.Sprintf("Преподаватель: %s", it.TeacherInitials))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// escapeICalText экранирует значение типа TEXT (RFC 5545, 3.3.11).
+func escapeICalText(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return r.Replace(s)
+}
+
+// writeICalLine пишет строку контента, перенося её по 75 октетов (RFC 5545, 3.1)
+// и не разрывая многобайтовые символы UTF-8.
+func writeICalLine(b *strings.Builder, line string) {
+	limit := icalLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// строка продолжения начинается с пробела, который тоже занимает октет
+		limit = icalLineLimit - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,91 @@
+package schedule
+
+import (
+	domainSchedule "EduSync/internal/domain/schedule"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const queryDateLayout = "2006-01-02"
+
+// moscow — часовой пояс учебных заведений. Смещение фиксированное (+03:00),
+// поэтому не зависим от наличия tzdata в контейнере.
+var moscow = time.FixedZone("MSK", 3*60*60)
+
+// parsePeriod разбирает параметры from, to и week из запроса.
+// week принимает значения current, next или ISO-неделю вида 2025-W14
+// и не может сочетаться с from/to.
+func parsePeriod(c *gin.Context, now time.Time) (domainSchedule.Period, error) {
+	var p domainSchedule.Period
+	from, to, week := c.Query("from"), c.Query("to"), c.Query("week")
+
+	if week != "" {
+		if from != "" || to != "" {
+			return p, errors.New("параметр week нельзя сочетать с from/to")
+		}
+		monday, err := weekStart(week, now)
+		if err != nil {
+			return p, err
+		}
+		p.From = monday
+		p.To = monday.AddDate(0, 0, 6)
+		return p, nil
+	}
+
+	var err error
+	if from != "" {
+		if p.From, err = time.Parse(queryDateLayout, from); err != nil {
+			return p, fmt.Errorf("неверный формат from, ожидается ГГГГ-ММ-ДД")
+		}
+	}
+	if to != "" {
+		if p.To, err = time.Parse(queryDateLayout, to); err != nil {
+			return p, fmt.Errorf("неверный формат to, ожидается ГГГГ-ММ-ДД")
+		}
+	}
+	if !p.From.IsZero() && !p.To.IsZero() && p.To.Before(p.From) {
+		return p, errors.New("to не может быть раньше from")
+	}
+	return p, nil
+}
+
+// weekStart возвращает понедельник запрошенной недели.
+func weekStart(week string, now time.Time) (time.Time, error) {
+	switch week {
+	case "current":
+		return mondayOf(now.In(moscow)), nil
+	case "next":
+		return mondayOf(now.In(moscow)).AddDate(0, 0, 7), nil
+	}
+
+	var year, num int
+	if _, err := fmt.Sscanf(week, "%d-W%d", &year, &num); err != nil || num < 1 || num > 53 {
+		return time.Time{}, fmt.Errorf("неверный параметр week: ожидается current, next или ГГГГ-Wнн")
+	}
+	// 4 января всегда приходится на первую ISO-неделю года
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	monday := mondayOf(jan4).AddDate(0, 0, (num-1)*7)
+	if y, w := monday.ISOWeek(); y != year || w != num {
+		return time.Time{}, fmt.Errorf("в %d году нет недели %d", year, num)
+	}
+	return monday, nil
+}
+
+// mondayOf возвращает понедельник недели, в которую попадает t, в виде даты без времени (UTC).
+func mondayOf(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return d.AddDate(0, 0, -offset)
+}
+
+// respondSchedule отдаёт расписание плоским списком или, при view=days, сгруппированным по дням.
+func respondSchedule(c *gin.Context, status int, items []*domainSchedule.Item) {
+	if c.Query("view") == "days" {
+		c.JSON(status, domainSchedule.GroupByDay(items))
+		return
+	}
+	c.JSON(status, items)
+}
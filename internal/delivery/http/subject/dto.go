@@ -0,0 +1,28 @@
+package subject
+
+// CreateSubjectReq — тело запроса на создание предмета.
+// swagger:model
+type CreateSubjectReq struct {
+	// Название предмета
+	// required: true
+	Name string `json:"name" binding:"required,max=255" example:"Математический анализ"`
+	// ID учебного заведения
+	// required: true
+	InstitutionID int `json:"institution_id" binding:"required,gt=0" example:"1"`
+}
+
+// RenameSubjectReq — тело запроса на переименование предмета.
+// swagger:model
+type RenameSubjectReq struct {
+	// Новое название предмета
+	// required: true
+	Name string `json:"name" binding:"required,max=255" example:"Математический анализ"`
+}
+
+// MergeSubjectsReq — предметы-дубликаты, которые сливаются в выбранный.
+// swagger:model
+type MergeSubjectsReq struct {
+	// ID дубликатов
+	// required: true
+	SourceIDs []int `json:"source_ids" binding:"required,min=1,dive,gt=0" example:"12,15"`
+}
@@ -1,7 +1,11 @@
 package subject
 
 import (
+	"EduSync/internal/delivery/middleware"
+	domainSubject "EduSync/internal/domain/subject"
+	domainUser "EduSync/internal/domain/user"
 	"EduSync/internal/service"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -21,33 +25,127 @@ func NewInstitutionHandler(subjectService service.SubjectService) *SubjectHandle
 // CreateSubjectHandler создает новый предмет
 // @Summary      Создать предмет
 // @Description  Создает новую запись учебного предмета
-// @Tags         Subjects
+// @Tags         Admin
 // @Security     BearerAuth
 // @Accept       json
 // @Produce      json
-// @Param        input  body      object{name=string,institution_id=int}  true  "Данные предмета"
-// @Success      201  {object}  object{subject_id=int}
+// @Param        input  body      CreateSubjectReq  true  "Данные предмета"
+// @Success      201  {object}  Subject
 // @Failure      400  {object} dto.ErrorResponse
+// @Failure      403  {object} dto.ErrorResponse
+// @Failure      409  {object} dto.ErrorResponse
 // @Failure      500  {object} dto.ErrorResponse
-// @Router       /subjects [post]
+// @Router       /admin/subjects [post]
 func (h *SubjectHandler) CreateSubjectHandler(c *gin.Context) {
-	var req struct {
-		Name          string `json:"name" binding:"required"`
-		InstitutionID int    `json:"institution_id" binding:"required"`
+	var req CreateSubjectReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
 	}
 
+	subj, err := h.subjectService.Add(c.Request.Context(), middleware.Actor(c), req.Name, req.InstitutionID)
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, subj)
+}
+
+// RenameSubjectHandler исправляет название предмета
+// @Summary      Переименовать предмет
+// @Tags         Admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id     path  int               true  "ID предмета"
+// @Param        input  body  RenameSubjectReq  true  "Новое название"
+// @Success      200  {object}  Subject
+// @Failure      400  {object} dto.ErrorResponse
+// @Failure      403  {object} dto.ErrorResponse
+// @Failure      404  {object} dto.ErrorResponse
+// @Failure      409  {object} dto.ErrorResponse
+// @Failure      500  {object} dto.ErrorResponse
+// @Router       /admin/subjects/{id} [patch]
+func (h *SubjectHandler) RenameSubjectHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "неправильный аргумент"})
+		return
+	}
+	var req RenameSubjectReq
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
 		return
 	}
-
-	subjectID, err := h.subjectService.Create(c.Request.Context(), req.Name, req.InstitutionID)
+	subj, err := h.subjectService.Rename(c.Request.Context(), middleware.Actor(c), id, req.Name)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondAdminError(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, subj)
+}
 
-	c.JSON(http.StatusCreated, gin.H{"subject_id": subjectID})
+// DeleteSubjectHandler удаляет предмет
+// @Summary      Удалить предмет
+// @Description  Удаляет предмет, который не используется в расписании и чатах
+// @Tags         Admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id  path  int  true  "ID предмета"
+// @Success      200  {object}  object{message=string}
+// @Failure      400  {object} dto.ErrorResponse
+// @Failure      403  {object} dto.ErrorResponse
+// @Failure      404  {object} dto.ErrorResponse
+// @Failure      409  {object} dto.ErrorResponse
+// @Failure      500  {object} dto.ErrorResponse
+// @Router       /admin/subjects/{id} [delete]
+func (h *SubjectHandler) DeleteSubjectHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "неправильный аргумент"})
+		return
+	}
+	if err := h.subjectService.Delete(c.Request.Context(), middleware.Actor(c), id); err != nil {
+		respondAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "предмет удалён"})
+}
+
+// MergeSubjectsHandler объединяет дубликаты предмета
+// @Summary      Объединить предметы
+// @Description  Переносит расписание и чаты предметов source_ids на предмет id и удаляет дубликаты. Названия дубликатов запоминаются: синхронизация расписания относит их к предмету id
+// @Tags         Admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id     path  int               true  "ID предмета, который остаётся"
+// @Param        input  body  MergeSubjectsReq  true  "Дубликаты"
+// @Success      200  {object}  Subject
+// @Failure      400  {object} dto.ErrorResponse
+// @Failure      403  {object} dto.ErrorResponse
+// @Failure      404  {object} dto.ErrorResponse
+// @Failure      409  {object} dto.ErrorResponse
+// @Failure      500  {object} dto.ErrorResponse
+// @Router       /admin/subjects/{id}/merge [post]
+func (h *SubjectHandler) MergeSubjectsHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "неправильный аргумент"})
+		return
+	}
+	var req MergeSubjectsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+	subj, err := h.subjectService.Merge(c.Request.Context(), middleware.Actor(c), id, req.SourceIDs)
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, subj)
 }
 
 // GetSubjectsByInstitution возвращает предметы учреждения
@@ -115,3 +213,20 @@ func (h *SubjectHandler) GetSubjectsByGroup(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, subjects)
 }
+
+func respondAdminError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domainUser.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domainSubject.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domainSubject.ErrExists),
+		errors.Is(err, domainSubject.ErrInUse),
+		errors.Is(err, domainSubject.ErrMergeChatConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domainSubject.ErrInvalidName), errors.Is(err, domainSubject.ErrMergeInstitution):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
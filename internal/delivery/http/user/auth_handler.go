@@ -1,9 +1,12 @@
 package user
 
 import (
+	"EduSync/internal/delivery/middleware"
 	domainUser "EduSync/internal/domain/user"
 	"EduSync/internal/service"
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -29,6 +32,7 @@ func NewAuthHandler(authService service.UserService) *AuthHandler {
 // @Success      201    {object}  object{message=string,user_id=int}
 // @Failure      400    {object}  dto.ErrorResponse
 // @Failure      409    {object}  dto.ErrorResponse
+// @Failure      429    {object}  dto.ErrorResponse
 // @Router       /register [post]
 func (h *AuthHandler) RegisterHandler(c *gin.Context) {
 	var req RegistrationUserReq
@@ -60,14 +64,17 @@ func (h *AuthHandler) RegisterHandler(c *gin.Context) {
 
 // LoginHandler обрабатывает логин.
 // @Summary      Аутентификация пользователя
-// @Description  Вход в систему с использованием email и пароля
+// @Description  Вход в систему с использованием email и пароля. Если у пользователя включена 2FA
+// @Description  или учреждение требует её для его роли, возвращается 202 с токеном второго шага
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
 // @Param        input  body      LoginUserReq  true  "Данные для входа"
 // @Success      200    {object}  PairTokenResp
+// @Success      202    {object}  TwoFactorChallengeResp
 // @Failure      400    {object}  dto.ErrorResponse
 // @Failure      401    {object}  dto.ErrorResponse
+// @Failure      429    {object}  dto.ErrorResponse
 // @Router       /login [post]
 func (h *AuthHandler) LoginHandler(c *gin.Context) {
 	var req LoginUserReq
@@ -80,16 +87,16 @@ func (h *AuthHandler) LoginHandler(c *gin.Context) {
 	userAgent := c.Request.UserAgent()
 	ipAddress := c.ClientIP()
 
-	accessToken, refreshToken, err := h.authService.Login(c.Request.Context(), req.Email, req.Password, userAgent, ipAddress)
+	result, err := h.authService.Login(c.Request.Context(), req.Email, req.Password, userAgent, ipAddress)
 	if err != nil {
+		if middleware.AbortLimited(c, err) {
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, PairTokenResp{
-		AccessToken:  "Bearer " + accessToken,
-		RefreshToken: "Bearer " + refreshToken,
-	})
+	respondLogin(c, result)
 }
 
 // LogoutHandler отзывает токен.
@@ -102,12 +109,11 @@ func (h *AuthHandler) LoginHandler(c *gin.Context) {
 // @Failure      500  {object}  dto.ErrorResponse
 // @Router       /logout [post]
 func (h *AuthHandler) LogoutHandler(c *gin.Context) {
-	token := c.GetHeader("Authorization")
+	token := bearerToken(c)
 	if token == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Токен отсутствует"})
 		return
 	}
-	token = strings.TrimPrefix(token, "Bearer ")
 	if err := h.authService.Logout(c.Request.Context(), token); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка выхода"})
 		return
@@ -116,6 +122,19 @@ func (h *AuthHandler) LogoutHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Выход выполнен"})
 }
 
+// JWKSHandler отдаёт открытые ключи подписи токенов.
+// @Summary      Открытые ключи подписи JWT
+// @Description  JWKS (RFC 7517) для офлайн-проверки токенов EduSync; ключ выбирается по заголовку kid токена
+// @Tags         Auth
+// @Produce      json
+// @Success      200  {object}  domainUser.JWKS
+// @Router       /.well-known/jwks.json [get]
+func (h *AuthHandler) JWKSHandler(c *gin.Context) {
+	// Ключи меняются только при ротации, клиенты могут ненадолго кэшировать набор
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.authService.PublicKeys())
+}
+
 // RefreshTokenHandler обрабатывает обновление access-токена.
 // @Summary      Обновление токена доступа
 // @Description  Обновляет access-токен с использованием refresh-токена
@@ -126,6 +145,7 @@ func (h *AuthHandler) LogoutHandler(c *gin.Context) {
 // @Success      200    {object}  PairTokenResp
 // @Failure      400    {object}  dto.ErrorResponse
 // @Failure      401    {object}  dto.ErrorResponse
+// @Failure      429    {object}  dto.ErrorResponse
 // @Router       /refresh [post]
 func (h *AuthHandler) RefreshTokenHandler(c *gin.Context) {
 	var req RefreshTokenReq
@@ -182,10 +202,15 @@ func (h *AuthHandler) UpdateProfileHandler(c *gin.Context) {
 			FullName:      req.FullName,
 			InstitutionID: req.InstitutionID,
 			GroupID:       req.GroupID,
+			Locale:        req.Locale,
 		},
 		userAgent,
 		ipAddress,
 	)
+	if errors.Is(err, domainUser.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Сотрудник не может сменить учреждение, обратитесь к администратору"})
+		return
+	}
 	if err != nil {
 		// можно различать тип ошибки, но для простоты:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -212,6 +237,7 @@ func (h *AuthHandler) ProfileHandler(c *gin.Context) {
 	isTeacher, _ := c.Get("is_teacher")
 	groupID, _ := c.Get("group_id")
 	institutionID, _ := c.Get("institution_id")
+	role, _ := c.Get("role")
 	c.JSON(http.StatusOK, ProfileResp{
 		UserID:        userID.(int),
 		Email:         email.(string),
@@ -219,23 +245,285 @@ func (h *AuthHandler) ProfileHandler(c *gin.Context) {
 		InstitutionID: institutionID.(int),
 		GroupID:       groupID.(int),
 		IsTeacher:     isTeacher.(bool),
+		Role:          role.(domainUser.Role),
 	})
 }
 
+// RequestDeleteProfileHandler отправляет код для удаления аккаунта.
+// @Summary      Запросить удаление аккаунта
+// @Description  Отправляет на email пользователя код для DELETE /profile. Преподаватель, которому принадлежат чаты, должен сначала удалить их (409)
+// @Tags         Auth
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} object{message=string}
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      409 {object} dto.ErrorResponse
+// @Router       /profile/delete [post]
+func (h *AuthHandler) RequestDeleteProfileHandler(c *gin.Context) {
+	err := h.authService.RequestAccountDeletion(c.Request.Context(), c.GetInt("user_id"))
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"message": "код отправлен"})
+	case errors.Is(err, domainUser.ErrOwnsChats):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
 // DeleteProfileHandler удаляет аккаунт пользователя.
 // @Summary      Удалить свой аккаунт
-// @Description  Полное удаление учётной записи (только для авторизованных пользователей)
+// @Description  Удаляет учётную запись по коду из письма (POST /profile/delete) и завершает все сессии. В течение 14 дней вход восстанавливает аккаунт, затем данные стираются. Преподаватель, которому принадлежат чаты, должен сначала удалить их (409)
 // @Tags         Auth
 // @Security     BearerAuth
+// @Accept       json
+// @Param        input  body  DeleteAccountReq  true  "Код из письма"
 // @Success      200 {object} object{message=string}
+// @Failure      400 {object} dto.ErrorResponse
 // @Failure      401 {object} dto.ErrorResponse
+// @Failure      409 {object} dto.ErrorResponse
 // @Failure      500 {object} dto.ErrorResponse
 // @Router       /profile [delete]
 func (h *AuthHandler) DeleteProfileHandler(c *gin.Context) {
+	var req DeleteAccountReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
 	userID := c.GetInt("user_id")
-	if err := h.authService.DeleteAccount(c.Request.Context(), userID); err != nil {
+	if err := h.authService.DeleteAccount(c.Request.Context(), userID, req.Code); err != nil {
+		switch {
+		case errors.Is(err, domainUser.ErrInvalidOTP):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domainUser.ErrOwnsChats):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "аккаунт удалён, войдите в течение 14 дней, чтобы восстановить его"})
+}
+
+// ChangeEmailHandler запрашивает смену email.
+// @Summary      Запросить смену email
+// @Description  Проверяет пароль и отправляет код на новый адрес. Email преподавателя должен принадлежать домену его учреждения. Адрес меняется только после POST /profile/email/confirm
+// @Tags         Auth
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        input  body  ChangeEmailReq  true  "Новый email и текущий пароль"
+// @Success      200 {object} object{message=string}
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      409 {object} dto.ErrorResponse
+// @Failure      429 {object} dto.ErrorResponse
+// @Failure      500 {object} dto.ErrorResponse
+// @Router       /profile/email [post]
+func (h *AuthHandler) ChangeEmailHandler(c *gin.Context) {
+	var req ChangeEmailReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+	err := h.authService.RequestEmailChange(c.Request.Context(), c.GetInt("user_id"), req.NewEmail, req.Password)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"message": "код отправлен на новый email"})
+	case errors.Is(err, domainUser.ErrInvalidCredentials):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, domainUser.ErrUserAlreadyExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domainUser.ErrCodeRecentlySent):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case errors.Is(err, domainUser.ErrSameEmail), errors.Is(err, domainUser.ErrEmailNotAllowed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// ConfirmEmailChangeHandler подтверждает смену email.
+// @Summary      Подтвердить смену email
+// @Description  Меняет email по коду с нового адреса и сообщает об этом на старый. Все сессии завершаются, текущее устройство получает новую пару токенов
+// @Tags         Auth
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        input  body  ConfirmEmailChangeReq  true  "Код из письма"
+// @Success      200 {object} PairTokenResp
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      409 {object} dto.ErrorResponse
+// @Failure      500 {object} dto.ErrorResponse
+// @Router       /profile/email/confirm [post]
+func (h *AuthHandler) ConfirmEmailChangeHandler(c *gin.Context) {
+	var req ConfirmEmailChangeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "аккаунт удалён"})
+	accessToken, refreshToken, err := h.authService.ConfirmEmailChange(
+		c.Request.Context(), c.GetInt("user_id"), req.Code, c.Request.UserAgent(), c.ClientIP(),
+	)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, PairTokenResp{
+			AccessToken:  "Bearer " + accessToken,
+			RefreshToken: "Bearer " + refreshToken,
+		})
+	case errors.Is(err, domainUser.ErrInvalidOTP), errors.Is(err, domainUser.ErrEmailNotAllowed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domainUser.ErrUserAlreadyExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// ExportProfileHandler выгружает данные пользователя.
+// @Summary      Выгрузить свои данные
+// @Description  ZIP-архив с профилем (profile.json), сообщениями (messages.json), загруженными файлами (files/) и голосами в опросах (poll_votes.json)
+// @Tags         Auth
+// @Security     BearerAuth
+// @Produce      application/zip
+// @Success      200 {file} file
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      500 {object} dto.ErrorResponse
+// @Router       /profile/export [get]
+func (h *AuthHandler) ExportProfileHandler(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="edusync-export.zip"`)
+	if err := h.authService.ExportData(c.Request.Context(), userID, c.Writer); err != nil {
+		// Если архив уже начал передаваться, ответ не заменить — клиент получит обрезанный файл
+		if !c.Writer.Written() {
+			c.Header("Content-Type", "")
+			c.Header("Content-Disposition", "")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+	}
+}
+
+// ChangeRoleHandler назначает пользователю роль.
+// @Summary      Сменить роль пользователя
+// @Description  Администратор учреждения назначает роли в своём учреждении, системный администратор — любые роли. Сессии пользователя завершаются.
+// @Tags         Admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int            true  "ID пользователя"
+// @Param        input  body      ChangeRoleReq  true  "Новая роль"
+// @Success      200    {object}  object{message=string}
+// @Failure      400    {object}  dto.ErrorResponse
+// @Failure      403    {object}  dto.ErrorResponse
+// @Failure      404    {object}  dto.ErrorResponse
+// @Failure      500    {object}  dto.ErrorResponse
+// @Router       /admin/users/{id}/role [put]
+func (h *AuthHandler) ChangeRoleHandler(c *gin.Context) {
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор пользователя"})
+		return
+	}
+	var req ChangeRoleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	err = h.authService.ChangeRole(c.Request.Context(), middleware.Actor(c), targetID, req.Role)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"message": "роль изменена"})
+	case errors.Is(err, domainUser.ErrInvalidRole):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domainUser.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domainUser.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// SessionsHandler возвращает активные сессии пользователя.
+// @Summary      Активные сессии
+// @Description  Список устройств, на которых выполнен вход. Сессия текущего запроса отмечена current=true
+// @Tags         Auth
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {array}   user.Session
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /sessions [get]
+func (h *AuthHandler) SessionsHandler(c *gin.Context) {
+	sessions, err := h.authService.Sessions(c.Request.Context(), c.GetInt("user_id"), bearerToken(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if sessions == nil {
+		sessions = []*domainUser.Session{}
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSessionHandler завершает одну сессию.
+// @Summary      Завершить сессию
+// @Description  Выход на одном устройстве
+// @Tags         Auth
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path      int  true  "ID сессии"
+// @Success      200  {object}  object{message=string}
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /sessions/{id} [delete]
+func (h *AuthHandler) RevokeSessionHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор сессии"})
+		return
+	}
+	err = h.authService.RevokeSession(c.Request.Context(), c.GetInt("user_id"), id)
+	if errors.Is(err, domainUser.ErrSessionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "сессия завершена"})
+}
+
+// RevokeOtherSessionsHandler завершает все сессии, кроме текущей.
+// @Summary      Выйти на других устройствах
+// @Description  Завершает все сессии пользователя, кроме той, из которой выполнен запрос
+// @Tags         Auth
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  object{message=string,revoked=int}
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /sessions [delete]
+func (h *AuthHandler) RevokeOtherSessionsHandler(c *gin.Context) {
+	n, err := h.authService.RevokeOtherSessions(c.Request.Context(), c.GetInt("user_id"), bearerToken(c))
+	if errors.Is(err, domainUser.ErrSessionNotFound) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Токен отозван"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "выполнен выход на других устройствах", "revoked": n})
+}
+
+// bearerToken возвращает токен из заголовка Authorization без префикса "Bearer ".
+func bearerToken(c *gin.Context) string {
+	return strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
 }
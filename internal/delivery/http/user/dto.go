@@ -3,6 +3,7 @@ package user
 import (
 	domainUser "EduSync/internal/domain/user"
 	"errors"
+	"time"
 )
 
 // LoginUserReq тело запроса на вход
@@ -39,6 +40,8 @@ type RegistrationUserReq struct {
 	// Является ли пользователь учителем
 	// required: true
 	IsTeacher *bool `json:"is_teacher" binding:"required" example:"false"`
+	// Язык писем: ru (по умолчанию) или en
+	Locale string `json:"locale" binding:"omitempty,oneof=ru en" example:"ru"`
 }
 
 func (r *RegistrationUserReq) ConvertToSvc() (domainUser.CreateUser, error) {
@@ -48,6 +51,7 @@ func (r *RegistrationUserReq) ConvertToSvc() (domainUser.CreateUser, error) {
 		FullName:      r.FullName,
 		IsTeacher:     *r.IsTeacher,
 		InstitutionID: r.InstitutionID,
+		Locale:        r.Locale,
 	}
 
 	if !*r.IsTeacher {
@@ -100,6 +104,9 @@ type ProfileResp struct {
 	// Является ли пользователь учителем
 	// required: true
 	IsTeacher bool `json:"is_teacher" binding:"required" example:"false"`
+	// Роль пользователя
+	// required: true
+	Role domainUser.Role `json:"role" example:"student"`
 }
 
 // UpdateProfileReq — payload для обновления профиля.
@@ -108,4 +115,114 @@ type UpdateProfileReq struct {
 	FullName      *string `json:"full_name,omitempty"`
 	InstitutionID *int    `json:"institution_id,omitempty"`
 	GroupID       *int    `json:"group_id,omitempty"`
+	// Язык писем: ru или en
+	Locale *string `json:"locale,omitempty" binding:"omitempty,oneof=ru en" example:"en"`
+}
+
+// ChangeEmailReq — запрос на смену email.
+// swagger:model
+type ChangeEmailReq struct {
+	// Новый email, на него придёт код
+	// required: true
+	NewEmail string `json:"new_email" binding:"required,email" example:"new@example.com"`
+
+	// Текущий пароль
+	// required: true
+	Password string `json:"password" binding:"required" example:"secret123"`
+}
+
+// ConfirmEmailChangeReq — подтверждение смены email.
+// swagger:model
+type ConfirmEmailChangeReq struct {
+	// Код из письма, отправленного на новый адрес
+	// required: true
+	Code string `json:"code" binding:"required,len=6" example:"123456"`
+}
+
+// DeleteAccountReq — подтверждение удаления аккаунта.
+// swagger:model
+type DeleteAccountReq struct {
+	// Код из письма
+	// required: true
+	Code string `json:"code" binding:"required,len=6" example:"123456"`
+}
+
+// ChangeRoleReq — тело запроса на смену роли пользователя.
+// swagger:model
+type ChangeRoleReq struct {
+	// Новая роль: student, teacher, institution_admin или system_admin
+	// required: true
+	Role domainUser.Role `json:"role" binding:"required" example:"institution_admin"`
+}
+
+// LoginResp — ответ на вход: пара токенов и, если 2FA подключена при входе, коды восстановления.
+// swagger:model
+type LoginResp struct {
+	// JWT для доступа
+	AccessToken string `json:"access_token"`
+	// JWT для обновления
+	RefreshToken string `json:"refresh_token"`
+	// Одноразовые коды восстановления; показываются один раз
+	RecoveryCodes []string `json:"recovery_codes,omitempty" example:"a1b2c-3d4e5"`
+}
+
+// TwoFactorChallengeResp — ответ первого шага входа, когда нужен код второго фактора.
+// swagger:model
+type TwoFactorChallengeResp struct {
+	// Всегда true: для входа нужен код
+	TwoFactorRequired bool `json:"two_factor_required" example:"true"`
+	// Токен второго шага входа
+	ChallengeToken string `json:"challenge_token" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	// До какого времени действует токен
+	ExpiresAt time.Time `json:"expires_at"`
+	// Учреждение требует 2FA, а она ещё не подключена: сначала /login/2fa/enroll
+	EnrollmentRequired bool `json:"enrollment_required" example:"false"`
+}
+
+// ChallengeReq — запрос второго шага входа без кода.
+// swagger:model
+type ChallengeReq struct {
+	// Токен из ответа /login
+	// required: true
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+// TwoFactorLoginReq — код второго фактора для завершения входа.
+// swagger:model
+type TwoFactorLoginReq struct {
+	// Токен из ответа /login
+	// required: true
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	// Код из приложения, код восстановления или код из письма
+	// required: true
+	Code string `json:"code" binding:"required" example:"123456"`
+}
+
+// TwoFactorCodeReq — код из приложения-аутентификатора.
+// swagger:model
+type TwoFactorCodeReq struct {
+	// Код из приложения (для отключения подходит и код восстановления)
+	// required: true
+	Code string `json:"code" binding:"required" example:"123456"`
+}
+
+// RecoveryCodesResp — новые коды восстановления.
+// swagger:model
+type RecoveryCodesResp struct {
+	// Одноразовые коды восстановления; показываются один раз
+	RecoveryCodes []string `json:"recovery_codes" example:"a1b2c-3d4e5"`
+}
+
+// TwoFactorPolicyReq — роли, для которых учреждение требует 2FA.
+// swagger:model
+type TwoFactorPolicyReq struct {
+	// Роли; пустой список отменяет требование
+	// required: true
+	Roles []domainUser.Role `json:"roles" binding:"required" example:"teacher,institution_admin"`
+}
+
+// TwoFactorPolicyResp — действующая политика 2FA учреждения.
+// swagger:model
+type TwoFactorPolicyResp struct {
+	Roles []domainUser.Role `json:"roles" example:"teacher,institution_admin"`
 }
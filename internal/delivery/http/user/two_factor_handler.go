@@ -0,0 +1,340 @@
+package user
+
+import (
+	"EduSync/internal/delivery/middleware"
+	domainInstitution "EduSync/internal/domain/institution"
+	domainUser "EduSync/internal/domain/user"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LoginTwoFactorHandler завершает вход кодом второго фактора.
+// @Summary      Второй шаг входа
+// @Description  Принимает код из приложения или одноразовый код восстановления. Если 2FA подключалась при входе,
+// @Description  код подтверждает подключение, а в ответе приходят коды восстановления
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        input  body      TwoFactorLoginReq  true  "Токен второго шага и код"
+// @Success      200    {object}  LoginResp
+// @Failure      400    {object}  dto.ErrorResponse
+// @Failure      401    {object}  dto.ErrorResponse
+// @Failure      409    {object}  dto.ErrorResponse
+// @Failure      429    {object}  dto.ErrorResponse
+// @Router       /login/2fa [post]
+func (h *AuthHandler) LoginTwoFactorHandler(c *gin.Context) {
+	var req TwoFactorLoginReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+	result, err := h.authService.LoginTwoFactor(c.Request.Context(), req.ChallengeToken, req.Code, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+	respondLogin(c, result)
+}
+
+// EnrollForChallengeHandler подключает 2FA на втором шаге входа.
+// @Summary      Подключить 2FA при входе
+// @Description  Для пользователей, которым учреждение требует 2FA: возвращает секрет и otpauth:// URI.
+// @Description  Подключение завершается кодом из приложения через /login/2fa
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        input  body      ChallengeReq  true  "Токен второго шага"
+// @Success      200    {object}  user.TwoFactorEnrollment
+// @Failure      400    {object}  dto.ErrorResponse
+// @Failure      401    {object}  dto.ErrorResponse
+// @Failure      409    {object}  dto.ErrorResponse
+// @Failure      429    {object}  dto.ErrorResponse
+// @Router       /login/2fa/enroll [post]
+func (h *AuthHandler) EnrollForChallengeHandler(c *gin.Context) {
+	var req ChallengeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+	enrollment, err := h.authService.EnrollForChallenge(c.Request.Context(), req.ChallengeToken)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// RequestTwoFactorResetHandler отправляет код сброса 2FA на почту.
+// @Summary      Код сброса 2FA на почту
+// @Description  Если нет доступа ни к приложению, ни к кодам восстановления, на почту отправляется код для сброса 2FA
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        input  body      ChallengeReq  true  "Токен второго шага"
+// @Success      200    {object}  object{message=string}
+// @Failure      400    {object}  dto.ErrorResponse
+// @Failure      401    {object}  dto.ErrorResponse
+// @Failure      429    {object}  dto.ErrorResponse
+// @Router       /login/2fa/email [post]
+func (h *AuthHandler) RequestTwoFactorResetHandler(c *gin.Context) {
+	var req ChallengeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+	err := h.authService.RequestTwoFactorReset(c.Request.Context(), req.ChallengeToken)
+	if errors.Is(err, domainUser.ErrChallengeInvalid) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "код отправлен"})
+}
+
+// ResetTwoFactorHandler сбрасывает 2FA по коду из письма и завершает вход.
+// @Summary      Сброс 2FA по коду из письма
+// @Description  Отключает 2FA и выдаёт токены. Если учреждение требует 2FA, возвращается 202 с новым токеном второго шага для повторного подключения
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        input  body      TwoFactorLoginReq  true  "Токен второго шага и код из письма"
+// @Success      200    {object}  LoginResp
+// @Success      202    {object}  TwoFactorChallengeResp
+// @Failure      400    {object}  dto.ErrorResponse
+// @Failure      401    {object}  dto.ErrorResponse
+// @Failure      429    {object}  dto.ErrorResponse
+// @Router       /login/2fa/recover [post]
+func (h *AuthHandler) ResetTwoFactorHandler(c *gin.Context) {
+	var req TwoFactorLoginReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+	result, err := h.authService.ResetTwoFactor(c.Request.Context(), req.ChallengeToken, req.Code, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+	respondLogin(c, result)
+}
+
+// TwoFactorStatusHandler возвращает состояние 2FA.
+// @Summary      Состояние 2FA
+// @Tags         Auth
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  user.TwoFactorStatus
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /2fa [get]
+func (h *AuthHandler) TwoFactorStatusHandler(c *gin.Context) {
+	status, err := h.authService.TwoFactorStatus(c.Request.Context(), middleware.Actor(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// EnrollTwoFactorHandler начинает подключение 2FA.
+// @Summary      Подключить 2FA
+// @Description  Возвращает секрет и otpauth:// URI для приложения-аутентификатора. 2FA включится после /2fa/enable
+// @Tags         Auth
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  user.TwoFactorEnrollment
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Router       /2fa/enroll [post]
+func (h *AuthHandler) EnrollTwoFactorHandler(c *gin.Context) {
+	enrollment, err := h.authService.EnrollTwoFactor(c.Request.Context(), c.GetInt("user_id"))
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// EnableTwoFactorHandler включает 2FA кодом из приложения.
+// @Summary      Включить 2FA
+// @Description  Подтверждает подключение первым кодом из приложения и выдаёт коды восстановления
+// @Tags         Auth
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        input  body      TwoFactorCodeReq  true  "Код из приложения"
+// @Success      200    {object}  RecoveryCodesResp
+// @Failure      400    {object}  dto.ErrorResponse
+// @Failure      401    {object}  dto.ErrorResponse
+// @Failure      409    {object}  dto.ErrorResponse
+// @Router       /2fa/enable [post]
+func (h *AuthHandler) EnableTwoFactorHandler(c *gin.Context) {
+	var req TwoFactorCodeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+	codes, err := h.authService.EnableTwoFactor(c.Request.Context(), c.GetInt("user_id"), req.Code)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, RecoveryCodesResp{RecoveryCodes: codes})
+}
+
+// DisableTwoFactorHandler отключает 2FA.
+// @Summary      Отключить 2FA
+// @Description  Нужен код из приложения или код восстановления. Нельзя, если учреждение требует 2FA для роли пользователя
+// @Tags         Auth
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        input  body      TwoFactorCodeReq  true  "Код"
+// @Success      200    {object}  object{message=string}
+// @Failure      400    {object}  dto.ErrorResponse
+// @Failure      401    {object}  dto.ErrorResponse
+// @Failure      403    {object}  dto.ErrorResponse
+// @Failure      409    {object}  dto.ErrorResponse
+// @Router       /2fa/disable [post]
+func (h *AuthHandler) DisableTwoFactorHandler(c *gin.Context) {
+	var req TwoFactorCodeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+	if err := h.authService.DisableTwoFactor(c.Request.Context(), middleware.Actor(c), req.Code); err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "двухфакторная аутентификация отключена"})
+}
+
+// RegenerateRecoveryCodesHandler выдаёт новые коды восстановления.
+// @Summary      Новые коды восстановления
+// @Description  Прежние коды перестают действовать. Нужен код из приложения
+// @Tags         Auth
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        input  body      TwoFactorCodeReq  true  "Код из приложения"
+// @Success      200    {object}  RecoveryCodesResp
+// @Failure      400    {object}  dto.ErrorResponse
+// @Failure      401    {object}  dto.ErrorResponse
+// @Failure      409    {object}  dto.ErrorResponse
+// @Router       /2fa/recovery-codes [post]
+func (h *AuthHandler) RegenerateRecoveryCodesHandler(c *gin.Context) {
+	var req TwoFactorCodeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+	codes, err := h.authService.RegenerateRecoveryCodes(c.Request.Context(), c.GetInt("user_id"), req.Code)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, RecoveryCodesResp{RecoveryCodes: codes})
+}
+
+// TwoFactorPolicyHandler возвращает роли, для которых учреждение требует 2FA.
+// @Summary      Политика 2FA учреждения
+// @Tags         Admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path      int  true  "ID учреждения"
+// @Success      200  {object}  TwoFactorPolicyResp
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/institutions/{id}/2fa-policy [get]
+func (h *AuthHandler) TwoFactorPolicyHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор учреждения"})
+		return
+	}
+	roles, err := h.authService.TwoFactorPolicy(c.Request.Context(), middleware.Actor(c), id)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, TwoFactorPolicyResp{Roles: roles})
+}
+
+// SetTwoFactorPolicyHandler задаёт роли, для которых учреждение требует 2FA.
+// @Summary      Изменить политику 2FA учреждения
+// @Description  Пользователи перечисленных ролей без 2FA подключат её при следующем входе
+// @Tags         Admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int                 true  "ID учреждения"
+// @Param        input  body      TwoFactorPolicyReq  true  "Роли"
+// @Success      200    {object}  TwoFactorPolicyResp
+// @Failure      400    {object}  dto.ErrorResponse
+// @Failure      403    {object}  dto.ErrorResponse
+// @Failure      404    {object}  dto.ErrorResponse
+// @Failure      500    {object}  dto.ErrorResponse
+// @Router       /admin/institutions/{id}/2fa-policy [put]
+func (h *AuthHandler) SetTwoFactorPolicyHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор учреждения"})
+		return
+	}
+	var req TwoFactorPolicyReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+	roles, err := h.authService.SetTwoFactorPolicy(c.Request.Context(), middleware.Actor(c), id, req.Roles)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, TwoFactorPolicyResp{Roles: roles})
+}
+
+// respondLogin отвечает парой токенов или, если нужен второй шаг, токеном второго шага.
+func respondLogin(c *gin.Context, result *domainUser.LoginResult) {
+	if ch := result.Challenge; ch != nil {
+		c.JSON(http.StatusAccepted, TwoFactorChallengeResp{
+			TwoFactorRequired:  true,
+			ChallengeToken:     ch.Token,
+			ExpiresAt:          ch.ExpiresAt,
+			EnrollmentRequired: ch.EnrollmentRequired,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, LoginResp{
+		AccessToken:   "Bearer " + result.AccessToken,
+		RefreshToken:  "Bearer " + result.RefreshToken,
+		RecoveryCodes: result.RecoveryCodes,
+	})
+}
+
+func respondTwoFactorError(c *gin.Context, err error) {
+	if middleware.AbortLimited(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, domainUser.ErrInvalidOTP), errors.Is(err, domainUser.ErrChallengeInvalid):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, domainUser.ErrTwoFactorEnabled), errors.Is(err, domainUser.ErrTwoFactorNotEnrolled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domainUser.ErrTwoFactorRequired), errors.Is(err, domainUser.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domainUser.ErrInvalidRole):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domainUser.ErrUserNotFound), errors.Is(err, domainInstitution.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"EduSync/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// CalendarTokenMiddleware авторизует запрос по секретному токену из пути.
+// Календарные приложения не умеют передавать заголовок Authorization,
+// поэтому подписка на расписание работает по ссылке с токеном.
+func CalendarTokenMiddleware(
+	calendarTokenRepo repository.CalendarTokenRepository,
+	log *logrus.Logger,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.Param("token")
+		if token == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Требуется токен календаря"})
+			c.Abort()
+			return
+		}
+
+		userID, err := calendarTokenRepo.UserIDByToken(c.Request.Context(), token)
+		if err != nil {
+			log.Errorf("Ошибка проверки токена календаря: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка проверки токена"})
+			c.Abort()
+			return
+		}
+		if userID == 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Токен календаря недействителен"})
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", userID)
+		c.Next()
+	}
+}
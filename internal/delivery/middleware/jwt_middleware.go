@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	domainUser "EduSync/internal/domain/user"
 	"github.com/sirupsen/logrus"
 	"net/http"
 	"strings"
@@ -34,7 +35,7 @@ func JWTMiddleware(
 
 		tokenStr := parts[1]
 		// Парсим JWT
-		claims, err := jwtManager.ParseJWT(tokenStr, log)
+		claims, err := jwtManager.ParseTyped(tokenStr, domainUser.TokenAccess, log)
 		if err != nil {
 
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверный или просроченный токен"})
@@ -58,6 +59,12 @@ func JWTMiddleware(
 		// Пробрасываем данные пользователя в контекст
 		c.Set("user_id", claims.ID)
 		c.Set("is_teacher", claims.IsTeacher)
+		role := claims.Role
+		if role == "" {
+			// токены, выданные до появления ролей
+			role = domainUser.DefaultRole(claims.IsTeacher)
+		}
+		c.Set("role", role)
 		c.Set("email", claims.Email)
 		c.Set("full_name", claims.FullName)
 		c.Set("group_id", claims.GroupId)
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	domainUser "EduSync/internal/domain/user"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// RequirePermission пропускает запрос, только если роль пользователя
+// из JWTMiddleware дает указанное разрешение.
+func RequirePermission(perm domainUser.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get("role")
+		role, ok := value.(domainUser.Role)
+		if !exists || !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Нет информации о пользователе"})
+			c.Abort()
+			return
+		}
+		if !role.Can(perm) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Недостаточно прав"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// Actor собирает из контекста запроса пользователя, выполняющего действие.
+func Actor(c *gin.Context) domainUser.Actor {
+	role, _ := c.Get("role")
+	r, _ := role.(domainUser.Role)
+	return domainUser.Actor{
+		ID:            c.GetInt("user_id"),
+		Role:          r,
+		InstitutionID: c.GetInt("institution_id"),
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	domainRateLimit "EduSync/internal/domain/ratelimit"
+	"EduSync/internal/service"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit ограничивает число запросов с одного IP по политике.
+func RateLimit(limiter service.RateLimiter, policy domainRateLimit.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if AbortLimited(c, limiter.Hit(c.Request.Context(), policy, c.ClientIP())) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// AbortLimited отвечает 429 с заголовком Retry-After, если err — превышение лимита.
+func AbortLimited(c *gin.Context, err error) bool {
+	var limited *domainRateLimit.LimitedError
+	if !errors.As(err, &limited) {
+		return false
+	}
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(limited.RetryAfter.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": limited.Error()})
+	c.Abort()
+	return true
+}
@@ -1,6 +1,7 @@
 package ws
 
 import (
+	domainUser "EduSync/internal/domain/user"
 	"EduSync/internal/repository"
 	"EduSync/internal/util"
 	"fmt"
@@ -32,7 +33,7 @@ func HandleWebSocket(hub *Hub, mgr *util.JWTManager, tokenRepo repository.TokenR
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "требуется авторизация"})
 			return
 		}
-		claims, err := mgr.ParseJWT(parts[1], log)
+		claims, err := mgr.ParseTyped(parts[1], domainUser.TokenAccess, log)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "некорректный токен"})
 			return
@@ -53,9 +54,13 @@ func HandleWebSocket(hub *Hub, mgr *util.JWTManager, tokenRepo repository.TokenR
 			userID: claims.ID,
 			hub:    hub,
 		}
+		hub.Subscribe(UserRoom(client.userID), client)
 		// 3) старт чита/пиши
 		go client.writePump()
 		client.readPump()
+		// после Leave рассылки в клиента больше не идут, канал можно закрыть
+		hub.Leave(client)
+		close(client.send)
 	}
 }
 
@@ -64,8 +69,9 @@ func (c *Client) readPump() {
 	defer c.conn.Close()
 	for {
 		var msg struct {
-			Action string `json:"action"`
-			ChatID int    `json:"chat_id"`
+			Action  string `json:"action"`
+			ChatID  int    `json:"chat_id"`
+			GroupID int    `json:"group_id"`
 		}
 		if err := c.conn.ReadJSON(&msg); err != nil {
 			break
@@ -77,6 +83,12 @@ func (c *Client) readPump() {
 		case "unsubscribe":
 			room := fmt.Sprintf("chat_%d", msg.ChatID)
 			c.hub.Unsubscribe(room, c)
+		case "subscribe_schedule":
+			room := fmt.Sprintf("group_%d", msg.GroupID)
+			c.hub.Subscribe(room, c)
+		case "unsubscribe_schedule":
+			room := fmt.Sprintf("group_%d", msg.GroupID)
+			c.hub.Unsubscribe(room, c)
 		default:
 			// игнорируем
 		}
@@ -1,6 +1,7 @@
 package ws
 
 import (
+	"fmt"
 	"sync"
 )
 
@@ -41,6 +42,24 @@ func (h *Hub) Unsubscribe(room string, c *Client) {
 	}
 }
 
+// Leave удаляет клиента из всех комнат; вызывается при закрытии соединения.
+func (h *Hub) Leave(c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for room, conns := range h.rooms {
+		delete(conns, c)
+		if len(conns) == 0 {
+			delete(h.rooms, room)
+		}
+	}
+}
+
+// UserRoom — личная комната пользователя: в неё попадают все его соединения
+// независимо от подписок на чаты.
+func UserRoom(userID int) string {
+	return fmt.Sprintf("user_%d", userID)
+}
+
 // Broadcast шлёт событие во все соединения комнаты
 func (h *Hub) Broadcast(room, event string, data interface{}) {
 	h.mu.RLock()
@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Entity — тип изменённой сущности.
+type Entity string
+
+const (
+	EntityInstitution Entity = "institution"
+	EntityEmailMask   Entity = "email_mask"
+	EntityGroup       Entity = "group"
+	EntitySubject     Entity = "subject"
+	EntityUser        Entity = "user"
+	EntityMessage     Entity = "message"
+)
+
+// Action — выполненное действие.
+type Action string
+
+const (
+	ActionCreate         Action = "create"
+	ActionUpdate         Action = "update"
+	ActionDelete         Action = "delete"
+	ActionMerge          Action = "merge"
+	ActionRoleChange     Action = "role_change"
+	ActionTokenReuse     Action = "token_reuse"      // повторное использование refresh-токена
+	ActionTwoFactorReset Action = "two_factor_reset" // 2FA сброшена по коду из письма
+	ActionEmailChange    Action = "email_change"     // email подтверждён кодом с нового адреса
+	ActionPurge          Action = "purge"            // сообщение удалено безвозвратно
+)
+
+// Entry — запись журнала административных действий.
+// swagger:model
+type Entry struct {
+	// ID записи
+	// example: 1
+	ID int `json:"id"`
+
+	// Кто выполнил действие; пусто, если пользователь удалён
+	// example: 7
+	UserID *int `json:"user_id"`
+
+	// Учебное заведение, к которому относится изменение
+	// example: 1
+	InstitutionID *int `json:"institution_id"`
+
+	// Тип сущности
+	// example: subject
+	Entity Entity `json:"entity"`
+
+	// ID сущности
+	// example: 12
+	EntityID int `json:"entity_id"`
+
+	// Действие
+	// example: merge
+	Action Action `json:"action"`
+
+	// Подробности изменения: прежние и новые значения
+	Details json.RawMessage `json:"details" swaggertype:"object"`
+
+	// Время действия
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Filter — условия выборки журнала.
+type Filter struct {
+	InstitutionID int // 0 — все учреждения
+	Entity        Entity
+	EntityID      int
+	Limit         int
+	Offset        int
+}
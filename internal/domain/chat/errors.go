@@ -7,4 +7,7 @@ var (
 	ErrPermissionDenied = errors.New("permission denied")
 	ErrAlreadyFavorited = errors.New("already favorited")
 	ErrNotFavorited     = errors.New("not favorited")
+	ErrInvalidCursor    = errors.New("укажите только один из параметров before_id и after_id")
+	ErrInvalidParent    = errors.New("сообщение для ответа не найдено в этом чате")
+	ErrInvalidKind      = errors.New("неизвестный вид сообщения")
 )
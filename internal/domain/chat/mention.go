@@ -0,0 +1,92 @@
+package chat
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MentionAll — упоминание всех участников чата.
+const MentionAll = "all"
+
+// MentionEvent — личное событие message:mention для упомянутого пользователя.
+// swagger:model
+type MentionEvent struct {
+	// ID чата
+	// example: 5
+	ChatID int `json:"chat_id"`
+
+	// ID сообщения
+	// example: 120
+	MessageID int `json:"message_id"`
+
+	// Автор сообщения
+	// example: 10
+	AuthorID int `json:"author_id"`
+
+	// Сообщение с упоминанием
+	Message *Message `json:"message"`
+}
+
+// ParseMentions находит в тексте упоминания @ФИО и @all и возвращает ID упомянутых
+// участников без автора и повторов. Имя сравнивается без учёта регистра, из
+// подходящих выбирается самое длинное; @ внутри слова (например, в email) не считается.
+// Если самое длинное имя носят несколько участников, упомянутыми считаются все они:
+// по тексту их не различить, а молча выбрать одного — значит не уведомить адресата.
+func ParseMentions(text string, participants []*Participant, authorID int) []int {
+	lower := strings.ToLower(text)
+	seen := make(map[int]bool)
+	var ids []int
+	add := func(userID int) {
+		if userID != authorID && !seen[userID] {
+			seen[userID] = true
+			ids = append(ids, userID)
+		}
+	}
+
+	for i := strings.IndexByte(lower, '@'); i >= 0; {
+		if prev, _ := utf8.DecodeLastRuneInString(lower[:i]); i == 0 || !isWordRune(prev) {
+			rest := lower[i+1:]
+			if mentionPrefix(rest, MentionAll) {
+				for _, p := range participants {
+					add(p.UserID)
+				}
+			} else {
+				var best []*Participant
+				bestLen := 0
+				for _, p := range participants {
+					name := strings.ToLower(strings.TrimSpace(p.FullName))
+					if len(name) < bestLen || !mentionPrefix(rest, name) {
+						continue
+					}
+					if len(name) > bestLen {
+						best, bestLen = best[:0], len(name)
+					}
+					best = append(best, p)
+				}
+				for _, p := range best {
+					add(p.UserID)
+				}
+			}
+		}
+		next := strings.IndexByte(lower[i+1:], '@')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return ids
+}
+
+// mentionPrefix сообщает, что s начинается с name и имя не продолжается буквой или цифрой.
+func mentionPrefix(s, name string) bool {
+	if name == "" || !strings.HasPrefix(s, name) {
+		return false
+	}
+	next, _ := utf8.DecodeRuneInString(s[len(name):])
+	return len(s) == len(name) || !isWordRune(next)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
@@ -29,14 +29,219 @@ type Message struct {
 	// example: 7
 	ParentMessageID *int `json:"parent_message_id,omitempty"`
 
+	// Вид сообщения: message или announcement
+	// example: message
+	Kind string `json:"kind"`
+
 	// Время создания
 	// example: 2023-01-15T09:30:00Z
 	CreatedAt time.Time `json:"created_at"`
 
+	// Время закрепления
+	// example: 2023-01-15T09:45:00Z
+	PinnedAt *time.Time `json:"pinned_at,omitempty"`
+
+	// Кто закрепил сообщение
+	// example: 10
+	PinnedBy *int `json:"pinned_by,omitempty"`
+
+	// Время последнего редактирования
+	// example: 2023-01-15T09:35:00Z
+	EditedAt *time.Time `json:"edited_at,omitempty"`
+
+	// Время удаления; у удалённого сообщения нет текста и файлов
+	// example: 2023-01-15T09:40:00Z
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	// Кто удалил сообщение
+	// example: 10
+	DeletedBy *int `json:"deleted_by,omitempty"`
+
+	// Версия: растёт при создании, правке и удалении; курсор для синхронизации
+	// example: 1042
+	Version int64 `json:"version"`
+
+	// Число ответов на сообщение
+	// example: 4
+	ReplyCount int `json:"reply_count"`
+
+	// Время последнего ответа
+	// example: 2023-01-15T10:05:00Z
+	LastReplyAt *time.Time `json:"last_reply_at,omitempty"`
+
+	// Сообщение, на которое дан ответ
+	Parent *MessagePreview `json:"parent,omitempty"`
+
+	// Реакции в порядке появления
+	Reactions []Reaction `json:"reactions,omitempty"`
+
 	// Прикрепленные файлы
 	Files []FileInfo `json:"files,omitempty"`
 }
 
+// Виды сообщений. Объявление может опубликовать только владелец чата,
+// о нём получают письмо все участники.
+const (
+	KindMessage      = "message"
+	KindAnnouncement = "announcement"
+)
+
+// PinEvent — событие message:pinned для WebSocket.
+// swagger:model
+type PinEvent struct {
+	// ID сообщения
+	// example: 120
+	MessageID int `json:"message_id"`
+
+	// ID чата
+	// example: 5
+	ChatID int `json:"chat_id"`
+
+	// true — закреплено, false — откреплено
+	// example: true
+	Pinned bool `json:"pinned"`
+
+	// Кто закрепил или открепил
+	// example: 10
+	UserID int `json:"user_id"`
+
+	// Сообщение после изменения
+	Message *Message `json:"message"`
+}
+
+// MessagePreview — краткое представление родительского сообщения в ответе.
+// swagger:model
+type MessagePreview struct {
+	// ID сообщения
+	// example: 7
+	ID int `json:"id"`
+
+	// ID автора
+	// example: 10
+	UserID int `json:"user_id"`
+
+	// Начало текста
+	// example: Вопросы по лабораторной №3 пишите сюда
+	Text *string `json:"text,omitempty"`
+
+	// Время создания
+	// example: 2023-01-15T09:30:00Z
+	CreatedAt time.Time `json:"created_at"`
+
+	// Время удаления, если родитель удалён
+	// example: 2023-01-15T09:40:00Z
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// ReplyStats — сводка ответов на сообщение.
+type ReplyStats struct {
+	Count       int
+	LastReplyAt time.Time
+}
+
+// Thread — сообщение и страница ответов на него.
+// swagger:model
+type Thread struct {
+	// Родительское сообщение
+	Parent *Message `json:"parent"`
+
+	// Ответы, новые первыми
+	Replies []*Message `json:"replies"`
+}
+
+// Действия, после которых сохраняется прежний текст сообщения.
+const (
+	RevisionEdit   = "edit"
+	RevisionDelete = "delete"
+)
+
+// MessageRevision — прежний текст сообщения до правки или удаления.
+// swagger:model
+type MessageRevision struct {
+	// ID версии
+	// example: 3
+	ID int `json:"id"`
+
+	// ID сообщения
+	// example: 120
+	MessageID int `json:"message_id"`
+
+	// Текст до изменения
+	// example: Привет! Как дела?
+	Text *string `json:"text,omitempty"`
+
+	// Что произошло с текстом: edit или delete
+	// example: edit
+	Action string `json:"action"`
+
+	// Кто изменил сообщение
+	// example: 10
+	UserID *int `json:"user_id,omitempty"`
+
+	// Когда текст был заменён
+	// example: 2023-01-15T09:35:00Z
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MessageDeletion — событие удаления сообщения для WebSocket.
+// swagger:model
+type MessageDeletion struct {
+	// ID сообщения
+	// example: 120
+	ID int `json:"id"`
+
+	// ID чата
+	// example: 5
+	ChatID int `json:"chat_id"`
+
+	// Кто удалил
+	// example: 10
+	DeletedBy int `json:"deleted_by"`
+
+	// Время удаления
+	// example: 2023-01-15T09:40:00Z
+	DeletedAt time.Time `json:"deleted_at"`
+
+	// Версия надгробия для синхронизации
+	// example: 1043
+	Version int64 `json:"version,omitempty"`
+
+	// Модератор стёр сообщение вместе с историей, файлами и реакциями; остаётся только надгробие
+	// example: false
+	Purged bool `json:"purged"`
+}
+
+// MessageQuery — параметры постраничной выборки сообщений. Страница задаётся
+// границей по id: BeforeID — более старые сообщения, AfterID — более новые.
+// ParentID ограничивает выборку ответами на сообщение; ViewerID — пользователь,
+// для которого отмечаются его реакции.
+type MessageQuery struct {
+	BeforeID int
+	AfterID  int
+	Limit    int
+	ParentID int
+	ViewerID int
+}
+
+// SyncResult — изменения в чате после версии курсора.
+// swagger:model
+type SyncResult struct {
+	// Новые и отредактированные сообщения в порядке версий
+	Messages []*Message `json:"messages"`
+
+	// ID удалённых сообщений
+	// example: [118,121]
+	DeletedIDs []int `json:"deleted_ids"`
+
+	// Курсор для следующего запроса
+	// example: 1042
+	Cursor int64 `json:"cursor"`
+
+	// Есть ли ещё изменения после курсора
+	// example: false
+	HasMore bool `json:"has_more"`
+}
+
 // FileInfo модель файла
 // swagger:model
 type FileInfo struct {
@@ -0,0 +1,27 @@
+package chat
+
+import "time"
+
+// ReadReceipt — событие прочтения сообщений участником чата.
+// swagger:model
+type ReadReceipt struct {
+	// ID чата
+	// example: 5
+	ChatID int `json:"chat_id"`
+
+	// Кто прочитал
+	// example: 10
+	UserID int `json:"user_id"`
+
+	// Последнее прочитанное сообщение
+	// example: 120
+	MessageID int `json:"message_id"`
+
+	// Сколько участников, кроме автора, прочитали это сообщение
+	// example: 14
+	ReadCount int `json:"read_count"`
+
+	// Время прочтения
+	// example: 2023-01-15T09:30:00Z
+	ReadAt time.Time `json:"read_at"`
+}
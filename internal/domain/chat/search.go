@@ -0,0 +1,44 @@
+package chat
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrEmptyQuery возвращается, если поисковый запрос пуст.
+var ErrEmptyQuery = errors.New("поисковый запрос не может быть пустым")
+
+// HighlightStart и HighlightStop обрамляют найденные слова в ответе базы;
+// сервис заменяет их разметкой после экранирования текста.
+const (
+	HighlightStart = "\x01"
+	HighlightStop  = "\x02"
+)
+
+// SearchFilter — параметры поиска по сообщениям чатов пользователя.
+type SearchFilter struct {
+	UserID   int    // ищем только в чатах, где пользователь участник или владелец
+	Query    string // запрос в синтаксисе websearch: слова, "фразы", -исключения, or
+	ChatID   int    // 0 — во всех чатах пользователя
+	AuthorID int
+	From     *time.Time // включительно
+	To       *time.Time // не включительно
+	HasFiles *bool
+	Limit    int
+	Offset   int
+}
+
+// SearchHit — найденное сообщение.
+// swagger:model
+type SearchHit struct {
+	// Сообщение
+	Message *Message `json:"message"`
+
+	// Релевантность, больше — выше в выдаче
+	// example: 0.0759
+	Rank float64 `json:"rank"`
+
+	// Фрагменты текста с найденными словами в <mark>; остальной текст экранирован как HTML
+	// example: сдать <mark>лабораторную</mark> до пятницы
+	Headline string `json:"headline"`
+}
@@ -0,0 +1,33 @@
+package email
+
+import "errors"
+
+// ErrTemplateNotFound возвращается, если для письма нет шаблона.
+var ErrTemplateNotFound = errors.New("шаблон письма не найден")
+
+// Message — готовое письмо: HTML и текстовая альтернатива для почтовых клиентов без HTML.
+type Message struct {
+	To      string
+	Subject string
+	HTML    string // пусто — письмо только текстовое
+	Text    string
+}
+
+// Branding — оформление письма: учреждение получателя или EduSync по умолчанию.
+type Branding struct {
+	Name    string
+	LogoURL string
+	Color   string // #rrggbb
+}
+
+// Data — данные, которые подставляются в шаблон письма.
+type Data struct {
+	FullName  string
+	Brand     Branding
+	Code      string
+	Link      string
+	ExpiresIn int // срок действия кода или ссылки, в часах
+
+	// Vars — поля, нужные отдельным уведомлениям
+	Vars map[string]interface{}
+}
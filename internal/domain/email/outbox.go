@@ -0,0 +1,76 @@
+package email
+
+import "time"
+
+// Status — состояние доставки письма из очереди.
+type Status string
+
+const (
+	StatusPending Status = "pending" // ждёт отправки или повторной попытки
+	StatusSent    Status = "sent"
+	StatusFailed  Status = "failed" // попытки исчерпаны
+)
+
+// OutboxMessage — письмо в очереди на отправку.
+// swagger:model
+type OutboxMessage struct {
+	// ID письма
+	// example: 42
+	ID int `json:"id"`
+
+	// Получатель-пользователь; пусто, если пользователь удалён
+	// example: 7
+	UserID *int `json:"user_id"`
+
+	// Учреждение получателя
+	// example: 1
+	InstitutionID *int `json:"institution_id"`
+
+	// Шаблон письма
+	// example: register
+	Template string `json:"template"`
+
+	// Адрес получателя
+	// example: student@college.ru
+	To string `json:"to"`
+
+	// Тема письма
+	Subject string `json:"subject"`
+
+	Text string `json:"-"`
+	HTML string `json:"-"`
+
+	// Состояние доставки
+	// example: failed
+	Status Status `json:"status"`
+
+	// Сделано попыток отправки
+	// example: 8
+	Attempts int `json:"attempts"`
+
+	// Время следующей попытки
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+
+	// Ошибка последней попытки
+	// example: smtp send: dial tcp: i/o timeout
+	LastError string `json:"last_error,omitempty"`
+
+	// Время постановки в очередь
+	CreatedAt time.Time `json:"created_at"`
+
+	// Время отправки
+	SentAt *time.Time `json:"sent_at,omitempty"`
+}
+
+// Message возвращает письмо для транспорта.
+func (m *OutboxMessage) Message() *Message {
+	return &Message{To: m.To, Subject: m.Subject, HTML: m.HTML, Text: m.Text}
+}
+
+// OutboxFilter — условия выборки писем из очереди.
+type OutboxFilter struct {
+	InstitutionID int // 0 — все учреждения
+	Status        Status
+	Limit         int
+	Offset        int
+}
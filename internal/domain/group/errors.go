@@ -0,0 +1,14 @@
+package group
+
+import "errors"
+
+var (
+	// ErrNotFound возвращается, если группа не найдена.
+	ErrNotFound = errors.New("группа не найдена")
+	// ErrInvalidName возвращается для пустого названия группы.
+	ErrInvalidName = errors.New("название группы не может быть пустым")
+	// ErrExists возвращается, если группа с таким названием уже есть в учреждении.
+	ErrExists = errors.New("группа с таким названием уже существует")
+	// ErrInUse возвращается при удалении группы, у которой есть расписание или чаты.
+	ErrInUse = errors.New("у группы есть расписание или чаты")
+)
@@ -0,0 +1,23 @@
+package institution
+
+import "errors"
+
+var (
+	// ErrNotFound возвращается, если учебное заведение не найдено.
+	ErrNotFound = errors.New("учебное заведение не найдено")
+	// ErrInUse возвращается при удалении учреждения, на которое ссылаются другие данные.
+	ErrInUse = errors.New("у учебного заведения есть пользователи, группы или предметы")
+	// ErrInvalidName возвращается для пустого названия учреждения.
+	ErrInvalidName = errors.New("название учреждения не может быть пустым")
+	// ErrInvalidProvider возвращается для незарегистрированного источника расписания
+	// или настроек, не являющихся JSON-объектом.
+	ErrInvalidProvider = errors.New("некорректный источник расписания")
+	// ErrInvalidBranding возвращается для логотипа не по http(s) или цвета не в формате #rrggbb.
+	ErrInvalidBranding = errors.New("логотип должен быть ссылкой http(s), цвет — в формате #rrggbb")
+	// ErrMaskNotFound возвращается, если почтовая маска не найдена.
+	ErrMaskNotFound = errors.New("почтовая маска не найдена")
+	// ErrMaskTaken возвращается, если маска уже закреплена за учреждением.
+	ErrMaskTaken = errors.New("почтовая маска уже используется")
+	// ErrInvalidMask возвращается для маски, не похожей на почтовый домен.
+	ErrInvalidMask = errors.New("маска должна быть почтовым доменом, например college.ru")
+)
@@ -1,5 +1,7 @@
 package institution
 
+import "encoding/json"
+
 // Institution представляет учебное заведение
 // swagger:model
 type Institution struct {
@@ -10,11 +12,30 @@ type Institution struct {
 	// Название учреждения
 	// example: Московский Политех
 	Name string `json:"name"`
+
+	// Источник расписания (rksi, upload); пусто — расписание не синхронизируется
+	// example: rksi
+	ScheduleProvider string `json:"schedule_provider,omitempty"`
+
+	// Настройки источника расписания
+	ProviderConfig json.RawMessage `json:"-"`
+
+	// Логотип для писем
+	// example: https://edusync.ru/static/logo.png
+	LogoURL string `json:"logo_url,omitempty"`
+
+	// Фирменный цвет писем
+	// example: #1a73e8
+	BrandColor string `json:"brand_color,omitempty"`
 }
 
 // EmailMask представляет почтовую маску
 // swagger:model
 type EmailMask struct {
+	// ID маски
+	// example: 3
+	ID int `json:"id"`
+
 	// ID учебного заведения
 	// example: 5
 	InstitutionID int `json:"institution_id"`
@@ -23,3 +44,12 @@ type EmailMask struct {
 	// example: "@mospolytech.ru"
 	EmailMask string `json:"email_mask"`
 }
+
+// Update содержит изменяемые поля учреждения; nil — поле не меняется.
+type Update struct {
+	Name             *string
+	ScheduleProvider *string
+	ProviderConfig   json.RawMessage
+	LogoURL          *string
+	BrandColor       *string
+}
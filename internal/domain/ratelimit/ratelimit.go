@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrLimited — попыток слишком много, ключ временно заблокирован.
+var ErrLimited = errors.New("слишком много попыток")
+
+// LimitedError сообщает, через сколько можно повторить попытку.
+type LimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LimitedError) Error() string {
+	return fmt.Sprintf("слишком много попыток, повторите через %d с", int(math.Ceil(e.RetryAfter.Seconds())))
+}
+
+// Is позволяет проверять ошибку через errors.Is(err, ErrLimited).
+func (e *LimitedError) Is(target error) bool {
+	return target == ErrLimited
+}
+
+// Policy — ограничение на число попыток в окне. При превышении ключ
+// блокируется на BaseLockout, каждая следующая блокировка вдвое дольше, но не дольше MaxLockout.
+type Policy struct {
+	Name        string // префикс ключа
+	Limit       int
+	Window      time.Duration
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+}
+
+// Key формирует ключ счётчика политики для субъекта: IP, email, ID пользователя.
+func (p Policy) Key(subject string) string {
+	return p.Name + ":" + subject
+}
+
+// Lockout возвращает длительность блокировки с номером strike (с единицы).
+func (p Policy) Lockout(strike int) time.Duration {
+	d := p.BaseLockout
+	for i := 1; i < strike && d < p.MaxLockout; i++ {
+		d *= 2
+	}
+	if d > p.MaxLockout {
+		d = p.MaxLockout
+	}
+	return d
+}
+
+// State — счётчик попыток по ключу.
+type State struct {
+	Key         string
+	Count       int       // попыток в текущем окне
+	WindowStart time.Time // начало окна
+	Strikes     int       // сколько раз подряд ключ блокировался
+	LockedUntil time.Time
+	UpdatedAt   time.Time
+}
+
+// StrikesTTL — через сколько без попыток забываются прежние блокировки.
+const StrikesTTL = 24 * time.Hour
+
+// Политики, которыми защищены вход, регистрация и подтверждение по почте.
+var (
+	// LoginIP — все попытки входа с одного IP, включая второй шаг 2FA.
+	LoginIP = Policy{Name: "login_ip", Limit: 20, Window: time.Minute, BaseLockout: time.Minute, MaxLockout: time.Hour}
+	// LoginAccount — неудачные попытки входа в один аккаунт.
+	LoginAccount = Policy{Name: "login_account", Limit: 5, Window: 15 * time.Minute, BaseLockout: time.Minute, MaxLockout: 24 * time.Hour}
+	// TwoFactorAccount — неверные коды второго фактора одного пользователя.
+	TwoFactorAccount = Policy{Name: "2fa_account", Limit: 5, Window: 15 * time.Minute, BaseLockout: time.Minute, MaxLockout: 24 * time.Hour}
+	// RegisterIP — регистрации с одного IP.
+	RegisterIP = Policy{Name: "register_ip", Limit: 5, Window: time.Hour, BaseLockout: 10 * time.Minute, MaxLockout: 24 * time.Hour}
+	// ConfirmIP — запросы и проверки кодов из писем с одного IP.
+	ConfirmIP = Policy{Name: "confirm_ip", Limit: 10, Window: 10 * time.Minute, BaseLockout: 5 * time.Minute, MaxLockout: 24 * time.Hour}
+	// RefreshIP — обновления токенов с одного IP.
+	RefreshIP = Policy{Name: "refresh_ip", Limit: 60, Window: time.Minute, BaseLockout: time.Minute, MaxLockout: time.Hour}
+)
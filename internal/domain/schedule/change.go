@@ -0,0 +1,53 @@
+package schedule
+
+import "time"
+
+// ChangeType — вид изменения записи расписания.
+type ChangeType string
+
+const (
+	// ChangeAdded — появилась новая пара.
+	ChangeAdded ChangeType = "added"
+	// ChangeUpdated — у существующей пары изменились предмет, аудитория, преподаватель или время.
+	ChangeUpdated ChangeType = "updated"
+	// ChangeRemoved — пара исчезла из расписания.
+	ChangeRemoved ChangeType = "removed"
+)
+
+// Change — запись истории изменений расписания.
+// swagger:model
+type Change struct {
+	// ID записи истории
+	// example: 12
+	ID int `json:"id"`
+
+	// ID группы
+	// example: 1
+	GroupID int `json:"group_id"`
+
+	// Дата занятия
+	// example: 2025-03-31T00:00:00Z
+	Date time.Time `json:"date"`
+
+	// Номер пары
+	// example: 3
+	PairNumber int `json:"pair_number"`
+
+	// Вид изменения: added, updated, removed
+	// example: updated
+	Type ChangeType `json:"type"`
+
+	// Состояние пары до изменения
+	Old *Schedule `json:"old,omitempty"`
+
+	// Состояние пары после изменения
+	New *Schedule `json:"new,omitempty"`
+
+	// Человекочитаемое описание
+	// example: 31.03: пара 3 (Математика): аудитория ауд. 101 → ауд. 204
+	Description string `json:"description"`
+
+	// Когда изменение зафиксировано
+	// example: 2025-03-30T18:00:00Z
+	CreatedAt time.Time `json:"created_at"`
+}
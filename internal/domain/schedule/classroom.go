@@ -0,0 +1,36 @@
+package schedule
+
+import "time"
+
+// Classroom — аудитория учебного заведения.
+// swagger:model
+type Classroom struct {
+	// ID аудитории
+	// example: 1
+	ID int `json:"id"`
+
+	// ID учебного заведения
+	// example: 1
+	InstitutionID int `json:"institution_id"`
+
+	// Название аудитории, как в расписании
+	// example: 305
+	Name string `json:"name"`
+
+	// Корпус
+	// example: Главный корпус
+	Building string `json:"building"`
+
+	// Вместимость (мест), если известна
+	// example: 30
+	Capacity *int `json:"capacity,omitempty"`
+}
+
+// FreeClassroomsFilter — условия поиска свободных аудиторий.
+type FreeClassroomsFilter struct {
+	InstitutionID int
+	Date          time.Time
+	PairNumber    int
+	Building      string
+	MinCapacity   int
+}
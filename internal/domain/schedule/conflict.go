@@ -0,0 +1,81 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConflictType — вид конфликта при ручном изменении расписания.
+type ConflictType string
+
+const (
+	// ConflictGroupBusy — у группы уже стоит пара в это время.
+	ConflictGroupBusy ConflictType = "group_busy"
+	// ConflictTeacherBusy — преподаватель в это время ведёт другую пару.
+	ConflictTeacherBusy ConflictType = "teacher_busy"
+	// ConflictClassroomBusy — аудитория в это время занята другой группой.
+	ConflictClassroomBusy ConflictType = "classroom_busy"
+	// ConflictInvalidTime — время окончания не позже времени начала.
+	ConflictInvalidTime ConflictType = "invalid_time"
+	// ConflictBellMismatch — время пары не совпадает с расписанием звонков.
+	ConflictBellMismatch ConflictType = "bell_mismatch"
+)
+
+// Severity — насколько серьёзен конфликт.
+type Severity string
+
+const (
+	// SeverityError — запись не сохраняется.
+	SeverityError Severity = "error"
+	// SeverityWarning — запись сохраняется, если клиент передал force.
+	SeverityWarning Severity = "warning"
+)
+
+// Conflict описывает одно нарушение.
+// swagger:model
+type Conflict struct {
+	// Вид конфликта
+	// example: teacher_busy
+	Type ConflictType `json:"type"`
+
+	// error — запись отклонена, warning — можно сохранить с force=true
+	// example: error
+	Severity Severity `json:"severity"`
+
+	// Описание для пользователя
+	// example: Преподаватель И.И. Иванов в это время ведёт пару у группы ИС-21
+	Message string `json:"message"`
+
+	// ID записи расписания, с которой возник конфликт
+	// example: 42
+	ScheduleID *int `json:"schedule_id,omitempty"`
+}
+
+// ConflictError возвращается сервисом, если запись нельзя сохранить.
+type ConflictError struct {
+	Conflicts []*Conflict
+}
+
+func (e *ConflictError) Error() string {
+	msgs := make([]string, 0, len(e.Conflicts))
+	for _, c := range e.Conflicts {
+		msgs = append(msgs, c.Message)
+	}
+	return fmt.Sprintf("конфликты в расписании: %s", strings.Join(msgs, "; "))
+}
+
+// Bell — время одной пары по расписанию звонков учреждения.
+// swagger:model
+type Bell struct {
+	// Номер пары
+	// example: 1
+	PairNumber int `json:"pair_number"`
+
+	// Начало пары (ЧЧ:ММ)
+	// example: 08:00
+	StartTime string `json:"start_time"`
+
+	// Окончание пары (ЧЧ:ММ)
+	// example: 09:30
+	EndTime string `json:"end_time"`
+}
@@ -49,3 +49,34 @@ type Item struct {
 	// example: 0001-01-01T09:30:00Z
 	EndTime time.Time `json:"end_time"`
 }
+
+// Period — интервал дат для выборки расписания (границы включительно).
+// Нулевое значение границы означает, что она не ограничена.
+type Period struct {
+	From time.Time
+	To   time.Time
+}
+
+// Day — расписание одного дня
+// swagger:model
+type Day struct {
+	// Дата
+	// example: 2025-03-31T00:00:00Z
+	Date time.Time `json:"date"`
+
+	// Пары этого дня в порядке номеров
+	Items []*Item `json:"items"`
+}
+
+// GroupByDay раскладывает упорядоченные по дате элементы расписания по дням.
+func GroupByDay(items []*Item) []*Day {
+	days := make([]*Day, 0)
+	for _, it := range items {
+		if n := len(days); n == 0 || !days[n-1].Date.Equal(it.Date) {
+			days = append(days, &Day{Date: it.Date})
+		}
+		last := days[len(days)-1]
+		last.Items = append(last.Items, it)
+	}
+	return days
+}
@@ -0,0 +1,22 @@
+package schedule
+
+import "errors"
+
+var (
+	// ErrInvalidTemplate — загруженный файл не соответствует шаблону расписания.
+	ErrInvalidTemplate = errors.New("файл не соответствует шаблону расписания")
+	// ErrUploadNotAllowed — для учреждения настроен источник расписания, не принимающий загрузку файлов.
+	ErrUploadNotAllowed = errors.New("учреждение не принимает загрузку расписания из файла")
+	// ErrClassroomNotFound — аудитория не найдена в справочнике учреждения.
+	ErrClassroomNotFound = errors.New("аудитория не найдена")
+	// ErrNotFound — запись расписания не найдена.
+	ErrNotFound = errors.New("запись расписания не найдена")
+	// ErrGroupNotFound — в записи указана несуществующая группа.
+	ErrGroupNotFound = errors.New("группа не найдена")
+	// ErrSlotTaken — у группы уже есть пара с этим номером в этот день.
+	ErrSlotTaken = errors.New("пара уже занята")
+	// ErrForbidden — группа относится к другому учреждению.
+	ErrForbidden = errors.New("группа относится к другому учреждению")
+	// ErrInvalidBells — расписание звонков заполнено неверно.
+	ErrInvalidBells = errors.New("неверное расписание звонков")
+)
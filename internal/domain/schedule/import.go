@@ -0,0 +1,29 @@
+package schedule
+
+import "time"
+
+// ImportRow — строка расписания, загруженная из шаблона CSV/XLSX.
+type ImportRow struct {
+	GroupName  string
+	Date       time.Time
+	StartTime  string // ЧЧ:ММ
+	EndTime    string // ЧЧ:ММ
+	Discipline string
+	Teacher    string
+	Classroom  string
+}
+
+// ImportResult — итог загрузки шаблона расписания.
+// swagger:model
+type ImportResult struct {
+	// Количество загруженных строк
+	// example: 240
+	Rows int `json:"rows"`
+
+	// Количество групп в шаблоне
+	// example: 12
+	Groups int `json:"groups"`
+
+	// Группы, расписание которых не удалось обновить
+	FailedGroups []string `json:"failed_groups,omitempty"`
+}
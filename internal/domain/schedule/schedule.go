@@ -14,6 +14,10 @@ type Schedule struct {
 	TeacherInitialsID *int      `json:"teacher_initials_id,omitempty"`
 	StartTime         time.Time `json:"start_time"`
 	EndTime           time.Time `json:"end_time"`
+
+	// Synced — запись создана сверкой с источником; ручные записи сверка не трогает.
+	// Заполняется только при сверке.
+	Synced bool `json:"-"`
 }
 
 // TeacherInitials доменная модель записи инициалов преподавателя
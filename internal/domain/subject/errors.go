@@ -0,0 +1,19 @@
+package subject
+
+import "errors"
+
+var (
+	// ErrNotFound возвращается, если предмет не найден.
+	ErrNotFound = errors.New("предмет не найден")
+	// ErrInvalidName возвращается для пустого названия предмета.
+	ErrInvalidName = errors.New("название предмета не может быть пустым")
+	// ErrExists возвращается, если предмет с таким названием уже есть в учреждении.
+	ErrExists = errors.New("предмет с таким названием уже существует")
+	// ErrInUse возвращается при удалении предмета, который стоит в расписании или чатах.
+	ErrInUse = errors.New("предмет используется в расписании или чатах")
+	// ErrMergeInstitution возвращается при попытке объединить предметы разных учреждений.
+	ErrMergeInstitution = errors.New("объединять можно только предметы одного учреждения")
+	// ErrMergeChatConflict возвращается, если после объединения у преподавателя
+	// оказалось бы два чата одной группы по одному предмету.
+	ErrMergeChatConflict = errors.New("у преподавателя есть чаты группы по нескольким объединяемым предметам")
+)
@@ -9,4 +9,33 @@ var (
 	ErrRegistrationFailed = errors.New("ошибка регистрации пользователя")
 	// ErrInvalidCredentials – общее сообщение об ошибке аутентификации.
 	ErrInvalidCredentials = errors.New("неверные учетные данные")
+	// ErrUserNotFound возвращается, если пользователь не найден.
+	ErrUserNotFound = errors.New("пользователь не найден")
+	// ErrInvalidRole возвращается для неизвестной роли или роли, не подходящей пользователю.
+	ErrInvalidRole = errors.New("недопустимая роль")
+	// ErrSessionNotFound возвращается, если сессия не найдена или принадлежит другому пользователю.
+	ErrSessionNotFound = errors.New("сессия не найдена")
+	// ErrTokenReuse возвращается при повторном предъявлении уже обменянного refresh-токена.
+	ErrTokenReuse = errors.New("refresh-токен уже использован")
+	// ErrInvalidOTP возвращается для неверного кода 2FA или кода восстановления.
+	ErrInvalidOTP = errors.New("неверный код подтверждения")
+	// ErrChallengeInvalid возвращается, если второй шаг входа просрочен или исчерпаны попытки.
+	ErrChallengeInvalid = errors.New("время на ввод кода истекло, войдите заново")
+	// ErrTwoFactorEnabled возвращается при повторном подключении уже включённой 2FA.
+	ErrTwoFactorEnabled = errors.New("двухфакторная аутентификация уже включена")
+	// ErrTwoFactorNotEnrolled возвращается, если 2FA не подключали.
+	ErrTwoFactorNotEnrolled = errors.New("двухфакторная аутентификация не подключена")
+	// ErrTwoFactorRequired возвращается при попытке отключить 2FA, обязательную для роли.
+	ErrTwoFactorRequired = errors.New("учреждение требует двухфакторную аутентификацию для вашей роли")
+	// ErrEmailNotAllowed возвращается, если домен email преподавателя не зарегистрирован за его учреждением.
+	ErrEmailNotAllowed = errors.New("email не поддерживается учреждением")
+	// ErrSameEmail возвращается при попытке сменить email на текущий.
+	ErrSameEmail = errors.New("новый email совпадает с текущим")
+	// ErrCodeRecentlySent возвращается, если код уже отправлялся недавно.
+	ErrCodeRecentlySent = errors.New("код уже отправлялся недавно, попробуйте позже")
+	// ErrOwnsChats возвращается при удалении аккаунта преподавателя, которому принадлежат чаты:
+	// вместе с чатом стёрлась бы переписка студентов.
+	ErrOwnsChats = errors.New("аккаунт владеет чатами, удалите их перед удалением аккаунта")
+	// ErrForbidden возвращается, если у пользователя недостаточно прав для действия.
+	ErrForbidden = errors.New("недостаточно прав")
 )
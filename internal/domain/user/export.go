@@ -0,0 +1,45 @@
+package user
+
+import "time"
+
+// DeletionGracePeriod — сколько удалённый аккаунт можно восстановить входом.
+const DeletionGracePeriod = 14 * 24 * time.Hour
+
+// ExportProfile — профиль пользователя в архиве данных.
+type ExportProfile struct {
+	ID            int       `json:"id"`
+	Email         string    `json:"email"`
+	FullName      string    `json:"full_name"`
+	IsTeacher     bool      `json:"is_teacher"`
+	Role          Role      `json:"role"`
+	Locale        string    `json:"locale"`
+	InstitutionID int       `json:"institution_id,omitempty"`
+	GroupID       int       `json:"group_id,omitempty"`
+	ExportedAt    time.Time `json:"exported_at"`
+}
+
+// ExportMessage — сообщение пользователя в архиве данных.
+type ExportMessage struct {
+	ID              int       `json:"id"`
+	ChatID          int       `json:"chat_id"`
+	Text            string    `json:"text"`
+	ParentMessageID *int      `json:"parent_message_id,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	Files           []string  `json:"files,omitempty"` // пути внутри архива
+}
+
+// ExportFile — файл, загруженный пользователем.
+type ExportFile struct {
+	ID        int
+	MessageID int
+	Path      string // путь в хранилище
+}
+
+// ExportVote — голос пользователя в опросе.
+type ExportVote struct {
+	PollID   int    `json:"poll_id"`
+	ChatID   int    `json:"chat_id"`
+	Question string `json:"question"`
+	OptionID int    `json:"option_id"`
+	Option   string `json:"option"`
+}
@@ -0,0 +1,40 @@
+package user
+
+// JWK — открытый ключ подписи токенов в формате RFC 7517.
+// swagger:model
+type JWK struct {
+	// Тип ключа: RSA или OKP (Ed25519)
+	// example: OKP
+	Kty string `json:"kty"`
+
+	// Идентификатор ключа, совпадает с заголовком kid токена
+	// example: 20261018T120000-3fa9
+	Kid string `json:"kid"`
+
+	// Назначение ключа
+	// example: sig
+	Use string `json:"use"`
+
+	// Алгоритм подписи
+	// example: EdDSA
+	Alg string `json:"alg"`
+
+	// Модуль RSA-ключа (base64url)
+	N string `json:"n,omitempty"`
+
+	// Экспонента RSA-ключа (base64url)
+	E string `json:"e,omitempty"`
+
+	// Кривая OKP-ключа
+	// example: Ed25519
+	Crv string `json:"crv,omitempty"`
+
+	// Открытый OKP-ключ (base64url)
+	X string `json:"x,omitempty"`
+}
+
+// JWKS — набор открытых ключей, которыми можно проверить токены EduSync.
+// swagger:model
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
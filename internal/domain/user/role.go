@@ -0,0 +1,92 @@
+package user
+
+// Role — роль пользователя, хранится в users.role и передаётся в TokenClaims.
+type Role string
+
+const (
+	RoleStudent          Role = "student"
+	RoleTeacher          Role = "teacher"
+	RoleInstitutionAdmin Role = "institution_admin"
+	RoleSystemAdmin      Role = "system_admin"
+)
+
+// Permission — действие, доступ к которому проверяет PermissionMiddleware.
+type Permission string
+
+const (
+	// PermScheduleWrite — ручное создание, изменение и удаление пар.
+	PermScheduleWrite Permission = "schedule:write"
+	// PermScheduleSync — запуск обновления расписания из источника и загрузка шаблона.
+	PermScheduleSync Permission = "schedule:sync"
+	// PermInstitutionManage — справочники своего учреждения: звонки, аудитории, предметы.
+	PermInstitutionManage Permission = "institution:manage"
+	// PermRolesManage — назначение ролей пользователям.
+	PermRolesManage Permission = "roles:manage"
+	// PermSystemManage — операции над всеми учреждениями.
+	PermSystemManage Permission = "system:manage"
+	// PermMessagesModerate — безвозвратное удаление сообщений в чатах учреждения.
+	PermMessagesModerate Permission = "messages:moderate"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleStudent: {},
+	RoleTeacher: {PermScheduleWrite},
+	RoleInstitutionAdmin: {
+		PermScheduleWrite, PermScheduleSync, PermInstitutionManage, PermRolesManage, PermMessagesModerate,
+	},
+	RoleSystemAdmin: {
+		PermScheduleWrite, PermScheduleSync, PermInstitutionManage, PermRolesManage, PermMessagesModerate,
+		PermSystemManage,
+	},
+}
+
+// Actor — пользователь, выполняющий административное действие.
+type Actor struct {
+	ID            int
+	Role          Role
+	InstitutionID int
+}
+
+// Manages сообщает, может ли пользователь управлять справочниками учреждения:
+// системный администратор — любым, администратор учреждения — только своим.
+func (a Actor) Manages(institutionID int) bool {
+	if a.Role.Can(PermSystemManage) {
+		return true
+	}
+	return a.Role.Can(PermInstitutionManage) && a.InstitutionID == institutionID
+}
+
+// In сообщает, относится ли действие к учреждению пользователя:
+// системный администратор работает с любым учреждением, остальные — только со своим.
+func (a Actor) In(institutionID int) bool {
+	return a.Role.Can(PermSystemManage) || a.InstitutionID == institutionID
+}
+
+// Valid сообщает, известна ли роль.
+func (r Role) Valid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Can сообщает, есть ли у роли разрешение.
+func (r Role) Can(p Permission) bool {
+	for _, have := range rolePermissions[r] {
+		if have == p {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdmin сообщает, является ли роль административной.
+func (r Role) IsAdmin() bool {
+	return r == RoleInstitutionAdmin || r == RoleSystemAdmin
+}
+
+// DefaultRole — роль, которую получает пользователь при регистрации.
+func DefaultRole(isTeacher bool) Role {
+	if isTeacher {
+		return RoleTeacher
+	}
+	return RoleStudent
+}
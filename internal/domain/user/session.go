@@ -0,0 +1,31 @@
+package user
+
+import "time"
+
+// Session — активный вход пользователя с одного устройства.
+// swagger:model
+type Session struct {
+	// ID сессии
+	// example: 12
+	ID int `json:"id"`
+
+	// User-Agent устройства
+	// example: Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)
+	UserAgent string `json:"user_agent"`
+
+	// IP-адрес последнего входа или обновления токена
+	// example: 192.168.1.10
+	IPAddress string `json:"ip_address"`
+
+	// Время входа
+	CreatedAt time.Time `json:"created_at"`
+
+	// Время последнего обновления токенов
+	LastUsedAt time.Time `json:"last_used_at"`
+
+	// Когда истекает refresh-токен сессии
+	ExpiresAt time.Time `json:"expires_at"`
+
+	// Сессия, из которой выполнен запрос
+	Current bool `json:"current"`
+}
@@ -2,13 +2,23 @@ package user
 
 import "github.com/golang-jwt/jwt/v5"
 
+// TokenType отличает access-токен от refresh-токена.
+type TokenType string
+
+const (
+	TokenAccess  TokenType = "access"
+	TokenRefresh TokenType = "refresh"
+)
+
 // TokenClaims — данные, которые будут помещаться в JWT.
 type TokenClaims struct {
-	ID            int    `json:"id"`
-	IsTeacher     bool   `json:"is_teacher"`
-	Email         string `json:"email"`
-	FullName      string `json:"full_name"`
-	InstitutionId int    `json:"institution_id"`
-	GroupId       int    `json:"group_id"`
+	ID            int       `json:"id"`
+	Type          TokenType `json:"typ"`
+	IsTeacher     bool      `json:"is_teacher"`
+	Role          Role      `json:"role"`
+	Email         string    `json:"email"`
+	FullName      string    `json:"full_name"`
+	InstitutionId int       `json:"institution_id"`
+	GroupId       int       `json:"group_id"`
 	jwt.RegisteredClaims
 }
@@ -0,0 +1,57 @@
+package user
+
+import "time"
+
+// TwoFactor — настройки TOTP пользователя.
+type TwoFactor struct {
+	UserID    int
+	Secret    string // base32, как в otpauth://
+	Enabled   bool
+	LastStep  int64 // последний принятый шаг TOTP
+	EnabledAt *time.Time
+}
+
+// TwoFactorStatus — состояние 2FA для профиля.
+// swagger:model
+type TwoFactorStatus struct {
+	// Включена ли 2FA
+	// example: true
+	Enabled bool `json:"enabled"`
+
+	// Требует ли учреждение 2FA для роли пользователя
+	// example: false
+	Required bool `json:"required"`
+
+	// Сколько неиспользованных кодов восстановления осталось
+	// example: 8
+	RecoveryCodesLeft int `json:"recovery_codes_left"`
+}
+
+// TwoFactorEnrollment — данные для добавления аккаунта в приложение-аутентификатор.
+// swagger:model
+type TwoFactorEnrollment struct {
+	// Секрет в base32 для ручного ввода
+	// example: JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP
+	Secret string `json:"secret"`
+
+	// URI для QR-кода
+	// example: otpauth://totp/EduSync:user@example.com?secret=JBSWY3DPEHPK3PXP&issuer=EduSync
+	URI string `json:"otpauth_uri"`
+}
+
+// Challenge — первый шаг входа: пароль проверен, нужен код второго фактора.
+type Challenge struct {
+	Token     string
+	ExpiresAt time.Time
+	// Учреждение требует 2FA, а пользователь её ещё не подключил
+	EnrollmentRequired bool
+}
+
+// LoginResult — результат входа: либо пара токенов, либо Challenge.
+type LoginResult struct {
+	AccessToken  string
+	RefreshToken string
+	Challenge    *Challenge
+	// Коды восстановления, выданные при подключении 2FA во время входа
+	RecoveryCodes []string
+}
@@ -3,6 +3,7 @@ package user
 import (
 	"errors"
 	"strings"
+	"time"
 )
 
 // User представляет пользователя системы.
@@ -13,8 +14,17 @@ type User struct {
 	FullName     string
 	IsTeacher    bool
 	IsActive     bool
+	Role         Role
+	Locale       string     // язык писем: ru или en
+	DeletedAt    *time.Time // аккаунт удалён и будет стёрт по истечении DeletionGracePeriod
 }
 
+// Языки, на которых отправляются письма.
+const (
+	LocaleRU = "ru"
+	LocaleEN = "en"
+)
+
 // CreateUser представляет пользователя системы.
 type CreateUser struct {
 	Email         string
@@ -23,6 +33,7 @@ type CreateUser struct {
 	IsTeacher     bool
 	InstitutionID int
 	GroupID       int
+	Locale        string
 }
 
 func (c *CreateUser) EmailMask() (string, error) {
@@ -42,6 +53,7 @@ type UpdateUser struct {
 	FullName      *string
 	InstitutionID *int
 	GroupID       *int // nil для преподавателей
+	Locale        *string
 	IsTeacher     bool
 }
 
@@ -62,5 +74,7 @@ func (c *CreateUser) ConvertToUser(passwordHash []byte) *User {
 		PasswordHash: passwordHash,
 		FullName:     c.FullName,
 		IsTeacher:    c.IsTeacher,
+		Role:         DefaultRole(c.IsTeacher),
+		Locale:       c.Locale,
 	}
 }
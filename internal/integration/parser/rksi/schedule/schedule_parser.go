@@ -51,13 +51,12 @@ type Parser interface {
 }
 
 type ScheduleParser struct {
-	URL           string
-	log           *logrus.Logger
-	InstitutionID int
+	URL string
+	log *logrus.Logger
 }
 
 func NewScheduleParser(URL string, log *logrus.Logger) *ScheduleParser {
-	return &ScheduleParser{URL: URL, log: log, InstitutionID: 1}
+	return &ScheduleParser{URL: URL, log: log}
 }
 
 // parseRussianDate преобразует русскую дату в `time.Time`.
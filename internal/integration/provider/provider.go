@@ -0,0 +1,88 @@
+package provider
+
+import (
+	domainInstitution "EduSync/internal/domain/institution"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNotConfigured возвращается, если у учреждения не задан источник расписания.
+var ErrNotConfigured = errors.New("источник расписания не настроен")
+
+// Entry представляет одну пару, полученную из источника.
+type Entry struct {
+	Date       time.Time
+	StartTime  string // ЧЧ:ММ
+	EndTime    string // ЧЧ:ММ
+	Discipline string
+	Teacher    string
+	Classroom  string
+}
+
+// Provider — источник групп, преподавателей и расписания одного учебного заведения.
+type Provider interface {
+	Groups(ctx context.Context) ([]string, error)
+	Teachers(ctx context.Context) ([]string, error)
+	Schedule(ctx context.Context, group string) ([]Entry, error)
+}
+
+// Factory создает источник для учреждения по его настройкам из institutions.provider_config.
+type Factory func(institutionID int, config json.RawMessage) (Provider, error)
+
+// Registry хранит фабрики источников по их названию (institutions.schedule_provider).
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+// NewRegistry создает пустой реестр источников расписания.
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+// Register регистрирует фабрику под именем kind.
+func (r *Registry) Register(kind string, factory Factory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories[kind] = factory
+}
+
+// Kinds возвращает имена зарегистрированных источников.
+func (r *Registry) Kinds() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	kinds := make([]string, 0, len(r.factories))
+	for k := range r.factories {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// ForInstitution создает источник расписания для учреждения.
+func (r *Registry) ForInstitution(inst *domainInstitution.Institution) (Provider, error) {
+	if inst.ScheduleProvider == "" {
+		return nil, ErrNotConfigured
+	}
+	r.mu.RLock()
+	factory, ok := r.factories[inst.ScheduleProvider]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("неизвестный источник расписания %q", inst.ScheduleProvider)
+	}
+
+	cfg := inst.ProviderConfig
+	if len(cfg) == 0 {
+		cfg = json.RawMessage("{}")
+	}
+	p, err := factory(inst.ID, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка настройки источника %q: %w", inst.ScheduleProvider, err)
+	}
+	return p, nil
+}
@@ -0,0 +1,78 @@
+package rksi
+
+import (
+	"EduSync/internal/integration/parser/rksi/group"
+	"EduSync/internal/integration/parser/rksi/schedule"
+	"EduSync/internal/integration/parser/rksi/teacher"
+	"EduSync/internal/integration/provider"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Kind — имя источника в institutions.schedule_provider.
+const Kind = "rksi"
+
+// Config — настройки источника. Пустой url заменяется адресом из URL_PARSER_RKSI.
+type Config struct {
+	URL string `json:"url"`
+}
+
+type rksiProvider struct {
+	groups   *group.GroupParser
+	teachers *teacher.TeacherParser
+	schedule *schedule.ScheduleParser
+}
+
+// NewFactory возвращает фабрику источника, разбирающего HTML-страницу расписания РКСИ.
+func NewFactory(defaultURL string, log *logrus.Logger) provider.Factory {
+	return func(_ int, raw json.RawMessage) (provider.Provider, error) {
+		var cfg Config
+		if err := json.Unmarshal(raw, &cfg); err != nil {
+			return nil, fmt.Errorf("неверные настройки: %w", err)
+		}
+		if cfg.URL == "" {
+			cfg.URL = defaultURL
+		}
+		if cfg.URL == "" {
+			return nil, errors.New("не задан url страницы расписания")
+		}
+		return &rksiProvider{
+			groups:   group.NewGroupParser(cfg.URL, log),
+			teachers: teacher.NewTeacherParser(cfg.URL, log),
+			schedule: schedule.NewScheduleParser(cfg.URL, log),
+		}, nil
+	}
+}
+
+func (p *rksiProvider) Groups(ctx context.Context) ([]string, error) {
+	groups, _, err := p.groups.FetchGroups(ctx)
+	return groups, err
+}
+
+func (p *rksiProvider) Teachers(ctx context.Context) ([]string, error) {
+	teachers, _, err := p.teachers.FetchTeacher(ctx)
+	return teachers, err
+}
+
+func (p *rksiProvider) Schedule(ctx context.Context, groupName string) ([]provider.Entry, error) {
+	parsed, err := p.schedule.FetchSchedule(ctx, groupName)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]provider.Entry, 0, len(parsed))
+	for _, e := range parsed {
+		entries = append(entries, provider.Entry{
+			Date:       e.Date,
+			StartTime:  e.StartTime,
+			EndTime:    e.EndTime,
+			Discipline: e.Discipline,
+			Teacher:    e.Teacher,
+			Classroom:  e.Classroom,
+		})
+	}
+	return entries, nil
+}
@@ -0,0 +1,189 @@
+package upload
+
+import (
+	domainSchedule "EduSync/internal/domain/schedule"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Колонки шаблона. Заголовок обязателен, порядок колонок произвольный,
+// названия принимаются на русском или английском.
+const (
+	colGroup      = "group"
+	colDate       = "date"
+	colStart      = "start"
+	colEnd        = "end"
+	colDiscipline = "discipline"
+	colTeacher    = "teacher"
+	colClassroom  = "classroom"
+)
+
+var headerAliases = map[string]string{
+	"группа":        colGroup,
+	"group":         colGroup,
+	"дата":          colDate,
+	"date":          colDate,
+	"начало":        colStart,
+	"start":         colStart,
+	"конец":         colEnd,
+	"окончание":     colEnd,
+	"end":           colEnd,
+	"дисциплина":    colDiscipline,
+	"предмет":       colDiscipline,
+	"discipline":    colDiscipline,
+	"subject":       colDiscipline,
+	"преподаватель": colTeacher,
+	"teacher":       colTeacher,
+	"аудитория":     colClassroom,
+	"classroom":     colClassroom,
+}
+
+var requiredColumns = []string{colGroup, colDate, colStart, colEnd, colDiscipline}
+
+var dateLayouts = []string{"2006-01-02", "02.01.2006", "2.1.2006"}
+
+// excelEpoch — нулевая дата серийных номеров Excel (с учётом ошибки 1900 года).
+var excelEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+// ParseTemplate разбирает шаблон расписания в формате CSV или XLSX по расширению файла.
+func ParseTemplate(filename string, data []byte) ([]*domainSchedule.ImportRow, error) {
+	var (
+		records [][]string
+		err     error
+	)
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		records, err = readCSV(data)
+	case ".xlsx":
+		records, err = readXLSX(data)
+	default:
+		return nil, errors.New("поддерживаются только файлы .csv и .xlsx")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseRecords(records)
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	r := csv.NewReader(bytes.NewReader(data))
+	// Excel с русской локалью сохраняет CSV через точку с запятой
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		r.Comma = ';'
+	}
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения CSV: %w", err)
+	}
+	return records, nil
+}
+
+func parseRecords(records [][]string) ([]*domainSchedule.ImportRow, error) {
+	if len(records) == 0 {
+		return nil, errors.New("файл пуст")
+	}
+
+	index := make(map[string]int)
+	for i, name := range records[0] {
+		if col, ok := headerAliases[strings.ToLower(strings.TrimSpace(name))]; ok {
+			index[col] = i
+		}
+	}
+	for _, col := range requiredColumns {
+		if _, ok := index[col]; !ok {
+			return nil, fmt.Errorf("в заголовке нет колонки %q", col)
+		}
+	}
+
+	var rows []*domainSchedule.ImportRow
+	for n, rec := range records[1:] {
+		line := n + 2
+		cell := func(col string) string {
+			i, ok := index[col]
+			if !ok || i >= len(rec) {
+				return ""
+			}
+			return strings.TrimSpace(rec[i])
+		}
+		if isBlank(rec) {
+			continue
+		}
+
+		row := &domainSchedule.ImportRow{
+			GroupName:  cell(colGroup),
+			Discipline: cell(colDiscipline),
+			Teacher:    cell(colTeacher),
+			Classroom:  cell(colClassroom),
+		}
+		if row.GroupName == "" || row.Discipline == "" {
+			return nil, fmt.Errorf("строка %d: не заполнены группа или дисциплина", line)
+		}
+
+		var err error
+		if row.Date, err = parseDate(cell(colDate)); err != nil {
+			return nil, fmt.Errorf("строка %d: %w", line, err)
+		}
+		if row.StartTime, err = parseClock(cell(colStart)); err != nil {
+			return nil, fmt.Errorf("строка %d: %w", line, err)
+		}
+		if row.EndTime, err = parseClock(cell(colEnd)); err != nil {
+			return nil, fmt.Errorf("строка %d: %w", line, err)
+		}
+		if row.EndTime <= row.StartTime {
+			return nil, fmt.Errorf("строка %d: окончание пары раньше начала", line)
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.New("в файле нет строк расписания")
+	}
+	return rows, nil
+}
+
+func isBlank(rec []string) bool {
+	for _, v := range rec {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseDate принимает ГГГГ-ММ-ДД, ДД.ММ.ГГГГ или серийный номер даты Excel.
+func parseDate(v string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if d, err := time.Parse(layout, v); err == nil {
+			return d, nil
+		}
+	}
+	if serial, err := strconv.ParseFloat(v, 64); err == nil && serial > 0 {
+		return excelEpoch.AddDate(0, 0, int(serial)), nil
+	}
+	return time.Time{}, fmt.Errorf("неверная дата %q", v)
+}
+
+// parseClock принимает ЧЧ:ММ, ЧЧ:ММ:СС или долю суток из Excel и возвращает ЧЧ:ММ.
+func parseClock(v string) (string, error) {
+	for _, layout := range []string{"15:04", "15:04:05", "15.04"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t.Format("15:04"), nil
+		}
+	}
+	if frac, err := strconv.ParseFloat(v, 64); err == nil && frac >= 0 && frac < 1 {
+		minutes := int(math.Round(frac * 24 * 60))
+		return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60), nil
+	}
+	return "", fmt.Errorf("неверное время %q", v)
+}
@@ -0,0 +1,52 @@
+package upload
+
+import (
+	"EduSync/internal/integration/provider"
+	"EduSync/internal/repository"
+	"context"
+	"encoding/json"
+)
+
+// Kind — имя источника в institutions.schedule_provider.
+const Kind = "upload"
+
+// uploadProvider отдаёт расписание, загруженное администратором из шаблона CSV/XLSX.
+type uploadProvider struct {
+	repo          repository.ScheduleImportRepository
+	institutionID int
+}
+
+// NewFactory возвращает фабрику источника, работающего с таблицей schedule_imports.
+// Настройки источнику не нужны.
+func NewFactory(repo repository.ScheduleImportRepository) provider.Factory {
+	return func(institutionID int, _ json.RawMessage) (provider.Provider, error) {
+		return &uploadProvider{repo: repo, institutionID: institutionID}, nil
+	}
+}
+
+func (p *uploadProvider) Groups(ctx context.Context) ([]string, error) {
+	return p.repo.Groups(ctx, p.institutionID)
+}
+
+func (p *uploadProvider) Teachers(ctx context.Context) ([]string, error) {
+	return p.repo.Teachers(ctx, p.institutionID)
+}
+
+func (p *uploadProvider) Schedule(ctx context.Context, group string) ([]provider.Entry, error) {
+	rows, err := p.repo.ByGroup(ctx, p.institutionID, group)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]provider.Entry, 0, len(rows))
+	for _, r := range rows {
+		entries = append(entries, provider.Entry{
+			Date:       r.Date,
+			StartTime:  r.StartTime,
+			EndTime:    r.EndTime,
+			Discipline: r.Discipline,
+			Teacher:    r.Teacher,
+			Classroom:  r.Classroom,
+		})
+	}
+	return entries, nil
+}
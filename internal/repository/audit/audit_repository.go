@@ -0,0 +1,64 @@
+package audit
+
+import (
+	domainAudit "EduSync/internal/domain/audit"
+	"EduSync/internal/repository"
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type auditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository создает репозиторий журнала административных действий.
+func NewAuditRepository(db *sql.DB) repository.AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// Add записывает действие в журнал.
+func (r *auditRepository) Add(ctx context.Context, e *domainAudit.Entry) error {
+	details := e.Details
+	if len(details) == 0 {
+		details = []byte("{}")
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO audit_log (user_id, institution_id, entity, entity_id, action, details)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, e.UserID, e.InstitutionID, e.Entity, e.EntityID, e.Action, string(details))
+	if err != nil {
+		return fmt.Errorf("ошибка записи в журнал: %w", err)
+	}
+	return nil
+}
+
+// List возвращает записи журнала, начиная с последних.
+func (r *auditRepository) List(ctx context.Context, f domainAudit.Filter) ([]*domainAudit.Entry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, institution_id, entity, entity_id, action, details, created_at
+		FROM audit_log
+		WHERE ($1 = 0 OR institution_id = $1)
+		  AND ($2 = '' OR entity = $2)
+		  AND ($3 = 0 OR entity_id = $3)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4 OFFSET $5
+	`, f.InstitutionID, f.Entity, f.EntityID, f.Limit, f.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения журнала: %w", err)
+	}
+	defer rows.Close()
+
+	var out []*domainAudit.Entry
+	for rows.Next() {
+		e := new(domainAudit.Entry)
+		var details []byte
+		if err := rows.Scan(&e.ID, &e.UserID, &e.InstitutionID, &e.Entity, &e.EntityID,
+			&e.Action, &details, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования записи журнала: %w", err)
+		}
+		e.Details = details
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
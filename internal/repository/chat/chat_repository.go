@@ -228,3 +228,96 @@ func (r *chatRepository) IsOwner(ctx context.Context, chatID int, userID int) (b
 	}
 	return false, nil
 }
+
+// MarkRead сдвигает курсор прочтения участника до messageID (0 — до последнего сообщения чата).
+// Курсор не двигается назад. Возвращает nil, если сообщения нет в чате.
+func (r *chatRepository) MarkRead(ctx context.Context, chatID, userID, messageID int) (*domainChat.ReadReceipt, error) {
+	rr := &domainChat.ReadReceipt{ChatID: chatID, UserID: userID}
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO chat_reads (chat_id, user_id, last_read_message_id, read_at)
+		SELECT $1, $2, m.id, NOW()
+		  FROM messages m
+		 WHERE m.chat_id = $1 AND ($3 = 0 OR m.id = $3)
+		 ORDER BY m.id DESC
+		 LIMIT 1
+		ON CONFLICT (chat_id, user_id) DO UPDATE
+		   SET last_read_message_id = GREATEST(chat_reads.last_read_message_id, EXCLUDED.last_read_message_id),
+		       read_at = EXCLUDED.read_at
+		RETURNING last_read_message_id, read_at
+	`, chatID, userID, messageID).Scan(&rr.MessageID, &rr.ReadAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сохранения прочтения: %w", err)
+	}
+	return rr, nil
+}
+
+// LastReadMessageID возвращает последнее прочитанное пользователем сообщение или 0.
+func (r *chatRepository) LastReadMessageID(ctx context.Context, chatID, userID int) (int, error) {
+	var id int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT last_read_message_id FROM chat_reads WHERE chat_id = $1 AND user_id = $2
+	`, chatID, userID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения курсора прочтения: %w", err)
+	}
+	return id, nil
+}
+
+// UnreadCount считает чужие сообщения после курсора прочтения пользователя.
+func (r *chatRepository) UnreadCount(ctx context.Context, chatID, userID int) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		  FROM messages m
+		 WHERE m.chat_id = $1
+		   AND m.user_id <> $2
+		   AND m.deleted_at IS NULL
+		   AND m.id > COALESCE(
+		         (SELECT last_read_message_id FROM chat_reads WHERE chat_id = $1 AND user_id = $2), 0)
+	`, chatID, userID).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка подсчёта непрочитанных: %w", err)
+	}
+	return n, nil
+}
+
+// ReadCount считает участников, кроме автора, прочитавших сообщение messageID.
+func (r *chatRepository) ReadCount(ctx context.Context, chatID, messageID int) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		  FROM chat_reads cr
+		  JOIN messages m ON m.id = $2
+		 WHERE cr.chat_id = $1
+		   AND cr.last_read_message_id >= $2
+		   AND cr.user_id <> m.user_id
+	`, chatID, messageID).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка подсчёта прочитавших: %w", err)
+	}
+	return n, nil
+}
+
+// InstitutionID возвращает учебное заведение, которому принадлежит группа чата, или 0.
+func (r *chatRepository) InstitutionID(ctx context.Context, chatID int) (int, error) {
+	var id int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT g.institution_id
+		  FROM chats c
+		  JOIN groups g ON g.id = c.group_id
+		 WHERE c.id = $1
+	`, chatID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения учреждения чата: %w", err)
+	}
+	return id, nil
+}
@@ -8,8 +8,18 @@ import (
 	"time"
 
 	domainChat "EduSync/internal/domain/chat"
+	"github.com/lib/pq"
 )
 
+// previewLength — сколько символов текста родителя показывать в ответе.
+const previewLength = 200
+
+// messageColumns — поля сообщения в порядке scanMessage.
+const messageColumns = `id, chat_id, user_id, text, message_group_id, parent_message_id, created_at, edited_at, deleted_at, deleted_by, version, kind, pinned_at, pinned_by`
+
+// bumpVersion выдаёт сообщению новую версию. Выполняется только после lockVersionsTx.
+const bumpVersion = `version = nextval('messages_version_seq')`
+
 type messageRepository struct {
 	db *sql.DB
 }
@@ -18,53 +28,318 @@ func NewMessageRepository(db *sql.DB) repository.MessageRepository {
 	return &messageRepository{db: db}
 }
 
-func (r *messageRepository) CreateMessage(ctx context.Context, msg *domainChat.Message) (int, error) {
-	var id int
-	err := r.db.QueryRowContext(ctx, `
-		INSERT INTO messages (chat_id, user_id, text, message_group_id, parent_message_id, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id
-	`, msg.ChatID, msg.UserID, msg.Text, msg.MessageGroupID, msg.ParentMessageID, time.Now()).Scan(&id)
-	if err != nil {
-		return 0, fmt.Errorf("ошибка создания сообщения: %w", err)
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanMessage(row rowScanner) (*domainChat.Message, error) {
+	msg := new(domainChat.Message)
+	err := row.Scan(&msg.ID, &msg.ChatID, &msg.UserID, &msg.Text, &msg.MessageGroupID, &msg.ParentMessageID,
+		&msg.CreatedAt, &msg.EditedAt, &msg.DeletedAt, &msg.DeletedBy, &msg.Version, &msg.Kind, &msg.PinnedAt, &msg.PinnedBy)
+	return msg, err
+}
+
+func scanMessages(rows *sql.Rows) ([]*domainChat.Message, error) {
+	defer rows.Close()
+	var messages []*domainChat.Message
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования сообщения: %w", err)
+		}
+		messages = append(messages, msg)
 	}
-	return id, nil
+	return messages, rows.Err()
 }
 
 func (r *messageRepository) ByID(ctx context.Context, msgID int) (*domainChat.Message, error) {
-	msg := &domainChat.Message{}
-	err := r.db.QueryRowContext(ctx, `
-		SELECT id, chat_id, user_id, text, message_group_id, parent_message_id, created_at
+	msg, err := scanMessage(r.db.QueryRowContext(ctx, `
+		SELECT `+messageColumns+`
 		FROM messages
-		WHERE id = $1`, msgID).Scan(&msg.ID, &msg.ChatID, &msg.UserID, &msg.Text, &msg.MessageGroupID, &msg.ParentMessageID, &msg.CreatedAt)
+		WHERE id = $1`, msgID))
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения сообщенияg по id: %w", err)
 	}
 	return msg, nil
 }
 
-func (r *messageRepository) Messages(ctx context.Context, chatID int, limit, offset int) ([]*domainChat.Message, error) {
-	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, chat_id, user_id, text, message_group_id, parent_message_id, created_at 
-		FROM messages 
-		WHERE chat_id = $1
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
-	`, chatID, limit, offset)
+// Messages возвращает страницу сообщений чата, новые первыми; удалённые остаются в ленте без текста.
+// Без границ — последние сообщения; с BeforeID — предыдущие, с AfterID — ближайшие более новые.
+func (r *messageRepository) Messages(ctx context.Context, chatID int, q domainChat.MessageQuery) ([]*domainChat.Message, error) {
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	if q.AfterID > 0 {
+		// Берём ближайшие к курсору сообщения и разворачиваем, чтобы порядок совпадал с остальными страницами
+		rows, err = r.db.QueryContext(ctx, `
+			SELECT * FROM (
+				SELECT `+messageColumns+`
+				FROM messages
+				WHERE chat_id = $1 AND id > $2 AND ($4 = 0 OR parent_message_id = $4)
+				ORDER BY id
+				LIMIT $3
+			) page
+			ORDER BY id DESC
+		`, chatID, q.AfterID, q.Limit, q.ParentID)
+	} else {
+		rows, err = r.db.QueryContext(ctx, `
+			SELECT `+messageColumns+`
+			FROM messages
+			WHERE chat_id = $1 AND ($2 = 0 OR id < $2) AND ($4 = 0 OR parent_message_id = $4)
+			ORDER BY id DESC
+			LIMIT $3
+		`, chatID, q.BeforeID, q.Limit, q.ParentID)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения сообщений: %w", err)
 	}
+	return scanMessages(rows)
+}
+
+// Changes возвращает сообщения чата, созданные, изменённые или удалённые после версии since,
+// в порядке версий. Версии чата фиксируются по порядку (см. lockVersionsTx), поэтому
+// меньшая версия не может появиться после того, как клиент получил большую.
+func (r *messageRepository) Changes(ctx context.Context, chatID int, since int64, limit int) ([]*domainChat.Message, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+messageColumns+`
+		FROM messages
+		WHERE chat_id = $1 AND version > $2
+		ORDER BY version
+		LIMIT $3
+	`, chatID, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения изменений: %w", err)
+	}
+	return scanMessages(rows)
+}
+
+// LastMessage возвращает последнее неудалённое сообщение чата или nil, если сообщений нет.
+func (r *messageRepository) LastMessage(ctx context.Context, chatID int) (*domainChat.Message, error) {
+	msg, err := scanMessage(r.db.QueryRowContext(ctx, `
+		SELECT `+messageColumns+`
+		FROM messages
+		WHERE chat_id = $1 AND deleted_at IS NULL
+		ORDER BY id DESC
+		LIMIT 1`, chatID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения последнего сообщения: %w", err)
+	}
+	return msg, nil
+}
+
+// Mentions возвращает страницу неудалённых сообщений, где упомянут userID, новые первыми.
+// Учитываются только чаты, в которых пользователь сейчас участник или владелец.
+func (r *messageRepository) Mentions(ctx context.Context, userID int, q domainChat.MessageQuery) ([]*domainChat.Message, error) {
+	const mentioned = `
+		id IN (SELECT message_id FROM message_mentions WHERE user_id = $1)
+		AND deleted_at IS NULL
+		AND chat_id IN (
+			SELECT id FROM chats WHERE owner_id = $1
+			UNION
+			SELECT chat_id FROM student_chats WHERE student_id = $1
+		)`
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	if q.AfterID > 0 {
+		rows, err = r.db.QueryContext(ctx, `
+			SELECT * FROM (
+				SELECT `+messageColumns+`
+				FROM messages
+				WHERE `+mentioned+` AND id > $2
+				ORDER BY id
+				LIMIT $3
+			) page
+			ORDER BY id DESC
+		`, userID, q.AfterID, q.Limit)
+	} else {
+		rows, err = r.db.QueryContext(ctx, `
+			SELECT `+messageColumns+`
+			FROM messages
+			WHERE `+mentioned+` AND ($2 = 0 OR id < $2)
+			ORDER BY id DESC
+			LIMIT $3
+		`, userID, q.BeforeID, q.Limit)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения упоминаний: %w", err)
+	}
+	return scanMessages(rows)
+}
+
+// Pins возвращает закреплённые сообщения чата, последние закреплённые первыми.
+func (r *messageRepository) Pins(ctx context.Context, chatID int) ([]*domainChat.Message, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+messageColumns+`
+		FROM messages
+		WHERE chat_id = $1 AND pinned_at IS NOT NULL
+		ORDER BY pinned_at DESC, id DESC
+	`, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения закреплённых сообщений: %w", err)
+	}
+	return scanMessages(rows)
+}
+
+// Pin закрепляет неудалённое сообщение и поднимает его версию;
+// false — сообщение уже закреплено или удалено.
+func (r *messageRepository) Pin(ctx context.Context, messageID, userID int) (bool, error) {
+	changed, err := r.versioned(ctx, messageID, `
+		UPDATE messages
+		   SET pinned_at = NOW(), pinned_by = $2, `+bumpVersion+`
+		 WHERE id = $1 AND deleted_at IS NULL AND pinned_at IS NULL
+	`, messageID, userID)
+	if err != nil {
+		return false, fmt.Errorf("ошибка закрепления сообщения: %w", err)
+	}
+	return changed, nil
+}
+
+// Unpin снимает закрепление и поднимает версию сообщения; false — сообщение не было закреплено.
+func (r *messageRepository) Unpin(ctx context.Context, messageID int) (bool, error) {
+	changed, err := r.versioned(ctx, messageID, `
+		UPDATE messages
+		   SET pinned_at = NULL, pinned_by = NULL, `+bumpVersion+`
+		 WHERE id = $1 AND pinned_at IS NOT NULL
+	`, messageID)
+	if err != nil {
+		return false, fmt.Errorf("ошибка открепления сообщения: %w", err)
+	}
+	return changed, nil
+}
+
+// versioned выполняет изменение сообщения messageID с новой версией в отдельной транзакции.
+func (r *messageRepository) versioned(ctx context.Context, messageID int, query string, args ...interface{}) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	if err := lockVersionsTx(ctx, tx, messageID); err != nil {
+		return false, err
+	}
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, tx.Commit()
+}
+
+// lockVersionsTx берёт блокировку версий чата сообщения messageID до конца транзакции tx.
+// Версия выдаётся при выполнении запроса, а не при фиксации, поэтому без блокировки
+// транзакция с версией 10 могла бы зафиксироваться позже транзакции с версией 11,
+// и клиент, уже получивший 11, пропустил бы 10. Под блокировкой версии одного чата
+// фиксируются строго по порядку. Блокировку нужно брать до изменения строк messages,
+// чтобы транзакции не ждали друг друга по кругу.
+func lockVersionsTx(ctx context.Context, tx *sql.Tx, messageID int) error {
+	_, err := tx.ExecContext(ctx, `
+        SELECT pg_advisory_xact_lock(hashtext('messages_version'), chat_id)
+        FROM messages WHERE id = $1
+    `, messageID)
+	return err
+}
+
+// ReplyStats считает неудалённые ответы на каждое из сообщений messageIDs.
+// Сообщения без ответов в результат не попадают.
+func (r *messageRepository) ReplyStats(ctx context.Context, messageIDs []int) (map[int]domainChat.ReplyStats, error) {
+	out := make(map[int]domainChat.ReplyStats)
+	if len(messageIDs) == 0 {
+		return out, nil
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT parent_message_id, COUNT(*), MAX(created_at)
+		FROM messages
+		WHERE parent_message_id = ANY($1) AND deleted_at IS NULL
+		GROUP BY parent_message_id
+	`, pq.Array(messageIDs))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подсчёта ответов: %w", err)
+	}
 	defer rows.Close()
+	for rows.Next() {
+		var id int
+		var st domainChat.ReplyStats
+		if err := rows.Scan(&id, &st.Count, &st.LastReplyAt); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования ответов: %w", err)
+		}
+		out[id] = st
+	}
+	return out, rows.Err()
+}
 
-	var messages []*domainChat.Message
+// Previews возвращает краткие представления сообщений messageIDs.
+func (r *messageRepository) Previews(ctx context.Context, messageIDs []int) (map[int]*domainChat.MessagePreview, error) {
+	out := make(map[int]*domainChat.MessagePreview)
+	if len(messageIDs) == 0 {
+		return out, nil
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, LEFT(text, $2), created_at, deleted_at
+		FROM messages
+		WHERE id = ANY($1)
+	`, pq.Array(messageIDs), previewLength)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения родительских сообщений: %w", err)
+	}
+	defer rows.Close()
 	for rows.Next() {
-		msg := new(domainChat.Message)
-		err := rows.Scan(&msg.ID, &msg.ChatID, &msg.UserID, &msg.Text, &msg.MessageGroupID, &msg.ParentMessageID, &msg.CreatedAt)
-		if err != nil {
-			return nil, fmt.Errorf("ошибка сканирования сообщения: %w", err)
+		p := new(domainChat.MessagePreview)
+		if err := rows.Scan(&p.ID, &p.UserID, &p.Text, &p.CreatedAt, &p.DeletedAt); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования родительского сообщения: %w", err)
 		}
-		messages = append(messages, msg)
+		out[p.ID] = p
+	}
+	return out, rows.Err()
+}
+
+// Reactions сводит реакции на сообщения messageIDs в порядке первого появления
+// и отмечает те, что поставил viewerID. Сообщения без реакций в результат не попадают.
+func (r *messageRepository) Reactions(ctx context.Context, messageIDs []int, viewerID int) (map[int][]domainChat.Reaction, error) {
+	out := make(map[int][]domainChat.Reaction)
+	if len(messageIDs) == 0 {
+		return out, nil
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT message_id, emoji, COUNT(*), BOOL_OR(user_id = $2)
+		FROM message_reactions
+		WHERE message_id = ANY($1)
+		GROUP BY message_id, emoji
+		ORDER BY message_id, MIN(created_at), emoji
+	`, pq.Array(messageIDs), viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения реакций: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		var re domainChat.Reaction
+		if err := rows.Scan(&id, &re.Emoji, &re.Count, &re.ReactedByMe); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования реакции: %w", err)
+		}
+		out[id] = append(out[id], re)
 	}
-	return messages, nil
+	return out, rows.Err()
+}
+
+// ReactionCount возвращает, сколько пользователей поставили emoji на сообщение.
+func (r *messageRepository) ReactionCount(ctx context.Context, messageID int, emoji string) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM message_reactions WHERE message_id = $1 AND emoji = $2
+	`, messageID, emoji).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка подсчёта реакций: %w", err)
+	}
+	return n, nil
 }
 
 func (r *messageRepository) DeleteMessage(ctx context.Context, messageID int) error {
@@ -77,31 +352,59 @@ func (r *messageRepository) DeleteMessage(ctx context.Context, messageID int) er
 	return nil
 }
 
-func (r *messageRepository) SearchMessages(ctx context.Context, chatID int, query string, limit, offset int) ([]*domainChat.Message, error) {
-	// Ищем и по тексту, и по file_url (чтобы нашли по имени файла)
+// Search ищет неудалённые сообщения в чатах пользователя по тексту (русская морфология)
+// и именам файлов; результаты упорядочены по релевантности.
+func (r *messageRepository) Search(ctx context.Context, f domainChat.SearchFilter) ([]*domainChat.SearchHit, error) {
 	rows, err := r.db.QueryContext(ctx, `
-    SELECT DISTINCT m.id, m.chat_id, m.user_id, m.text, m.message_group_id, m.parent_message_id, m.created_at
+    WITH q AS (
+        SELECT websearch_to_tsquery('russian', $2) AS text_q,
+               websearch_to_tsquery('simple', $2)  AS file_q
+    ),
+    member_chats AS (
+        SELECT id AS chat_id FROM chats WHERE owner_id = $1
+        UNION
+        SELECT chat_id FROM student_chats WHERE student_id = $1
+    )
+    SELECT m.id, m.chat_id, m.user_id, m.text, m.message_group_id, m.parent_message_id,
+           m.created_at, m.edited_at, m.deleted_at, m.deleted_by, m.version, m.kind, m.pinned_at, m.pinned_by,
+           ts_rank(m.search_vector, q.text_q) + COALESCE(files.rank, 0) AS rank,
+           ts_headline('russian', COALESCE(m.text, ''), q.text_q, $10) AS headline
       FROM messages m
-      LEFT JOIN message_files mf ON mf.message_id = m.id
-     WHERE m.chat_id = $1
-       AND (m.text ILIKE '%'||$2||'%' OR mf.file_url ILIKE '%'||$2||'%')
-     ORDER BY m.created_at
-     LIMIT $3 OFFSET $4
-  `, chatID, query, limit, offset)
+      CROSS JOIN q
+      JOIN member_chats mc ON mc.chat_id = m.chat_id
+      LEFT JOIN LATERAL (
+           SELECT MAX(ts_rank(mf.search_vector, q.file_q)) AS rank
+             FROM message_files mf
+            WHERE mf.message_id = m.id AND mf.search_vector @@ q.file_q
+      ) files ON true
+     WHERE m.deleted_at IS NULL
+       AND (m.search_vector @@ q.text_q OR files.rank IS NOT NULL)
+       AND ($3 = 0 OR m.chat_id = $3)
+       AND ($4 = 0 OR m.user_id = $4)
+       AND ($5::timestamp IS NULL OR m.created_at >= $5)
+       AND ($6::timestamp IS NULL OR m.created_at < $6)
+       AND ($7::boolean IS NULL OR EXISTS (SELECT 1 FROM message_files WHERE message_id = m.id) = $7)
+     ORDER BY rank DESC, m.id DESC
+     LIMIT $8 OFFSET $9
+  `, f.UserID, f.Query, f.ChatID, f.AuthorID, f.From, f.To, f.HasFiles, f.Limit, f.Offset,
+		`StartSel="`+domainChat.HighlightStart+`", StopSel="`+domainChat.HighlightStop+`", MaxFragments=3, MaxWords=25, MinWords=8`)
 	if err != nil {
-		return nil, fmt.Errorf("SearchMessages query: %w", err)
+		return nil, fmt.Errorf("ошибка поиска сообщений: %w", err)
 	}
 	defer rows.Close()
 
-	var msgs []*domainChat.Message
+	hits := []*domainChat.SearchHit{}
 	for rows.Next() {
-		m := &domainChat.Message{}
-		if err := rows.Scan(&m.ID, &m.ChatID, &m.UserID, &m.Text, &m.MessageGroupID, &m.ParentMessageID, &m.CreatedAt); err != nil {
-			return nil, fmt.Errorf("SearchMessages scan: %w", err)
+		msg := new(domainChat.Message)
+		hit := &domainChat.SearchHit{Message: msg}
+		if err := rows.Scan(&msg.ID, &msg.ChatID, &msg.UserID, &msg.Text, &msg.MessageGroupID, &msg.ParentMessageID,
+			&msg.CreatedAt, &msg.EditedAt, &msg.DeletedAt, &msg.DeletedBy, &msg.Version, &msg.Kind, &msg.PinnedAt, &msg.PinnedBy,
+			&hit.Rank, &hit.Headline); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования результата поиска: %w", err)
 		}
-		msgs = append(msgs, m)
+		hits = append(hits, hit)
 	}
-	return msgs, nil
+	return hits, rows.Err()
 }
 
 func (r *messageRepository) MessageFileInfo(ctx context.Context, messageID int) ([]*domainChat.FileInfo, error) {
@@ -134,9 +437,9 @@ func (r *messageRepository) CreateMessageTx(
 	var id int
 	err := tx.QueryRowContext(ctx, `
         INSERT INTO messages
-          (chat_id, user_id, text, message_group_id, parent_message_id, created_at)
-        VALUES ($1,$2,$3,$4,$5,$6) RETURNING id
-    `, msg.ChatID, msg.UserID, msg.Text, msg.MessageGroupID, msg.ParentMessageID, time.Now()).
+          (chat_id, user_id, text, message_group_id, parent_message_id, created_at, kind)
+        VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING id
+    `, msg.ChatID, msg.UserID, msg.Text, msg.MessageGroupID, msg.ParentMessageID, time.Now(), msg.Kind).
 		Scan(&id)
 	if err != nil {
 		return 0, err
@@ -164,13 +467,139 @@ func (r *messageRepository) DeleteMessageFilesTx(ctx context.Context, tx *sql.Tx
 func (r *messageRepository) UpdateMessageTx(
 	ctx context.Context, tx *sql.Tx, messageID int, newText string,
 ) error {
+	if err := lockVersionsTx(ctx, tx, messageID); err != nil {
+		return err
+	}
 	_, err := tx.ExecContext(ctx, `
-        UPDATE messages SET text = $1 WHERE id = $2
+        UPDATE messages
+           SET text = $1, edited_at = NOW(), `+bumpVersion+`
+         WHERE id = $2
     `, newText, messageID)
 	return err
 }
 
-func (r *messageRepository) DeleteMessageTx(ctx context.Context, tx *sql.Tx, messageID int) error {
-	_, err := tx.ExecContext(ctx, `DELETE FROM messages WHERE id = $1`, messageID)
+// SoftDeleteMessageTx стирает текст сообщения, снимает закрепление и помечает его удалённым;
+// строка остаётся надгробием, чтобы не терялись ответы и синхронизация сообщила об удалении.
+func (r *messageRepository) SoftDeleteMessageTx(ctx context.Context, tx *sql.Tx, messageID, deletedBy int) error {
+	if err := lockVersionsTx(ctx, tx, messageID); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `
+        UPDATE messages
+           SET text = NULL, deleted_at = NOW(), deleted_by = $2, pinned_at = NULL, pinned_by = NULL,
+               `+bumpVersion+`
+         WHERE id = $1 AND deleted_at IS NULL
+    `, messageID, deletedBy)
+	return err
+}
+
+// AddRevisionTx сохраняет прежний текст сообщения.
+func (r *messageRepository) AddRevisionTx(ctx context.Context, tx *sql.Tx, rev *domainChat.MessageRevision) error {
+	return tx.QueryRowContext(ctx, `
+        INSERT INTO message_revisions (message_id, text, action, user_id)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, created_at
+    `, rev.MessageID, rev.Text, rev.Action, rev.UserID).Scan(&rev.ID, &rev.CreatedAt)
+}
+
+// Revisions возвращает историю сообщения от старых версий к новым.
+func (r *messageRepository) Revisions(ctx context.Context, messageID int) ([]*domainChat.MessageRevision, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT id, message_id, text, action, user_id, created_at
+          FROM message_revisions
+         WHERE message_id = $1
+         ORDER BY id
+    `, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения истории сообщения: %w", err)
+	}
+	defer rows.Close()
+
+	out := []*domainChat.MessageRevision{}
+	for rows.Next() {
+		rev := new(domainChat.MessageRevision)
+		if err := rows.Scan(&rev.ID, &rev.MessageID, &rev.Text, &rev.Action, &rev.UserID, &rev.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования версии сообщения: %w", err)
+		}
+		out = append(out, rev)
+	}
+	return out, rows.Err()
+}
+
+// PurgeMessageTx стирает содержимое сообщения вместе с файлами, историей, реакциями
+// и упоминаниями. Строка остаётся надгробием без содержимого с новой версией,
+// чтобы синхронизация сообщила клиентам об удалении. Возвращает время удаления и версию.
+func (r *messageRepository) PurgeMessageTx(ctx context.Context, tx *sql.Tx, messageID, deletedBy int) (time.Time, int64, error) {
+	if err := lockVersionsTx(ctx, tx, messageID); err != nil {
+		return time.Time{}, 0, err
+	}
+	for _, table := range []string{"message_files", "message_revisions", "message_reactions", "message_mentions"} {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE message_id = $1`, messageID); err != nil {
+			return time.Time{}, 0, err
+		}
+	}
+	var (
+		deletedAt time.Time
+		version   int64
+	)
+	err := tx.QueryRowContext(ctx, `
+        UPDATE messages
+           SET text = NULL, deleted_at = COALESCE(deleted_at, NOW()), deleted_by = $2,
+               pinned_at = NULL, pinned_by = NULL, `+bumpVersion+`
+         WHERE id = $1
+        RETURNING deleted_at, version
+    `, messageID, deletedBy).Scan(&deletedAt, &version)
+	return deletedAt, version, err
+}
+
+// AddReactionTx ставит реакцию; false — пользователь уже ставил эту реакцию.
+func (r *messageRepository) AddReactionTx(ctx context.Context, tx *sql.Tx, messageID, userID int, emoji string) (bool, error) {
+	res, err := tx.ExecContext(ctx, `
+        INSERT INTO message_reactions (message_id, user_id, emoji)
+        VALUES ($1, $2, $3)
+        ON CONFLICT DO NOTHING
+    `, messageID, userID, emoji)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// RemoveReactionTx снимает реакцию; false — такой реакции не было.
+func (r *messageRepository) RemoveReactionTx(ctx context.Context, tx *sql.Tx, messageID, userID int, emoji string) (bool, error) {
+	res, err := tx.ExecContext(ctx, `
+        DELETE FROM message_reactions
+         WHERE message_id = $1 AND user_id = $2 AND emoji = $3
+    `, messageID, userID, emoji)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// TouchMessageTx поднимает версию сообщения, чтобы синхронизация вернула его заново.
+// Блокировка версий чата держится до конца транзакции tx.
+func (r *messageRepository) TouchMessageTx(ctx context.Context, tx *sql.Tx, messageID int) error {
+	if err := lockVersionsTx(ctx, tx, messageID); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `
+        UPDATE messages SET `+bumpVersion+` WHERE id = $1
+    `, messageID)
+	return err
+}
+
+// AddMentionsTx сохраняет упоминания пользователей userIDs в сообщении.
+func (r *messageRepository) AddMentionsTx(ctx context.Context, tx *sql.Tx, messageID int, userIDs []int) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO message_mentions (message_id, user_id)
+        SELECT $1, unnest($2::int[])
+        ON CONFLICT DO NOTHING
+    `, messageID, pq.Array(userIDs))
 	return err
 }
@@ -0,0 +1,94 @@
+package email
+
+import (
+	"EduSync/internal/repository"
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type emailChangeRepository struct {
+	db *sql.DB
+}
+
+// NewEmailChangeRepository создаёт репозиторий запросов на смену email.
+func NewEmailChangeRepository(db *sql.DB) repository.EmailChangeRepository {
+	return &emailChangeRepository{db: db}
+}
+
+// Create сохраняет запрос на смену email, заменяя предыдущий запрос пользователя.
+func (r *emailChangeRepository) Create(ctx context.Context, tx *sql.Tx, userID int, newEmail, code string, expiresAt time.Time) error {
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO email_changes (user_id, new_email, code, expires_at)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (user_id) DO UPDATE
+           SET new_email = EXCLUDED.new_email, code = EXCLUDED.code,
+               attempts = 0, created_at = NOW(), expires_at = EXCLUDED.expires_at
+    `, userID, newEmail, code, expiresAt)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения запроса на смену email: %w", err)
+	}
+	return nil
+}
+
+// CreatedAt возвращает время последнего запроса пользователя или нулевое время.
+func (r *emailChangeRepository) CreatedAt(ctx context.Context, userID int) (time.Time, error) {
+	var created time.Time
+	err := r.db.QueryRowContext(ctx, `
+        SELECT created_at FROM email_changes WHERE user_id = $1
+    `, userID).Scan(&created)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("ошибка получения запроса на смену email: %w", err)
+	}
+	return created, nil
+}
+
+// Verify проверяет код и возвращает подтверждённый адрес или пустую строку.
+// После maxAttempts неверных попыток код перестаёт действовать.
+func (r *emailChangeRepository) Verify(ctx context.Context, userID int, code string, maxAttempts int) (string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var newEmail, stored string
+	var expires time.Time
+	var attempts int
+	err = tx.QueryRowContext(ctx, `
+        SELECT new_email, code, expires_at, attempts
+          FROM email_changes
+         WHERE user_id = $1
+         FOR UPDATE
+    `, userID).Scan(&newEmail, &stored, &expires, &attempts)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if time.Now().After(expires) || attempts >= maxAttempts {
+		return "", nil
+	}
+	if subtle.ConstantTimeCompare([]byte(stored), []byte(code)) == 1 {
+		return newEmail, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+        UPDATE email_changes SET attempts = attempts + 1 WHERE user_id = $1
+    `, userID); err != nil {
+		return "", err
+	}
+	return "", tx.Commit()
+}
+
+// Delete удаляет запрос пользователя.
+func (r *emailChangeRepository) Delete(ctx context.Context, tx *sql.Tx, userID int) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM email_changes WHERE user_id = $1`, userID)
+	return err
+}
@@ -3,9 +3,9 @@ package email
 import (
 	"EduSync/internal/repository"
 	"context"
+	"crypto/subtle"
 	"database/sql"
 	"errors"
-	"fmt"
 	"time"
 )
 
@@ -17,40 +17,62 @@ func NewEmailConfirmationsRepository(db *sql.DB) repository.EmailConfirmationsRe
 	return &postgresEmailConfRepo{db: db}
 }
 
-func (r *postgresEmailConfRepo) Create(ctx context.Context, userID int, action, code string, expiresAt time.Time) error {
+func (r *postgresEmailConfRepo) Create(ctx context.Context, tx *sql.Tx, userID int, action, code string, expiresAt time.Time) error {
 	// upsert: удаляем старый
-	_, err := r.db.ExecContext(ctx, `
+	_, err := tx.ExecContext(ctx, `
         DELETE FROM email_confirmations
          WHERE user_id=$1 AND action=$2
     `, userID, action)
 	if err != nil {
 		return err
 	}
-	_, err = r.db.ExecContext(ctx, `
+	_, err = tx.ExecContext(ctx, `
         INSERT INTO email_confirmations (user_id, action, code, expires_at)
         VALUES ($1, $2, $3, $4)
     `, userID, action, code, expiresAt)
 	return err
 }
 
-func (r *postgresEmailConfRepo) GetValid(ctx context.Context, userID int, action, code string) (bool, error) {
+// Verify проверяет код и засчитывает неверную попытку. После maxAttempts
+// неверных попыток код перестаёт действовать даже при правильном вводе.
+func (r *postgresEmailConfRepo) Verify(ctx context.Context, userID int, action, code string, maxAttempts int) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var stored string
 	var used bool
 	var expires time.Time
-	err := r.db.QueryRowContext(ctx, `
-        SELECT used, expires_at
+	var attempts int
+	err = tx.QueryRowContext(ctx, `
+        SELECT code, used, expires_at, attempts
           FROM email_confirmations
-         WHERE user_id=$1 AND action=$2 AND code=$3
-    `, userID, action, code).Scan(&used, &expires)
+         WHERE user_id=$1 AND action=$2
+         FOR UPDATE
+    `, userID, action).Scan(&stored, &used, &expires, &attempts)
 	if err == sql.ErrNoRows {
 		return false, nil
 	}
 	if err != nil {
 		return false, err
 	}
-	if used || time.Now().After(expires) {
+	if used || time.Now().After(expires) || attempts >= maxAttempts {
 		return false, nil
 	}
-	return true, nil
+	if subtle.ConstantTimeCompare([]byte(stored), []byte(code)) == 1 {
+		return true, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+        UPDATE email_confirmations
+           SET attempts = attempts + 1
+         WHERE user_id=$1 AND action=$2
+    `, userID, action); err != nil {
+		return false, err
+	}
+	return false, tx.Commit()
 }
 
 func (r *postgresEmailConfRepo) MarkUsed(ctx context.Context, userID int, action, code string) error {
@@ -84,16 +106,3 @@ func (r *postgresEmailConfRepo) CanSendNew(ctx context.Context, userID int, acti
 	}
 	return time.Since(created) >= throttle, nil
 }
-
-func (r *postgresEmailConfRepo) GetByActionCode(ctx context.Context, action, code string) (int, error) {
-	var userID int
-	err := r.db.QueryRowContext(ctx, `
-        SELECT user_id 
-          FROM email_confirmations
-         WHERE action=$1 AND code=$2 AND used = FALSE AND expires_at > NOW()
-    `, action, code).Scan(&userID)
-	if err == sql.ErrNoRows {
-		return 0, fmt.Errorf("code not found or expired")
-	}
-	return userID, err
-}
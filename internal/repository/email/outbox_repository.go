@@ -0,0 +1,125 @@
+package email
+
+import (
+	domainEmail "EduSync/internal/domain/email"
+	"EduSync/internal/repository"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type outboxRepository struct {
+	db *sql.DB
+}
+
+// NewOutboxRepository создаёт репозиторий очереди исходящих писем.
+func NewOutboxRepository(db *sql.DB) repository.EmailOutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+const outboxColumns = `id, user_id, institution_id, template, recipient, subject, body_text, body_html,
+	status, attempts, next_attempt_at, COALESCE(last_error, ''), created_at, sent_at`
+
+// Add ставит письмо в очередь в транзакции tx, чтобы оно ушло только если изменение сохранено.
+func (r *outboxRepository) Add(ctx context.Context, tx *sql.Tx, m *domainEmail.OutboxMessage) (int, error) {
+	var id int
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO email_outbox (user_id, institution_id, template, recipient, subject, body_text, body_html)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`, m.UserID, m.InstitutionID, m.Template, m.To, m.Subject, m.Text, m.HTML).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка добавления письма в очередь: %w", err)
+	}
+	return id, nil
+}
+
+// Claim забирает до limit писем, которым пора отправляться, и засчитывает им попытку.
+// Следующая попытка откладывается на lease, так что письмо, которое обработчик
+// не успел отметить (например, сервер упал), будет отправлено повторно.
+// SKIP LOCKED позволяет нескольким экземплярам разбирать очередь параллельно.
+func (r *outboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]*domainEmail.OutboxMessage, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE email_outbox
+		   SET attempts = attempts + 1,
+		       next_attempt_at = NOW() + make_interval(secs => $2)
+		 WHERE id IN (
+		       SELECT id FROM email_outbox
+		        WHERE status = 'pending' AND next_attempt_at <= NOW()
+		        ORDER BY next_attempt_at
+		        LIMIT $1
+		          FOR UPDATE SKIP LOCKED)
+		RETURNING `+outboxColumns, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выборки писем из очереди: %w", err)
+	}
+	return scanOutbox(rows)
+}
+
+// MarkSent отмечает письмо доставленным.
+func (r *outboxRepository) MarkSent(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE email_outbox
+		   SET status = 'sent', sent_at = NOW(), last_error = NULL
+		 WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления письма: %w", err)
+	}
+	return nil
+}
+
+// MarkRetry откладывает следующую попытку до next.
+func (r *outboxRepository) MarkRetry(ctx context.Context, id int, next time.Time, lastErr string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE email_outbox
+		   SET next_attempt_at = $2, last_error = $3
+		 WHERE id = $1`, id, next, lastErr)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления письма: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed прекращает попытки отправить письмо.
+func (r *outboxRepository) MarkFailed(ctx context.Context, id int, lastErr string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE email_outbox
+		   SET status = 'failed', last_error = $2
+		 WHERE id = $1`, id, lastErr)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления письма: %w", err)
+	}
+	return nil
+}
+
+// List возвращает письма из очереди, начиная с последних.
+func (r *outboxRepository) List(ctx context.Context, f domainEmail.OutboxFilter) ([]*domainEmail.OutboxMessage, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+outboxColumns+`
+		  FROM email_outbox
+		 WHERE ($1 = 0 OR institution_id = $1)
+		   AND ($2 = '' OR status = $2)
+		 ORDER BY created_at DESC, id DESC
+		 LIMIT $3 OFFSET $4
+	`, f.InstitutionID, f.Status, f.Limit, f.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения очереди писем: %w", err)
+	}
+	return scanOutbox(rows)
+}
+
+func scanOutbox(rows *sql.Rows) ([]*domainEmail.OutboxMessage, error) {
+	defer rows.Close()
+	var out []*domainEmail.OutboxMessage
+	for rows.Next() {
+		m := new(domainEmail.OutboxMessage)
+		if err := rows.Scan(&m.ID, &m.UserID, &m.InstitutionID, &m.Template, &m.To, &m.Subject,
+			&m.Text, &m.HTML, &m.Status, &m.Attempts, &m.NextAttemptAt, &m.LastError,
+			&m.CreatedAt, &m.SentAt); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования письма: %w", err)
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
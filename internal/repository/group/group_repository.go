@@ -5,6 +5,7 @@ import (
 	"EduSync/internal/repository"
 	"context"
 	"database/sql"
+	"fmt"
 )
 
 // GroupRepository реализует интерфейс Repository для PostgreSQL.
@@ -80,15 +81,59 @@ func (r *GroupRepository) ById(ctx context.Context, id int) (*domainGroup.Group,
 	return group, nil
 }
 
-func (r *GroupRepository) ByName(ctx context.Context, name string) (*domainGroup.Group, error) {
+// ByName ищет группу по названию в учреждении: названия уникальны только внутри учреждения.
+func (r *GroupRepository) ByName(ctx context.Context, institutionID int, name string) (*domainGroup.Group, error) {
 	group := &domainGroup.Group{}
 	err := r.db.QueryRowContext(ctx, `
 		SELECT id, name, institution_id 
 		FROM groups 
-		WHERE name = $1`, name).Scan(&group.ID, &group.Name, &group.InstitutionID)
+		WHERE institution_id = $1 AND name = $2`, institutionID, name).Scan(&group.ID, &group.Name, &group.InstitutionID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("ошибка поиска группы по названию: %w", err)
 	}
 
 	return group, nil
 }
+
+// Create добавляет группу вручную, не дожидаясь её появления в источнике расписания.
+func (r *GroupRepository) Create(ctx context.Context, name string, institutionID int) (int, error) {
+	var id int
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO groups (name, institution_id)
+		VALUES ($1, $2)
+		RETURNING id`, name, institutionID).Scan(&id)
+	if repository.IsUniqueViolation(err) {
+		return 0, domainGroup.ErrExists
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка создания группы: %w", err)
+	}
+	return id, nil
+}
+
+// Rename меняет название группы.
+func (r *GroupRepository) Rename(ctx context.Context, id int, name string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE groups SET name = $1 WHERE id = $2`, name, id)
+	if repository.IsUniqueViolation(err) {
+		return domainGroup.ErrExists
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка переименования группы: %w", err)
+	}
+	return nil
+}
+
+// Delete удаляет группу, если у неё нет расписания и чатов.
+func (r *GroupRepository) Delete(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM groups WHERE id = $1`, id)
+	if repository.IsForeignKeyViolation(err) {
+		return domainGroup.ErrInUse
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка удаления группы: %w", err)
+	}
+	return nil
+}
@@ -21,8 +21,9 @@ func NewEmailMaskRepository(db *sql.DB) repository.EmailMaskRepository {
 // All возвращает все записи из таблицы institution_email_masks.
 func (r *emailMaskRepository) All(ctx context.Context) ([]*domainInstitution.EmailMask, error) {
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT institution_id, email_mask
+		SELECT id, institution_id, email_mask
 		FROM institution_email_masks
+		ORDER BY institution_id, email_mask
 	`)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения почтовых масок: %w", err)
@@ -32,22 +33,22 @@ func (r *emailMaskRepository) All(ctx context.Context) ([]*domainInstitution.Ema
 	var masks []*domainInstitution.EmailMask
 	for rows.Next() {
 		mask := new(domainInstitution.EmailMask)
-		if err := rows.Scan(&mask.InstitutionID, &mask.EmailMask); err != nil {
+		if err := rows.Scan(&mask.ID, &mask.InstitutionID, &mask.EmailMask); err != nil {
 			return nil, fmt.Errorf("ошибка сканирования почтовой маски: %w", err)
 		}
 		masks = append(masks, mask)
 	}
-	return masks, nil
+	return masks, rows.Err()
 }
 
 // ByEmailMask возвращает запись почтовой маски по значению email_mask.
 func (r *emailMaskRepository) ByEmailMask(ctx context.Context, maskValue string) (*domainInstitution.EmailMask, error) {
 	mask := new(domainInstitution.EmailMask)
 	err := r.db.QueryRowContext(ctx, `
-		SELECT institution_id, email_mask
+		SELECT id, institution_id, email_mask
 		FROM institution_email_masks
 		WHERE email_mask = $1
-	`, maskValue).Scan(&mask.InstitutionID, &mask.EmailMask)
+	`, maskValue).Scan(&mask.ID, &mask.InstitutionID, &mask.EmailMask)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -56,3 +57,48 @@ func (r *emailMaskRepository) ByEmailMask(ctx context.Context, maskValue string)
 	}
 	return mask, nil
 }
+
+// ByID возвращает почтовую маску по идентификатору.
+func (r *emailMaskRepository) ByID(ctx context.Context, id int) (*domainInstitution.EmailMask, error) {
+	mask := new(domainInstitution.EmailMask)
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, institution_id, email_mask
+		FROM institution_email_masks
+		WHERE id = $1
+	`, id).Scan(&mask.ID, &mask.InstitutionID, &mask.EmailMask)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения почтовой маски: %w", err)
+	}
+	return mask, nil
+}
+
+// Create закрепляет почтовую маску за учреждением.
+func (r *emailMaskRepository) Create(ctx context.Context, institutionID int, mask string) (int, error) {
+	var id int
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO institution_email_masks (institution_id, email_mask)
+		VALUES ($1, $2)
+		RETURNING id
+	`, institutionID, mask).Scan(&id)
+	if repository.IsUniqueViolation(err) {
+		return 0, domainInstitution.ErrMaskTaken
+	}
+	if repository.IsForeignKeyViolation(err) {
+		return 0, domainInstitution.ErrNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка создания почтовой маски: %w", err)
+	}
+	return id, nil
+}
+
+// Delete удаляет почтовую маску.
+func (r *emailMaskRepository) Delete(ctx context.Context, id int) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM institution_email_masks WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("ошибка удаления почтовой маски: %w", err)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"EduSync/internal/repository"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 )
@@ -20,8 +21,12 @@ func NewRepository(db *sql.DB) repository.InstitutionRepository {
 
 func (r *Repository) ByID(ctx context.Context, id int) (*domainInstitution.Institution, error) {
 	inst := &domainInstitution.Institution{}
-	err := r.db.QueryRowContext(ctx, "SELECT id, name FROM institutions WHERE id = $1", id).
-		Scan(&inst.ID, &inst.Name)
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, name, COALESCE(schedule_provider, ''), provider_config,
+		       COALESCE(logo_url, ''), COALESCE(brand_color, '')
+		FROM institutions
+		WHERE id = $1`, id).
+		Scan(&inst.ID, &inst.Name, &inst.ScheduleProvider, &inst.ProviderConfig, &inst.LogoURL, &inst.BrandColor)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
@@ -32,7 +37,24 @@ func (r *Repository) ByID(ctx context.Context, id int) (*domainInstitution.Insti
 }
 
 func (r *Repository) All(ctx context.Context) ([]*domainInstitution.Institution, error) {
-	rows, err := r.db.QueryContext(ctx, "SELECT id, name FROM institutions")
+	return r.list(ctx, `
+		SELECT id, name, COALESCE(schedule_provider, ''), provider_config,
+		       COALESCE(logo_url, ''), COALESCE(brand_color, '')
+		FROM institutions`)
+}
+
+// WithScheduleProvider возвращает учреждения, для которых настроен источник расписания.
+func (r *Repository) WithScheduleProvider(ctx context.Context) ([]*domainInstitution.Institution, error) {
+	return r.list(ctx, `
+		SELECT id, name, schedule_provider, provider_config,
+		       COALESCE(logo_url, ''), COALESCE(brand_color, '')
+		FROM institutions
+		WHERE schedule_provider IS NOT NULL AND schedule_provider <> ''
+		ORDER BY id`)
+}
+
+func (r *Repository) list(ctx context.Context, query string) ([]*domainInstitution.Institution, error) {
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения учреждений: %v", err)
 	}
@@ -41,10 +63,57 @@ func (r *Repository) All(ctx context.Context) ([]*domainInstitution.Institution,
 	var institutions []*domainInstitution.Institution
 	for rows.Next() {
 		inst := &domainInstitution.Institution{}
-		if err := rows.Scan(&inst.ID, &inst.Name); err != nil {
+		if err := rows.Scan(&inst.ID, &inst.Name, &inst.ScheduleProvider, &inst.ProviderConfig, &inst.LogoURL, &inst.BrandColor); err != nil {
 			return nil, fmt.Errorf("ошибка сканирования учреждения: %v", err)
 		}
 		institutions = append(institutions, inst)
 	}
-	return institutions, nil
+	return institutions, rows.Err()
+}
+
+// Create добавляет учебное заведение.
+func (r *Repository) Create(ctx context.Context, inst *domainInstitution.Institution) (int, error) {
+	var id int
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO institutions (name, schedule_provider, provider_config, logo_url, brand_color)
+		VALUES ($1, NULLIF($2, ''), $3, NULLIF($4, ''), NULLIF($5, ''))
+		RETURNING id`, inst.Name, inst.ScheduleProvider, providerConfigArg(inst.ProviderConfig),
+		inst.LogoURL, inst.BrandColor).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка создания учреждения: %v", err)
+	}
+	return id, nil
+}
+
+// Update сохраняет название, источник расписания и оформление учреждения.
+func (r *Repository) Update(ctx context.Context, inst *domainInstitution.Institution) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE institutions
+		SET name = $1, schedule_provider = NULLIF($2, ''), provider_config = $3,
+		    logo_url = NULLIF($4, ''), brand_color = NULLIF($5, '')
+		WHERE id = $6`, inst.Name, inst.ScheduleProvider, providerConfigArg(inst.ProviderConfig),
+		inst.LogoURL, inst.BrandColor, inst.ID)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления учреждения: %v", err)
+	}
+	return nil
+}
+
+// Delete удаляет учебное заведение, если на него не ссылаются пользователи и группы.
+func (r *Repository) Delete(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM institutions WHERE id = $1`, id)
+	if repository.IsForeignKeyViolation(err) {
+		return domainInstitution.ErrInUse
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка удаления учреждения: %v", err)
+	}
+	return nil
+}
+
+func providerConfigArg(cfg json.RawMessage) string {
+	if len(cfg) == 0 {
+		return "{}"
+	}
+	return string(cfg)
 }
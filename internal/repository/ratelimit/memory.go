@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	domainRateLimit "EduSync/internal/domain/ratelimit"
+	"EduSync/internal/repository"
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryRepository хранит счётчики в памяти процесса. Подходит для одного
+// экземпляра сервера; при нескольких экземплярах используйте Postgres.
+type memoryRepository struct {
+	mu     sync.Mutex
+	states map[string]domainRateLimit.State
+}
+
+func NewMemoryRepository() repository.RateLimitRepository {
+	return &memoryRepository{states: make(map[string]domainRateLimit.State)}
+}
+
+func (r *memoryRepository) Get(ctx context.Context, key string) (*domainRateLimit.State, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.states[key]
+	if !ok {
+		return nil, nil
+	}
+	return &s, nil
+}
+
+func (r *memoryRepository) Update(ctx context.Context, key string, fn func(s *domainRateLimit.State)) (*domainRateLimit.State, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.states[key]
+	if !ok {
+		s = domainRateLimit.State{Key: key}
+	}
+	fn(&s)
+	s.UpdatedAt = time.Now()
+	r.states[key] = s
+	return &s, nil
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.states, key)
+	return nil
+}
+
+func (r *memoryRepository) DeleteStale(ctx context.Context, before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	n := 0
+	for key, s := range r.states {
+		if s.UpdatedAt.Before(before) && !s.LockedUntil.After(now) {
+			delete(r.states, key)
+			n++
+		}
+	}
+	return n, nil
+}
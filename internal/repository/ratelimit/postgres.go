@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	domainRateLimit "EduSync/internal/domain/ratelimit"
+	"EduSync/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// postgresRepository хранит счётчики в таблице rate_limits, чтобы
+// ограничения были общими для всех экземпляров сервера.
+type postgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(db *sql.DB) repository.RateLimitRepository {
+	return &postgresRepository{db: db}
+}
+
+func (r *postgresRepository) Get(ctx context.Context, key string) (*domainRateLimit.State, error) {
+	s, err := scanState(r.db.QueryRowContext(ctx, `
+		SELECT key, count, window_start, strikes, locked_until, updated_at
+		FROM rate_limits
+		WHERE key = $1
+	`, key))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения счётчика попыток: %w", err)
+	}
+	return s, nil
+}
+
+func (r *postgresRepository) Update(ctx context.Context, key string, fn func(s *domainRateLimit.State)) (*domainRateLimit.State, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Строка создаётся заранее, чтобы параллельные запросы ждали друг друга на FOR UPDATE
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO rate_limits (key) VALUES ($1) ON CONFLICT (key) DO NOTHING
+	`, key); err != nil {
+		return nil, fmt.Errorf("ошибка создания счётчика попыток: %w", err)
+	}
+	s, err := scanState(tx.QueryRowContext(ctx, `
+		SELECT key, count, window_start, strikes, locked_until, updated_at
+		FROM rate_limits
+		WHERE key = $1
+		FOR UPDATE
+	`, key))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения счётчика попыток: %w", err)
+	}
+
+	fn(s)
+	s.UpdatedAt = time.Now()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE rate_limits
+		SET count = $2, window_start = $3, strikes = $4, locked_until = $5, updated_at = $6
+		WHERE key = $1
+	`, key, s.Count, nullTime(s.WindowStart), s.Strikes, nullTime(s.LockedUntil), s.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения счётчика попыток: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения счётчика попыток: %w", err)
+	}
+	return s, nil
+}
+
+func (r *postgresRepository) Delete(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM rate_limits WHERE key = $1`, key)
+	return err
+}
+
+func (r *postgresRepository) DeleteStale(ctx context.Context, before time.Time) (int, error) {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM rate_limits
+		WHERE updated_at < $1
+		  AND (locked_until IS NULL OR locked_until < CURRENT_TIMESTAMP)
+	`, before)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка очистки счётчиков попыток: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func scanState(row *sql.Row) (*domainRateLimit.State, error) {
+	s := &domainRateLimit.State{}
+	var windowStart, lockedUntil sql.NullTime
+	if err := row.Scan(&s.Key, &s.Count, &windowStart, &s.Strikes, &lockedUntil, &s.UpdatedAt); err != nil {
+		return nil, err
+	}
+	s.WindowStart = windowStart.Time
+	s.LockedUntil = lockedUntil.Time
+	return s, nil
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
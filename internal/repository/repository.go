@@ -1,9 +1,12 @@
 package repository
 
 import (
+	domainAudit "EduSync/internal/domain/audit"
 	domainChat "EduSync/internal/domain/chat"
+	domainEmail "EduSync/internal/domain/email"
 	domainGroup "EduSync/internal/domain/group"
 	domainInstitution "EduSync/internal/domain/institution"
+	domainRateLimit "EduSync/internal/domain/ratelimit"
 	domainSchedule "EduSync/internal/domain/schedule"
 	domainSubject "EduSync/internal/domain/subject"
 	domainUser "EduSync/internal/domain/user"
@@ -18,13 +21,26 @@ type UserRepository interface {
 	Create(ctx context.Context, tx *sql.Tx, user *domainUser.User) (int, error)
 	ByEmail(ctx context.Context, email string) (*domainUser.User, error)
 	ByID(ctx context.Context, id int) (*domainUser.User, error)
+	ByIDs(ctx context.Context, ids []int) ([]*domainUser.User, error)
 	Update(ctx context.Context, tx *sql.Tx, user *domainUser.User) error
 	Activate(ctx context.Context, userID int) error
 	UpdatePassword(ctx context.Context, userID int, hashedPassword string) error
+	UpdateEmail(ctx context.Context, tx *sql.Tx, userID int, email string) error
 	DeleteByID(ctx context.Context, tx *sql.Tx, userID int) error
+	SetRole(ctx context.Context, userID int, role domainUser.Role) error
+	SoftDelete(ctx context.Context, userID int) error
+	Restore(ctx context.Context, userID int) (bool, error)
+	DeletedBefore(ctx context.Context, before time.Time) ([]int, error)
 }
 
 // StudentRepository описывает контракт для работы со студентами.
+// UserExportRepository — данные пользователя для выгрузки архивом и окончательного удаления.
+type UserExportRepository interface {
+	Messages(ctx context.Context, userID int) ([]*domainUser.ExportMessage, error)
+	Files(ctx context.Context, userID int) ([]*domainUser.ExportFile, error)
+	Votes(ctx context.Context, userID int) ([]*domainUser.ExportVote, error)
+}
+
 type StudentRepository interface {
 	Create(ctx context.Context, tx *sql.Tx, userID, institutionID, groupID int) error
 	ByUserID(ctx context.Context, userID int) (*domainUser.Student, error)
@@ -39,6 +55,7 @@ type TeacherRepository interface {
 	ByInstitutionID(ctx context.Context, institutionID int) ([]*domainUser.Teacher, error)
 	BySurname(ctx context.Context, surname string) ([]*domainUser.User, error)
 	Update(ctx context.Context, tx *sql.Tx, userID, institutionID int) error
+	OwnedChats(ctx context.Context, userID int) (int, error)
 }
 
 // TeacherInitialsRepository описывает работу с таблицей teacher_initials.
@@ -49,12 +66,49 @@ type TeacherInitialsRepository interface {
 	GetAll(ctx context.Context, institutionID int) ([]*domainSchedule.TeacherInitials, error)
 }
 
+// RateLimitRepository хранит счётчики попыток для ограничения частоты запросов.
+type RateLimitRepository interface {
+	// Get возвращает счётчик; nil — попыток не было.
+	Get(ctx context.Context, key string) (*domainRateLimit.State, error)
+	// Update атомарно изменяет счётчик функцией fn и сохраняет его.
+	Update(ctx context.Context, key string, fn func(s *domainRateLimit.State)) (*domainRateLimit.State, error)
+	Delete(ctx context.Context, key string) error
+	// DeleteStale удаляет незаблокированные счётчики, не менявшиеся с before.
+	DeleteStale(ctx context.Context, before time.Time) (int, error)
+}
+
+// TwoFactorRepository хранит настройки TOTP, коды восстановления,
+// незавершённые входы и требования учреждений к 2FA.
+type TwoFactorRepository interface {
+	ByUserID(ctx context.Context, userID int) (*domainUser.TwoFactor, error)
+	SaveSecret(ctx context.Context, userID int, secret string) error
+	Enable(ctx context.Context, userID int, step int64, codeHashes []string) error
+	Disable(ctx context.Context, userID int) error
+	UseStep(ctx context.Context, userID int, step int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error)
+	RecoveryCodesLeft(ctx context.Context, userID int) (int, error)
+
+	CreateChallenge(ctx context.Context, tokenHash string, userID int, expiresAt time.Time) error
+	ChallengeAttempt(ctx context.Context, tokenHash string, maxAttempts int) (int, error)
+	DeleteChallenge(ctx context.Context, tokenHash string) error
+
+	RequiredRoles(ctx context.Context, institutionID int) ([]domainUser.Role, error)
+	SetRequiredRoles(ctx context.Context, institutionID int, roles []domainUser.Role) error
+}
+
 type TokenRepository interface {
 	DeleteForUser(ctx context.Context, userID int) error
 	Save(ctx context.Context, userID int, accessToken, refreshToken, userAgent, ipAddress string, expiresAt time.Time) error
 	Revoke(ctx context.Context, accessToken string) error
 	IsValid(ctx context.Context, accessToken string) (bool, error)
 	IsRefreshValid(ctx context.Context, refreshToken string) (bool, error)
+	DeleteForDevice(ctx context.Context, userID int, userAgent string) error
+	Rotate(ctx context.Context, refreshToken, accessToken, newRefreshToken, ipAddress string, expiresAt time.Time) (int, error)
+	Sessions(ctx context.Context, userID int) ([]*domainUser.Session, error)
+	SessionID(ctx context.Context, accessToken string) (int, error)
+	DeleteSession(ctx context.Context, userID, sessionID int) (bool, error)
+	DeleteOtherSessions(ctx context.Context, userID, keepSessionID int) (int, error)
 }
 
 // GroupRepository описывает контракт для работы с группами.
@@ -62,19 +116,29 @@ type GroupRepository interface {
 	Save(ctx context.Context, groups []*domainGroup.Group) error
 	ByInstitutionID(ctx context.Context, institutionID int) ([]*domainGroup.Group, error)
 	ById(ctx context.Context, groupId int) (*domainGroup.Group, error)
-	ByName(ctx context.Context, name string) (*domainGroup.Group, error)
+	ByName(ctx context.Context, institutionID int, name string) (*domainGroup.Group, error)
+	Create(ctx context.Context, name string, institutionID int) (int, error)
+	Rename(ctx context.Context, id int, name string) error
+	Delete(ctx context.Context, id int) error
 }
 
 // InstitutionRepository описывает контракт доступа к данным учебных заведений.
 type InstitutionRepository interface {
 	ByID(ctx context.Context, id int) (*domainInstitution.Institution, error)
 	All(ctx context.Context) ([]*domainInstitution.Institution, error)
+	WithScheduleProvider(ctx context.Context) ([]*domainInstitution.Institution, error)
+	Create(ctx context.Context, inst *domainInstitution.Institution) (int, error)
+	Update(ctx context.Context, inst *domainInstitution.Institution) error
+	Delete(ctx context.Context, id int) error
 }
 
 // EmailMaskRepository описывает контракт доступа к почтовым маскам.
 type EmailMaskRepository interface {
 	All(ctx context.Context) ([]*domainInstitution.EmailMask, error)
 	ByEmailMask(ctx context.Context, mask string) (*domainInstitution.EmailMask, error)
+	ByID(ctx context.Context, id int) (*domainInstitution.EmailMask, error)
+	Create(ctx context.Context, institutionID int, mask string) (int, error)
+	Delete(ctx context.Context, id int) error
 }
 
 type SubjectRepository interface {
@@ -83,17 +147,60 @@ type SubjectRepository interface {
 	ByInstitutionID(ctx context.Context, institutionID int) ([]*domainSubject.Subject, error)
 	ByGroupID(ctx context.Context, groupID int) ([]*domainSubject.Subject, error)
 	ByNameAndInstitution(ctx context.Context, discipline string, id int) (*domainSubject.Subject, error)
+	Rename(ctx context.Context, id int, name string) error
+	Delete(ctx context.Context, id int) error
+	Merge(ctx context.Context, targetID int, sourceIDs []int) error
+}
+
+// AuditRepository хранит журнал административных действий.
+type AuditRepository interface {
+	Add(ctx context.Context, e *domainAudit.Entry) error
+	List(ctx context.Context, f domainAudit.Filter) ([]*domainAudit.Entry, error)
 }
 
 // ScheduleRepository описывает контракт доступа к данным расписания.
 type ScheduleRepository interface {
-	Save(ctx context.Context, entries []*domainSchedule.Schedule) error
+	ByGroupAndDates(ctx context.Context, groupID int, dates []time.Time) ([]*domainSchedule.Schedule, error)
+	ApplyChanges(ctx context.Context, changes []*domainSchedule.Change) error
+	Changes(ctx context.Context, groupID, limit, offset int) ([]*domainSchedule.Change, error)
 	Create(ctx context.Context, s *domainSchedule.Schedule) (int, error)
-	ByGroupID(ctx context.Context, groupID int) ([]*domainSchedule.Schedule, error)
+	ByGroupID(ctx context.Context, groupID int, period domainSchedule.Period) ([]*domainSchedule.Schedule, error)
 	GetByID(ctx context.Context, id int) (*domainSchedule.Schedule, error)
 	Update(ctx context.Context, id int, upd map[string]interface{}) error
 	Delete(ctx context.Context, id int) error
-	ByTeacherInitialsID(ctx context.Context, initialsID int) ([]*domainSchedule.Schedule, error)
+	ByTeacherInitialsID(ctx context.Context, initialsID int, period domainSchedule.Period) ([]*domainSchedule.Schedule, error)
+	ByClassroom(ctx context.Context, institutionID int, classroom string, period domainSchedule.Period) ([]*domainSchedule.Schedule, error)
+	Overlapping(ctx context.Context, institutionID int, e *domainSchedule.Schedule, excludeID int) ([]*domainSchedule.Schedule, error)
+}
+
+// BellScheduleRepository описывает доступ к расписанию звонков.
+type BellScheduleRepository interface {
+	ByInstitutionID(ctx context.Context, institutionID int) ([]*domainSchedule.Bell, error)
+	Replace(ctx context.Context, institutionID int, bells []*domainSchedule.Bell) error
+}
+
+// ClassroomRepository описывает доступ к справочнику аудиторий.
+type ClassroomRepository interface {
+	EnsureExist(ctx context.Context, institutionID int, names []string) error
+	ByInstitutionID(ctx context.Context, institutionID int) ([]*domainSchedule.Classroom, error)
+	ByID(ctx context.Context, id int) (*domainSchedule.Classroom, error)
+	Update(ctx context.Context, c *domainSchedule.Classroom) error
+	Free(ctx context.Context, f domainSchedule.FreeClassroomsFilter) ([]*domainSchedule.Classroom, error)
+}
+
+// ScheduleImportRepository описывает доступ к расписанию, загруженному из шаблона.
+type ScheduleImportRepository interface {
+	Replace(ctx context.Context, institutionID int, rows []*domainSchedule.ImportRow) error
+	Groups(ctx context.Context, institutionID int) ([]string, error)
+	Teachers(ctx context.Context, institutionID int) ([]string, error)
+	ByGroup(ctx context.Context, institutionID int, groupName string) ([]*domainSchedule.ImportRow, error)
+}
+
+// CalendarTokenRepository описывает доступ к секретным токенам подписки на календарь.
+type CalendarTokenRepository interface {
+	ByUserID(ctx context.Context, userID int) (string, error)
+	Save(ctx context.Context, userID int, token string) error
+	UserIDByToken(ctx context.Context, token string) (int, error)
 }
 
 // ChatRepository описывает операции для работы с чатами.
@@ -109,24 +216,45 @@ type ChatRepository interface {
 	RemoveParticipant(ctx context.Context, chatID int, userID int) error
 	JoinChat(ctx context.Context, chatID, userID int) error
 	LeaveChat(ctx context.Context, chatID int, userID int) error
+	MarkRead(ctx context.Context, chatID, userID, messageID int) (*domainChat.ReadReceipt, error)
+	LastReadMessageID(ctx context.Context, chatID, userID int) (int, error)
+	UnreadCount(ctx context.Context, chatID, userID int) (int, error)
+	ReadCount(ctx context.Context, chatID, messageID int) (int, error)
 	IsParticipant(ctx context.Context, chatID int, userID int) (bool, error)
 	IsOwner(ctx context.Context, chatID int, userID int) (bool, error)
+	InstitutionID(ctx context.Context, chatID int) (int, error)
 }
 
 type MessageRepository interface {
 	ByID(ctx context.Context, msgID int) (*domainChat.Message, error)
-	CreateMessage(ctx context.Context, msg *domainChat.Message) (int, error)
 	UpdateMessageTx(ctx context.Context, tx *sql.Tx, messageID int, newText string) error
-	Messages(ctx context.Context, chatID int, limit, offset int) ([]*domainChat.Message, error)
+	Messages(ctx context.Context, chatID int, q domainChat.MessageQuery) ([]*domainChat.Message, error)
+	Changes(ctx context.Context, chatID int, since int64, limit int) ([]*domainChat.Message, error)
+	LastMessage(ctx context.Context, chatID int) (*domainChat.Message, error)
+	Mentions(ctx context.Context, userID int, q domainChat.MessageQuery) ([]*domainChat.Message, error)
+	Pins(ctx context.Context, chatID int) ([]*domainChat.Message, error)
+	Pin(ctx context.Context, messageID, userID int) (bool, error)
+	Unpin(ctx context.Context, messageID int) (bool, error)
+	ReplyStats(ctx context.Context, messageIDs []int) (map[int]domainChat.ReplyStats, error)
+	Previews(ctx context.Context, messageIDs []int) (map[int]*domainChat.MessagePreview, error)
+	Reactions(ctx context.Context, messageIDs []int, viewerID int) (map[int][]domainChat.Reaction, error)
+	ReactionCount(ctx context.Context, messageID int, emoji string) (int, error)
 	DeleteMessage(ctx context.Context, messageID int) error
-	SearchMessages(ctx context.Context, chatID int, query string, limit, offset int) ([]*domainChat.Message, error)
+	Search(ctx context.Context, f domainChat.SearchFilter) ([]*domainChat.SearchHit, error)
 	MessageFileInfo(ctx context.Context, messageID int) ([]*domainChat.FileInfo, error)
 
 	BeginTx(ctx context.Context) (*sql.Tx, error)
 	CreateMessageTx(ctx context.Context, tx *sql.Tx, msg *domainChat.Message) (int, error)
 	CreateMessageFileTx(ctx context.Context, tx *sql.Tx, messageID int, fileURL string) (int, error)
 	DeleteMessageFilesTx(ctx context.Context, tx *sql.Tx, messageID int) error
-	DeleteMessageTx(ctx context.Context, tx *sql.Tx, messageID int) error
+	SoftDeleteMessageTx(ctx context.Context, tx *sql.Tx, messageID, deletedBy int) error
+	AddRevisionTx(ctx context.Context, tx *sql.Tx, rev *domainChat.MessageRevision) error
+	Revisions(ctx context.Context, messageID int) ([]*domainChat.MessageRevision, error)
+	PurgeMessageTx(ctx context.Context, tx *sql.Tx, messageID, deletedBy int) (time.Time, int64, error)
+	AddReactionTx(ctx context.Context, tx *sql.Tx, messageID, userID int, emoji string) (bool, error)
+	RemoveReactionTx(ctx context.Context, tx *sql.Tx, messageID, userID int, emoji string) (bool, error)
+	TouchMessageTx(ctx context.Context, tx *sql.Tx, messageID int) error
+	AddMentionsTx(ctx context.Context, tx *sql.Tx, messageID int, userIDs []int) error
 }
 
 // FileRepository описывает доступ к таблице message_files.
@@ -160,9 +288,26 @@ type PollRepository interface {
 }
 
 type EmailConfirmationsRepository interface {
-	Create(ctx context.Context, userID int, action, code string, expiresAt time.Time) error
-	GetValid(ctx context.Context, userID int, action, code string) (bool, error)
+	Create(ctx context.Context, tx *sql.Tx, userID int, action, code string, expiresAt time.Time) error
+	Verify(ctx context.Context, userID int, action, code string, maxAttempts int) (bool, error)
 	MarkUsed(ctx context.Context, userID int, action, code string) error
 	CanSendNew(ctx context.Context, userID int, action string, throttle time.Duration) (bool, error)
-	GetByActionCode(ctx context.Context, action, code string) (int, error)
+}
+
+// EmailChangeRepository — запросы на смену email с кодом, отправленным на новый адрес.
+type EmailChangeRepository interface {
+	Create(ctx context.Context, tx *sql.Tx, userID int, newEmail, code string, expiresAt time.Time) error
+	CreatedAt(ctx context.Context, userID int) (time.Time, error)
+	Verify(ctx context.Context, userID int, code string, maxAttempts int) (string, error)
+	Delete(ctx context.Context, tx *sql.Tx, userID int) error
+}
+
+// EmailOutboxRepository — очередь исходящих писем.
+type EmailOutboxRepository interface {
+	Add(ctx context.Context, tx *sql.Tx, m *domainEmail.OutboxMessage) (int, error)
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*domainEmail.OutboxMessage, error)
+	MarkSent(ctx context.Context, id int) error
+	MarkRetry(ctx context.Context, id int, next time.Time, lastErr string) error
+	MarkFailed(ctx context.Context, id int, lastErr string) error
+	List(ctx context.Context, f domainEmail.OutboxFilter) ([]*domainEmail.OutboxMessage, error)
 }
@@ -0,0 +1,64 @@
+package schedule
+
+import (
+	domainSchedule "EduSync/internal/domain/schedule"
+	"EduSync/internal/repository"
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type bellScheduleRepository struct {
+	db *sql.DB
+}
+
+// NewBellScheduleRepository создает репозиторий расписания звонков.
+func NewBellScheduleRepository(db *sql.DB) repository.BellScheduleRepository {
+	return &bellScheduleRepository{db: db}
+}
+
+// ByInstitutionID возвращает расписание звонков учреждения по возрастанию номера пары.
+func (r *bellScheduleRepository) ByInstitutionID(ctx context.Context, institutionID int) ([]*domainSchedule.Bell, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT pair_number, to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI')
+		FROM bell_schedule
+		WHERE institution_id = $1
+		ORDER BY pair_number
+	`, institutionID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения расписания звонков: %w", err)
+	}
+	defer rows.Close()
+
+	var bells []*domainSchedule.Bell
+	for rows.Next() {
+		b := new(domainSchedule.Bell)
+		if err := rows.Scan(&b.PairNumber, &b.StartTime, &b.EndTime); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования звонка: %w", err)
+		}
+		bells = append(bells, b)
+	}
+	return bells, rows.Err()
+}
+
+// Replace заменяет расписание звонков учреждения целиком.
+func (r *bellScheduleRepository) Replace(ctx context.Context, institutionID int, bells []*domainSchedule.Bell) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM bell_schedule WHERE institution_id = $1`, institutionID); err != nil {
+		return fmt.Errorf("ошибка очистки расписания звонков: %w", err)
+	}
+	for _, b := range bells {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO bell_schedule (institution_id, pair_number, start_time, end_time)
+			VALUES ($1, $2, $3, $4)
+		`, institutionID, b.PairNumber, b.StartTime, b.EndTime); err != nil {
+			return fmt.Errorf("ошибка сохранения звонка: %w", err)
+		}
+	}
+	return tx.Commit()
+}
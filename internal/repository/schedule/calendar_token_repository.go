@@ -0,0 +1,62 @@
+package schedule
+
+import (
+	"EduSync/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+type calendarTokenRepository struct {
+	db *sql.DB
+}
+
+// NewCalendarTokenRepository создает репозиторий токенов подписки на календарь.
+func NewCalendarTokenRepository(db *sql.DB) repository.CalendarTokenRepository {
+	return &calendarTokenRepository{db: db}
+}
+
+// ByUserID возвращает токен пользователя или пустую строку, если токен ещё не выпускался.
+func (r *calendarTokenRepository) ByUserID(ctx context.Context, userID int) (string, error) {
+	var token string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT token FROM calendar_tokens WHERE user_id = $1
+	`, userID).Scan(&token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("ошибка получения токена календаря: %w", err)
+	}
+	return token, nil
+}
+
+// Save сохраняет токен пользователя, заменяя предыдущий.
+func (r *calendarTokenRepository) Save(ctx context.Context, userID int, token string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO calendar_tokens (user_id, token, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		  SET token = EXCLUDED.token, created_at = EXCLUDED.created_at
+	`, userID, token)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения токена календаря: %w", err)
+	}
+	return nil
+}
+
+// UserIDByToken возвращает ID владельца токена или 0, если токен не найден.
+func (r *calendarTokenRepository) UserIDByToken(ctx context.Context, token string) (int, error) {
+	var userID int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT user_id FROM calendar_tokens WHERE token = $1
+	`, token).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка проверки токена календаря: %w", err)
+	}
+	return userID, nil
+}
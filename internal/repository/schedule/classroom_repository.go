@@ -0,0 +1,143 @@
+package schedule
+
+import (
+	domainSchedule "EduSync/internal/domain/schedule"
+	"EduSync/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+type classroomRepository struct {
+	db *sql.DB
+}
+
+// NewClassroomRepository создает репозиторий справочника аудиторий.
+func NewClassroomRepository(db *sql.DB) repository.ClassroomRepository {
+	return &classroomRepository{db: db}
+}
+
+// EnsureExist добавляет в справочник аудитории, которых там ещё нет.
+func (r *classroomRepository) EnsureExist(ctx context.Context, institutionID int, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO classrooms (institution_id, name)
+		VALUES ($1, $2)
+		ON CONFLICT (institution_id, name) DO NOTHING
+	`)
+	if err != nil {
+		return fmt.Errorf("ошибка подготовки запроса: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, name := range names {
+		if _, err := stmt.ExecContext(ctx, institutionID, name); err != nil {
+			return fmt.Errorf("ошибка сохранения аудитории %q: %w", name, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// ByInstitutionID возвращает справочник аудиторий учреждения.
+func (r *classroomRepository) ByInstitutionID(ctx context.Context, institutionID int) ([]*domainSchedule.Classroom, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, institution_id, name, building, capacity
+		FROM classrooms
+		WHERE institution_id = $1
+		ORDER BY building, name
+	`, institutionID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения аудиторий: %w", err)
+	}
+	return scanClassrooms(rows)
+}
+
+// ByID возвращает аудиторию по идентификатору.
+func (r *classroomRepository) ByID(ctx context.Context, id int) (*domainSchedule.Classroom, error) {
+	c := new(domainSchedule.Classroom)
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, institution_id, name, building, capacity
+		FROM classrooms
+		WHERE id = $1
+	`, id).Scan(&c.ID, &c.InstitutionID, &c.Name, &c.Building, &c.Capacity)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения аудитории: %w", err)
+	}
+	return c, nil
+}
+
+// Update сохраняет корпус и вместимость аудитории.
+func (r *classroomRepository) Update(ctx context.Context, c *domainSchedule.Classroom) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE classrooms SET building = $1, capacity = $2 WHERE id = $3
+	`, c.Building, c.Capacity, c.ID)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления аудитории: %w", err)
+	}
+	return nil
+}
+
+// Free возвращает аудитории, не занятые на указанной паре.
+// Аудитория считается занятой, если в ней стоит пара с тем же номером или
+// пересекающаяся по времени с этой парой у других групп учреждения:
+// номера пар у групп одного дня могут расходиться.
+func (r *classroomRepository) Free(ctx context.Context, f domainSchedule.FreeClassroomsFilter) ([]*domainSchedule.Classroom, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH busy AS (
+			SELECT s.classroom, s.pair_number, s.start_time, s.end_time
+			FROM schedule s
+			JOIN groups g ON g.id = s.group_id
+			WHERE g.institution_id = $1 AND s.date = $2::date
+		),
+		slot AS (
+			SELECT MIN(start_time) AS start_time, MAX(end_time) AS end_time
+			FROM busy
+			WHERE pair_number = $3
+		)
+		SELECT c.id, c.institution_id, c.name, c.building, c.capacity
+		FROM classrooms c
+		WHERE c.institution_id = $1
+		  AND ($4 = '' OR c.building = $4)
+		  AND ($5 = 0 OR c.capacity >= $5)
+		  AND NOT EXISTS (
+			SELECT 1
+			FROM busy b, slot
+			WHERE b.classroom = c.name
+			  AND (b.pair_number = $3
+			       OR (slot.start_time IS NOT NULL
+			           AND b.start_time < slot.end_time
+			           AND b.end_time > slot.start_time))
+		  )
+		ORDER BY c.building, c.name
+	`, f.InstitutionID, dateArg(f.Date), f.PairNumber, f.Building, f.MinCapacity)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка поиска свободных аудиторий: %w", err)
+	}
+	return scanClassrooms(rows)
+}
+
+func scanClassrooms(rows *sql.Rows) ([]*domainSchedule.Classroom, error) {
+	defer rows.Close()
+
+	var out []*domainSchedule.Classroom
+	for rows.Next() {
+		c := new(domainSchedule.Classroom)
+		if err := rows.Scan(&c.ID, &c.InstitutionID, &c.Name, &c.Building, &c.Capacity); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования аудитории: %w", err)
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
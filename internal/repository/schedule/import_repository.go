@@ -0,0 +1,112 @@
+package schedule
+
+import (
+	domainSchedule "EduSync/internal/domain/schedule"
+	"EduSync/internal/repository"
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type scheduleImportRepository struct {
+	db *sql.DB
+}
+
+// NewScheduleImportRepository создает репозиторий загруженного из шаблона расписания.
+func NewScheduleImportRepository(db *sql.DB) repository.ScheduleImportRepository {
+	return &scheduleImportRepository{db: db}
+}
+
+// Replace заменяет ранее загруженное расписание учреждения новым одной транзакцией.
+func (r *scheduleImportRepository) Replace(ctx context.Context, institutionID int, rows []*domainSchedule.ImportRow) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schedule_imports WHERE institution_id = $1`, institutionID); err != nil {
+		return fmt.Errorf("ошибка очистки загруженного расписания: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO schedule_imports
+			(institution_id, group_name, date, start_time, end_time, discipline, teacher, classroom)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`)
+	if err != nil {
+		return fmt.Errorf("ошибка подготовки запроса: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx, institutionID, row.GroupName, row.Date,
+			row.StartTime, row.EndTime, row.Discipline, row.Teacher, row.Classroom); err != nil {
+			return fmt.Errorf("ошибка сохранения строки расписания: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Groups возвращает названия групп из загруженного расписания.
+func (r *scheduleImportRepository) Groups(ctx context.Context, institutionID int) ([]string, error) {
+	return r.distinct(ctx, `
+		SELECT DISTINCT group_name FROM schedule_imports
+		WHERE institution_id = $1
+		ORDER BY group_name
+	`, institutionID)
+}
+
+// Teachers возвращает инициалы преподавателей из загруженного расписания.
+func (r *scheduleImportRepository) Teachers(ctx context.Context, institutionID int) ([]string, error) {
+	return r.distinct(ctx, `
+		SELECT DISTINCT teacher FROM schedule_imports
+		WHERE institution_id = $1 AND teacher <> ''
+		ORDER BY teacher
+	`, institutionID)
+}
+
+func (r *scheduleImportRepository) distinct(ctx context.Context, query string, institutionID int) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, query, institutionID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения загруженного расписания: %w", err)
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+// ByGroup возвращает загруженное расписание группы.
+func (r *scheduleImportRepository) ByGroup(ctx context.Context, institutionID int, groupName string) ([]*domainSchedule.ImportRow, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT group_name, date, to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'),
+		       discipline, teacher, classroom
+		FROM schedule_imports
+		WHERE institution_id = $1 AND group_name = $2
+		ORDER BY date, start_time
+	`, institutionID, groupName)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения загруженного расписания группы: %w", err)
+	}
+	defer rows.Close()
+
+	var out []*domainSchedule.ImportRow
+	for rows.Next() {
+		row := new(domainSchedule.ImportRow)
+		if err := rows.Scan(&row.GroupName, &row.Date, &row.StartTime, &row.EndTime,
+			&row.Discipline, &row.Teacher, &row.Classroom); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования строки расписания: %w", err)
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
@@ -5,8 +5,12 @@ import (
 	"EduSync/internal/repository"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 // PostgresScheduleRepository реализует интерфейс Repository для расписания.
@@ -19,49 +23,186 @@ func NewScheduleRepository(db *sql.DB) repository.ScheduleRepository {
 	return &PostgresScheduleRepository{db: db}
 }
 
-// Save сохраняет записи расписания в БД.
-// Здесь можно использовать транзакцию для пакетной вставки.
-func (r *PostgresScheduleRepository) Save(ctx context.Context, entries []*domainSchedule.Schedule) error {
-	tx, err := r.db.BeginTx(ctx, nil)
+// ByGroupAndDates возвращает сохранённые записи расписания группы на указанные даты.
+func (r *PostgresScheduleRepository) ByGroupAndDates(ctx context.Context, groupID int, dates []time.Time) ([]*domainSchedule.Schedule, error) {
+	days := make([]string, len(dates))
+	for i, d := range dates {
+		days[i] = d.Format("2006-01-02")
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, group_id, subject_id, date, pair_number, classroom, teacher_initials_id, start_time, end_time, synced
+		FROM schedule
+		WHERE group_id = $1 AND date = ANY($2::date[])
+		ORDER BY date, pair_number
+	`, groupID, pq.Array(days))
 	if err != nil {
-		return fmt.Errorf("не удалось начать транзакцию: %v", err)
+		return nil, fmt.Errorf("ошибка получения расписания по датам: %w", err)
 	}
-	stmt, err := tx.PrepareContext(ctx, `
-		INSERT INTO schedule (group_id, subject_id, date, pair_number, classroom, teacher_initials_id, start_time, end_time)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT DO NOTHING
-	`)
+	defer rows.Close()
+
+	var entries []*domainSchedule.Schedule
+	for rows.Next() {
+		ent := &domainSchedule.Schedule{}
+		if err := rows.Scan(
+			&ent.ID,
+			&ent.GroupID,
+			&ent.SubjectID,
+			&ent.Date,
+			&ent.PairNumber,
+			&ent.Classroom,
+			&ent.TeacherInitialsID,
+			&ent.StartTime,
+			&ent.EndTime,
+			&ent.Synced,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования записи расписания: %w", err)
+		}
+		entries = append(entries, ent)
+	}
+	return entries, nil
+}
+
+// ApplyChanges в одной транзакции применяет изменения к таблице schedule
+// и записывает их в историю schedule_changes. Добавленные записи помечаются synced.
+// Сначала удаляются пропавшие пары, затем обновляются и добавляются остальные,
+// чтобы не упереться в уникальный индекс (group_id, date, pair_number).
+func (r *PostgresScheduleRepository) ApplyChanges(ctx context.Context, changes []*domainSchedule.Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		tx.Rollback()
-		return fmt.Errorf("не удалось подготовить запрос: %v", err)
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
 	}
-	defer stmt.Close()
+	defer tx.Rollback()
 
-	var teacherInitID *int
-	for _, entry := range entries {
-		if entry.TeacherInitialsID != nil {
-			if *entry.TeacherInitialsID != 0 {
-				teacherInitID = entry.TeacherInitialsID
+	for _, kind := range []domainSchedule.ChangeType{
+		domainSchedule.ChangeRemoved,
+		domainSchedule.ChangeUpdated,
+		domainSchedule.ChangeAdded,
+	} {
+		for _, ch := range changes {
+			if ch.Type != kind {
+				continue
+			}
+			if err := applyChangeTx(ctx, tx, ch); err != nil {
+				return err
+			}
+			if err := insertChangeTx(ctx, tx, ch); err != nil {
+				return err
 			}
-		} else {
-			teacherInitID = nil
 		}
-		_, err := stmt.ExecContext(ctx,
-			entry.GroupID,
-			entry.SubjectID,
-			entry.Date,
-			entry.PairNumber,
-			entry.Classroom,
-			teacherInitID,
-			entry.StartTime,
-			entry.EndTime,
-		)
+	}
+
+	return tx.Commit()
+}
+
+func applyChangeTx(ctx context.Context, tx *sql.Tx, ch *domainSchedule.Change) error {
+	switch ch.Type {
+	case domainSchedule.ChangeRemoved:
+		if _, err := tx.ExecContext(ctx, `DELETE FROM schedule WHERE id = $1`, ch.Old.ID); err != nil {
+			return fmt.Errorf("ошибка удаления записи расписания %d: %w", ch.Old.ID, err)
+		}
+	case domainSchedule.ChangeUpdated:
+		_, err := tx.ExecContext(ctx, `
+			UPDATE schedule
+			   SET subject_id = $1, classroom = $2, teacher_initials_id = $3, start_time = $4, end_time = $5
+			 WHERE id = $6
+		`, ch.New.SubjectID, ch.New.Classroom, ch.New.TeacherInitialsID, ch.New.StartTime, ch.New.EndTime, ch.Old.ID)
 		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("ошибка при сохранении расписания: %v", err)
+			return fmt.Errorf("ошибка обновления записи расписания %d: %w", ch.Old.ID, err)
 		}
+		ch.New.ID = ch.Old.ID
+	case domainSchedule.ChangeAdded:
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO schedule (group_id, subject_id, date, pair_number, classroom, teacher_initials_id, start_time, end_time, synced)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, TRUE)
+			RETURNING id
+		`, ch.New.GroupID, ch.New.SubjectID, ch.New.Date, ch.New.PairNumber, ch.New.Classroom,
+			ch.New.TeacherInitialsID, ch.New.StartTime, ch.New.EndTime).Scan(&ch.New.ID)
+		if err != nil {
+			return fmt.Errorf("ошибка при сохранении расписания: %w", err)
+		}
+	default:
+		return fmt.Errorf("неизвестный тип изменения %q", ch.Type)
 	}
+	return nil
+}
 
-	return tx.Commit()
+func insertChangeTx(ctx context.Context, tx *sql.Tx, ch *domainSchedule.Change) error {
+	oldData, err := marshalNullable(ch.Old)
+	if err != nil {
+		return err
+	}
+	newData, err := marshalNullable(ch.New)
+	if err != nil {
+		return err
+	}
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO schedule_changes (group_id, date, pair_number, change_type, old_data, new_data, description)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`, ch.GroupID, ch.Date, ch.PairNumber, string(ch.Type), oldData, newData, ch.Description).
+		Scan(&ch.ID, &ch.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка записи истории расписания: %w", err)
+	}
+	return nil
+}
+
+// marshalNullable сериализует запись в JSON, nil превращается в SQL NULL.
+func marshalNullable(s *domainSchedule.Schedule) (interface{}, error) {
+	if s == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сериализации записи расписания: %w", err)
+	}
+	return data, nil
+}
+
+// Changes возвращает историю изменений расписания группы, новые записи первыми.
+func (r *PostgresScheduleRepository) Changes(ctx context.Context, groupID, limit, offset int) ([]*domainSchedule.Change, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, group_id, date, pair_number, change_type, old_data, new_data, description, created_at
+		FROM schedule_changes
+		WHERE group_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`, groupID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения истории расписания: %w", err)
+	}
+	defer rows.Close()
+
+	var out []*domainSchedule.Change
+	for rows.Next() {
+		ch := &domainSchedule.Change{}
+		var (
+			changeType       string
+			oldData, newData []byte
+		)
+		if err := rows.Scan(&ch.ID, &ch.GroupID, &ch.Date, &ch.PairNumber, &changeType,
+			&oldData, &newData, &ch.Description, &ch.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования истории расписания: %w", err)
+		}
+		ch.Type = domainSchedule.ChangeType(changeType)
+		if oldData != nil {
+			ch.Old = new(domainSchedule.Schedule)
+			if err := json.Unmarshal(oldData, ch.Old); err != nil {
+				return nil, fmt.Errorf("ошибка разбора old_data: %w", err)
+			}
+		}
+		if newData != nil {
+			ch.New = new(domainSchedule.Schedule)
+			if err := json.Unmarshal(newData, ch.New); err != nil {
+				return nil, fmt.Errorf("ошибка разбора new_data: %w", err)
+			}
+		}
+		out = append(out, ch)
+	}
+	return out, nil
 }
 
 func (r *PostgresScheduleRepository) Create(ctx context.Context, s *domainSchedule.Schedule) (int, error) {
@@ -75,20 +216,25 @@ func (r *PostgresScheduleRepository) Create(ctx context.Context, s *domainSchedu
 		s.GroupID, s.SubjectID, s.Date, s.PairNumber, s.Classroom,
 		s.TeacherInitialsID, s.StartTime, s.EndTime,
 	).Scan(&id)
+	if repository.IsUniqueViolation(err) {
+		return 0, domainSchedule.ErrSlotTaken
+	}
 	if err != nil {
 		return 0, fmt.Errorf("ошибка создания записи расписания: %w", err)
 	}
 	return id, nil
 }
 
-// ByGroupID возвращает расписание для заданной группы.
-func (r *PostgresScheduleRepository) ByGroupID(ctx context.Context, groupID int) ([]*domainSchedule.Schedule, error) {
+// ByGroupID возвращает расписание для заданной группы за указанный период.
+func (r *PostgresScheduleRepository) ByGroupID(ctx context.Context, groupID int, period domainSchedule.Period) ([]*domainSchedule.Schedule, error) {
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT id, group_id, subject_id, date, pair_number, classroom, teacher_initials_id, start_time, end_time
 		FROM schedule
 		WHERE group_id = $1
+		  AND ($2::date IS NULL OR date >= $2::date)
+		  AND ($3::date IS NULL OR date <= $3::date)
 		ORDER BY date, pair_number
-	`, groupID)
+	`, groupID, dateArg(period.From), dateArg(period.To))
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения расписания: %v", err)
 	}
@@ -141,6 +287,9 @@ func (r *PostgresScheduleRepository) Update(ctx context.Context, id int, upd map
 		i,
 	)
 	_, err := r.db.ExecContext(ctx, query, args...)
+	if repository.IsUniqueViolation(err) {
+		return domainSchedule.ErrSlotTaken
+	}
 	return err
 }
 
@@ -170,13 +319,15 @@ func (r *PostgresScheduleRepository) GetByID(ctx context.Context, id int) (*doma
 	return s, nil
 }
 
-func (r *PostgresScheduleRepository) ByTeacherInitialsID(ctx context.Context, initialsID int) ([]*domainSchedule.Schedule, error) {
+func (r *PostgresScheduleRepository) ByTeacherInitialsID(ctx context.Context, initialsID int, period domainSchedule.Period) ([]*domainSchedule.Schedule, error) {
 	rows, err := r.db.QueryContext(ctx, `
         SELECT id, group_id, subject_id, date, pair_number, classroom, teacher_initials_id, start_time, end_time
         FROM schedule
         WHERE teacher_initials_id = $1
+          AND ($2::date IS NULL OR date >= $2::date)
+          AND ($3::date IS NULL OR date <= $3::date)
         ORDER BY date, pair_number
-    `, initialsID)
+    `, initialsID, dateArg(period.From), dateArg(period.To))
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения расписания по initials_id=%d: %w", initialsID, err)
 	}
@@ -203,3 +354,84 @@ func (r *PostgresScheduleRepository) ByTeacherInitialsID(ctx context.Context, in
 	}
 	return entries, nil
 }
+
+// ByClassroom возвращает занятия в аудитории учреждения за указанный период.
+func (r *PostgresScheduleRepository) ByClassroom(ctx context.Context, institutionID int, classroom string, period domainSchedule.Period) ([]*domainSchedule.Schedule, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT s.id, s.group_id, s.subject_id, s.date, s.pair_number, s.classroom,
+		       s.teacher_initials_id, s.start_time, s.end_time
+		FROM schedule s
+		JOIN groups g ON g.id = s.group_id
+		WHERE g.institution_id = $1
+		  AND s.classroom = $2
+		  AND ($3::date IS NULL OR s.date >= $3::date)
+		  AND ($4::date IS NULL OR s.date <= $4::date)
+		ORDER BY s.date, s.start_time
+	`, institutionID, classroom, dateArg(period.From), dateArg(period.To))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения расписания аудитории %q: %w", classroom, err)
+	}
+	defer rows.Close()
+
+	var entries []*domainSchedule.Schedule
+	for rows.Next() {
+		ent := &domainSchedule.Schedule{}
+		if err := rows.Scan(
+			&ent.ID,
+			&ent.GroupID,
+			&ent.SubjectID,
+			&ent.Date,
+			&ent.PairNumber,
+			&ent.Classroom,
+			&ent.TeacherInitialsID,
+			&ent.StartTime,
+			&ent.EndTime,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования записи расписания: %w", err)
+		}
+		entries = append(entries, ent)
+	}
+	return entries, rows.Err()
+}
+
+// Overlapping возвращает записи учреждения в тот же день, которые могут конфликтовать с e:
+// пары той же группы с тем же номером и все пары, пересекающиеся с e по времени.
+// Запись excludeID (редактируемая) не учитывается.
+func (r *PostgresScheduleRepository) Overlapping(ctx context.Context, institutionID int, e *domainSchedule.Schedule, excludeID int) ([]*domainSchedule.Schedule, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT s.id, s.group_id, s.subject_id, s.date, s.pair_number, s.classroom,
+		       s.teacher_initials_id, s.start_time, s.end_time
+		FROM schedule s
+		JOIN groups g ON g.id = s.group_id
+		WHERE g.institution_id = $1
+		  AND s.date = $2::date
+		  AND s.id <> $3
+		  AND ((s.group_id = $4 AND s.pair_number = $5)
+		       OR (s.start_time < $7::time AND s.end_time > $6::time))
+		ORDER BY s.start_time
+	`, institutionID, dateArg(e.Date), excludeID, e.GroupID, e.PairNumber,
+		e.StartTime.Format("15:04:05"), e.EndTime.Format("15:04:05"))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка поиска пересекающихся пар: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*domainSchedule.Schedule
+	for rows.Next() {
+		ent := &domainSchedule.Schedule{}
+		if err := rows.Scan(&ent.ID, &ent.GroupID, &ent.SubjectID, &ent.Date, &ent.PairNumber,
+			&ent.Classroom, &ent.TeacherInitialsID, &ent.StartTime, &ent.EndTime); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования записи расписания: %w", err)
+		}
+		entries = append(entries, ent)
+	}
+	return entries, rows.Err()
+}
+
+// dateArg превращает границу периода в параметр запроса: нулевая дата становится NULL.
+func dateArg(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.Format("2006-01-02")
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	domainSubject "EduSync/internal/domain/subject"
+	"github.com/lib/pq"
 )
 
 // SubjectRepository управляет операциями с предметами в базе данных.
@@ -20,17 +21,16 @@ func NewSubjectRepository(db *sql.DB) repository.SubjectRepository {
 	return &subjectRepository{db: db}
 }
 
-// Create создаёт предмет, если его ещё нет в БД.
+// Create создаёт предмет, если его ещё нет в БД под этим названием или прежним названием.
 func (r *subjectRepository) Create(ctx context.Context, name string, institutionID int) (int, error) {
-	var existingID int
-	err := r.db.QueryRowContext(ctx, `SELECT id FROM subjects WHERE name = $1 AND institution_id = $2`, name, institutionID).Scan(&existingID)
-	if err == nil {
-		// Предмет уже существует, возвращаем его ID
-		return existingID, nil
-	}
-	if !errors.Is(err, sql.ErrNoRows) {
+	existing, err := r.ByNameAndInstitution(ctx, name, institutionID)
+	if err != nil {
 		return 0, fmt.Errorf("ошибка проверки предмета: %w", err)
 	}
+	if existing != nil {
+		// Предмет уже существует, возвращаем его ID
+		return existing.ID, nil
+	}
 
 	// Вставка нового предмета
 	var subjectID int
@@ -98,12 +98,23 @@ func (r *subjectRepository) ByGroupID(ctx context.Context, groupID int) ([]*doma
 	return subjects, nil
 }
 
-// ByNameAndInstitution получает список предметов по названию группы и id учреждения.
+// ByNameAndInstitution получает предмет учреждения по названию. Прежние названия
+// объединённых предметов ведут на предмет, в который их слили.
 func (r *subjectRepository) ByNameAndInstitution(ctx context.Context, discipline string, id int) (*domainSubject.Subject, error) {
 	subject := &domainSubject.Subject{}
-	err := r.db.QueryRowContext(ctx, `SELECT id, name, institution_id
-		FROM subjects 
-		WHERE institution_id = $1 AND name = $2`, id, discipline).
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, name, institution_id FROM (
+			SELECT id, name, institution_id, 0 AS rank
+			FROM subjects
+			WHERE institution_id = $1 AND name = $2
+			UNION ALL
+			SELECT s.id, s.name, s.institution_id, 1
+			FROM subject_aliases a
+			JOIN subjects s ON s.id = a.subject_id
+			WHERE a.institution_id = $1 AND a.name = $2
+		) found
+		ORDER BY rank
+		LIMIT 1`, id, discipline).
 		Scan(&subject.ID, &subject.Name, &subject.InstitutionID)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
@@ -113,3 +124,83 @@ func (r *subjectRepository) ByNameAndInstitution(ctx context.Context, discipline
 	}
 	return subject, nil
 }
+
+// Rename меняет название предмета.
+func (r *subjectRepository) Rename(ctx context.Context, id int, name string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE subjects SET name = $1 WHERE id = $2`, name, id)
+	if repository.IsUniqueViolation(err) {
+		return domainSubject.ErrExists
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка переименования предмета: %w", err)
+	}
+	return nil
+}
+
+// Delete удаляет предмет, если он не используется в расписании и чатах.
+func (r *subjectRepository) Delete(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM subjects WHERE id = $1`, id)
+	if repository.IsForeignKeyViolation(err) {
+		return domainSubject.ErrInUse
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка удаления предмета: %w", err)
+	}
+	return nil
+}
+
+// Merge переносит расписание и чаты предметов sourceIDs на предмет targetID
+// и удаляет исходные предметы, запоминая их названия как прежние названия targetID.
+// Всё выполняется в одной транзакции.
+func (r *subjectRepository) Merge(ctx context.Context, targetID int, sourceIDs []int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	all := pq.Array(append([]int{targetID}, sourceIDs...))
+	sources := pq.Array(sourceIDs)
+
+	// chats_unique (group_id, subject_id, owner_id) не даст слить два чата в один
+	var conflicts int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM (
+			SELECT group_id, owner_id
+			FROM chats
+			WHERE subject_id = ANY($1)
+			GROUP BY group_id, owner_id
+			HAVING COUNT(*) > 1
+		) dup`, all).Scan(&conflicts)
+	if err != nil {
+		return fmt.Errorf("ошибка проверки чатов: %w", err)
+	}
+	if conflicts > 0 {
+		return domainSubject.ErrMergeChatConflict
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE schedule SET subject_id = $1 WHERE subject_id = ANY($2)`, targetID, sources); err != nil {
+		return fmt.Errorf("ошибка переноса расписания: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE chats SET subject_id = $1 WHERE subject_id = ANY($2)`, targetID, sources); err != nil {
+		return fmt.Errorf("ошибка переноса чатов: %w", err)
+	}
+	// Синхронизация по-прежнему получит эти названия от источника
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE subject_aliases SET subject_id = $1 WHERE subject_id = ANY($2)
+	`, targetID, sources); err != nil {
+		return fmt.Errorf("ошибка переноса прежних названий: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO subject_aliases (institution_id, name, subject_id)
+		SELECT institution_id, name, $1 FROM subjects WHERE id = ANY($2)
+		ON CONFLICT (institution_id, name) DO UPDATE SET subject_id = EXCLUDED.subject_id
+	`, targetID, sources); err != nil {
+		return fmt.Errorf("ошибка сохранения прежних названий: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM subjects WHERE id = ANY($1)`, sources); err != nil {
+		return fmt.Errorf("ошибка удаления предметов: %w", err)
+	}
+	return tx.Commit()
+}
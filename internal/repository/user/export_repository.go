@@ -0,0 +1,96 @@
+package user
+
+import (
+	domainUser "EduSync/internal/domain/user"
+	"EduSync/internal/repository"
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// exportRepository собирает данные пользователя для выгрузки и удаления.
+type exportRepository struct {
+	db *sql.DB
+}
+
+// NewExportRepository создаёт репозиторий данных пользователя.
+func NewExportRepository(db *sql.DB) repository.UserExportRepository {
+	return &exportRepository{db: db}
+}
+
+// Messages возвращает сообщения пользователя во всех чатах.
+func (r *exportRepository) Messages(ctx context.Context, userID int) ([]*domainUser.ExportMessage, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, chat_id, COALESCE(text, ''), parent_message_id, created_at
+		FROM messages
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at, id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения сообщений пользователя: %w", err)
+	}
+	defer rows.Close()
+
+	var out []*domainUser.ExportMessage
+	for rows.Next() {
+		m := new(domainUser.ExportMessage)
+		var parentID sql.NullInt64
+		if err := rows.Scan(&m.ID, &m.ChatID, &m.Text, &parentID, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования сообщения: %w", err)
+		}
+		if parentID.Valid {
+			id := int(parentID.Int64)
+			m.ParentMessageID = &id
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+// Files возвращает файлы, прикреплённые пользователем к сообщениям.
+func (r *exportRepository) Files(ctx context.Context, userID int) ([]*domainUser.ExportFile, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT mf.id, mf.message_id, mf.file_url
+		FROM message_files mf
+		JOIN messages m ON m.id = mf.message_id
+		WHERE m.user_id = $1
+		ORDER BY mf.id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения файлов пользователя: %w", err)
+	}
+	defer rows.Close()
+
+	var out []*domainUser.ExportFile
+	for rows.Next() {
+		f := new(domainUser.ExportFile)
+		if err := rows.Scan(&f.ID, &f.MessageID, &f.Path); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования файла: %w", err)
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+// Votes возвращает голоса пользователя в опросах.
+func (r *exportRepository) Votes(ctx context.Context, userID int) ([]*domainUser.ExportVote, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT p.id, p.chat_id, p.question, o.id, o.option_text
+		FROM votes v
+		JOIN poll_options o ON o.id = v.poll_option_id
+		JOIN polls p ON p.id = o.poll_id
+		WHERE v.user_id = $1
+		ORDER BY p.id, o.id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения голосов пользователя: %w", err)
+	}
+	defer rows.Close()
+
+	var out []*domainUser.ExportVote
+	for rows.Next() {
+		v := new(domainUser.ExportVote)
+		if err := rows.Scan(&v.PollID, &v.ChatID, &v.Question, &v.OptionID, &v.Option); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования голоса: %w", err)
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
@@ -109,3 +109,13 @@ func (r *teacherRepository) Update(ctx context.Context, tx *sql.Tx, userID, inst
 	)
 	return err
 }
+
+// OwnedChats возвращает число чатов, владельцем которых является преподаватель.
+func (r *teacherRepository) OwnedChats(ctx context.Context, userID int) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM chats WHERE owner_id = $1`, userID).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка подсчёта чатов преподавателя: %w", err)
+	}
+	return n, nil
+}
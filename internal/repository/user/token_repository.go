@@ -1,14 +1,18 @@
 package user
 
 import (
+	domainUser "EduSync/internal/domain/user"
 	"EduSync/internal/repository"
+	"EduSync/internal/util"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 )
 
 // TokenRepository обеспечивает работу с таблицей токенов.
+// Токены хранятся только в виде хэшей util.HashToken, методы принимают исходные токены.
 type tokenRepository struct {
 	db *sql.DB
 }
@@ -24,18 +28,29 @@ func (r *tokenRepository) DeleteForUser(ctx context.Context, userID int) error {
 	return err
 }
 
-// SaveToken сохраняет новый токен для пользователя.
+// SaveToken сохраняет новую сессию пользователя и удаляет его истёкшие сессии
+// вместе с историей refresh-токенов, срок которых уже вышел.
 func (r *tokenRepository) Save(ctx context.Context, userID int, accessToken, refreshToken, userAgent, ipAddress string, expiresAt time.Time) error {
+	if _, err := r.db.ExecContext(ctx, `
+		DELETE FROM tokens WHERE user_id = $1 AND expires_at < CURRENT_TIMESTAMP
+	`, userID); err != nil {
+		return err
+	}
+	if _, err := r.db.ExecContext(ctx, `
+		DELETE FROM used_refresh_tokens WHERE user_id = $1 AND expires_at < CURRENT_TIMESTAMP
+	`, userID); err != nil {
+		return err
+	}
 	_, err := r.db.ExecContext(ctx, `
-		INSERT INTO tokens (user_id, access_token, refresh_token, user_agent, ip_address, expires_at)
+		INSERT INTO tokens (user_id, access_hash, refresh_hash, user_agent, ip_address, expires_at)
 		VALUES ($1, $2, $3, $4, $5, $6)
-	`, userID, accessToken, refreshToken, userAgent, ipAddress, expiresAt)
+	`, userID, util.HashToken(accessToken), util.HashToken(refreshToken), userAgent, ipAddress, expiresAt)
 	return err
 }
 
-// RevokeToken удаляет токен по значению access_token.
+// RevokeToken удаляет сессию по access-токену.
 func (r *tokenRepository) Revoke(ctx context.Context, accessToken string) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM tokens WHERE access_token = $1`, accessToken)
+	_, err := r.db.ExecContext(ctx, `DELETE FROM tokens WHERE access_hash = $1`, util.HashToken(accessToken))
 	return err
 }
 
@@ -45,7 +60,11 @@ func (r *tokenRepository) IsValid(ctx context.Context, accessToken string) (bool
 	err := r.db.QueryRowContext(ctx, `
 		SELECT expires_at 
 		FROM tokens 
-		WHERE access_token = $1`, accessToken).Scan(&expiresAt)
+		WHERE access_hash = $1`, util.HashToken(accessToken)).Scan(&expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Сессию завершили с другого устройства
+		return false, nil
+	}
 	if err != nil {
 		return false, fmt.Errorf("ошибка провкерки токена: %w", err)
 	}
@@ -63,10 +82,162 @@ func (r *tokenRepository) IsRefreshValid(ctx context.Context, refreshToken strin
 		SELECT EXISTS (
 			SELECT 1 
 			FROM tokens 
-			WHERE refresh_token = $1
-			)`, refreshToken).Scan(&exists)
+			WHERE refresh_hash = $1
+			)`, util.HashToken(refreshToken)).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("ошибка проверки токена: %w", err)
 	}
 	return exists, nil
 }
+
+// DeleteForDevice удаляет сессии пользователя с того же устройства,
+// чтобы повторный вход не плодил сессии.
+func (r *tokenRepository) DeleteForDevice(ctx context.Context, userID int, userAgent string) error {
+	_, err := r.db.ExecContext(ctx, `
+		DELETE FROM tokens
+		WHERE user_id = $1 AND COALESCE(user_agent, '') = $2
+	`, userID, userAgent)
+	return err
+}
+
+// Rotate заменяет пару токенов сессии, которой принадлежит refreshToken,
+// и запоминает прежний refresh-токен до истечения его срока. Возвращает ID сессии или 0, если её нет.
+// Если refreshToken уже был обменян, сессии его семейства удаляются
+// и возвращается ErrTokenReuse вместе с ID удалённой сессии (0, если она уже завершена).
+func (r *tokenRepository) Rotate(ctx context.Context, refreshToken, accessToken, newRefreshToken, ipAddress string, expiresAt time.Time) (int, error) {
+	oldHash := util.HashToken(refreshToken)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	var (
+		sessionID, userID int
+		family            string
+		oldExpiresAt      time.Time
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, user_id, family, expires_at FROM tokens WHERE refresh_hash = $1 FOR UPDATE
+	`, oldHash).Scan(&sessionID, &userID, &family, &oldExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return r.revokeReused(ctx, tx, oldHash)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения сессии: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO used_refresh_tokens (refresh_hash, user_id, family, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, oldHash, userID, family, oldExpiresAt); err != nil {
+		return 0, fmt.Errorf("ошибка сохранения истории токенов: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE tokens
+		SET access_hash = $1, refresh_hash = $2, ip_address = $3,
+		    expires_at = $4, last_used_at = CURRENT_TIMESTAMP
+		WHERE id = $5
+	`, util.HashToken(accessToken), util.HashToken(newRefreshToken), ipAddress, expiresAt, sessionID); err != nil {
+		return 0, fmt.Errorf("ошибка обновления сессии: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("ошибка обновления сессии: %w", err)
+	}
+	return sessionID, nil
+}
+
+// revokeReused удаляет сессии семейства, в котором refresh-токен с хэшем hash уже был обменян.
+// История не зависит от строки сессии, поэтому повтор распознаётся и после её завершения.
+func (r *tokenRepository) revokeReused(ctx context.Context, tx *sql.Tx, hash string) (int, error) {
+	var (
+		userID int
+		family string
+	)
+	err := tx.QueryRowContext(ctx, `
+		SELECT user_id, family FROM used_refresh_tokens
+		WHERE refresh_hash = $1 AND expires_at > CURRENT_TIMESTAMP
+	`, hash).Scan(&userID, &family)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка проверки истории токенов: %w", err)
+	}
+	var sessionID int
+	err = tx.QueryRowContext(ctx, `
+		DELETE FROM tokens WHERE user_id = $1 AND family = $2 RETURNING id
+	`, userID, family).Scan(&sessionID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("ошибка отзыва сессии: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("ошибка отзыва сессии: %w", err)
+	}
+	return sessionID, domainUser.ErrTokenReuse
+}
+
+// Sessions возвращает действующие сессии пользователя, последние — первыми.
+func (r *tokenRepository) Sessions(ctx context.Context, userID int) ([]*domainUser.Session, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, COALESCE(user_agent, ''), COALESCE(ip_address, ''),
+		       COALESCE(created_at, last_used_at), last_used_at, expires_at
+		FROM tokens
+		WHERE user_id = $1 AND expires_at > CURRENT_TIMESTAMP
+		ORDER BY last_used_at DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения сессий: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*domainUser.Session
+	for rows.Next() {
+		s := new(domainUser.Session)
+		if err := rows.Scan(&s.ID, &s.UserAgent, &s.IPAddress, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования сессии: %w", err)
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// SessionID возвращает идентификатор сессии по access-токену; 0 — сессии нет.
+func (r *tokenRepository) SessionID(ctx context.Context, accessToken string) (int, error) {
+	var id int
+	err := r.db.QueryRowContext(ctx, `SELECT id FROM tokens WHERE access_hash = $1`, util.HashToken(accessToken)).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения сессии: %w", err)
+	}
+	return id, nil
+}
+
+// DeleteSession завершает одну сессию пользователя.
+func (r *tokenRepository) DeleteSession(ctx context.Context, userID, sessionID int) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM tokens WHERE id = $1 AND user_id = $2`, sessionID, userID)
+	if err != nil {
+		return false, fmt.Errorf("ошибка удаления сессии: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("ошибка удаления сессии: %w", err)
+	}
+	return n > 0, nil
+}
+
+// DeleteOtherSessions завершает все сессии пользователя, кроме keepSessionID.
+func (r *tokenRepository) DeleteOtherSessions(ctx context.Context, userID, keepSessionID int) (int, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM tokens WHERE user_id = $1 AND id <> $2`, userID, keepSessionID)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка удаления сессий: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка удаления сессий: %w", err)
+	}
+	return int(n), nil
+}
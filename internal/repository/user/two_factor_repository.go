@@ -0,0 +1,246 @@
+package user
+
+import (
+	domainInstitution "EduSync/internal/domain/institution"
+	domainUser "EduSync/internal/domain/user"
+	"EduSync/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// twoFactorRepository обеспечивает работу с таблицами двухфакторной аутентификации.
+type twoFactorRepository struct {
+	db *sql.DB
+}
+
+func NewTwoFactorRepository(db *sql.DB) repository.TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+// ByUserID возвращает настройки TOTP пользователя; nil — 2FA не подключали.
+func (r *twoFactorRepository) ByUserID(ctx context.Context, userID int) (*domainUser.TwoFactor, error) {
+	tf := &domainUser.TwoFactor{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT user_id, secret, enabled, last_step, enabled_at
+		FROM user_two_factor
+		WHERE user_id = $1
+	`, userID).Scan(&tf.UserID, &tf.Secret, &tf.Enabled, &tf.LastStep, &tf.EnabledAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения настроек 2FA: %w", err)
+	}
+	return tf, nil
+}
+
+// SaveSecret сохраняет новый, ещё не подтверждённый секрет.
+func (r *twoFactorRepository) SaveSecret(ctx context.Context, userID int, secret string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_two_factor (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+			SET secret = EXCLUDED.secret, enabled = FALSE, last_step = 0,
+			    created_at = CURRENT_TIMESTAMP, enabled_at = NULL
+	`, userID, secret)
+	return err
+}
+
+// Enable включает 2FA после проверки первого кода и выдаёт коды восстановления.
+func (r *twoFactorRepository) Enable(ctx context.Context, userID int, step int64, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE user_two_factor
+		SET enabled = TRUE, last_step = $2, enabled_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1
+	`, userID, step); err != nil {
+		return fmt.Errorf("ошибка включения 2FA: %w", err)
+	}
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Disable удаляет секрет и коды восстановления.
+func (r *twoFactorRepository) Disable(ctx context.Context, userID int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM two_factor_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("ошибка удаления кодов восстановления: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_two_factor WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("ошибка отключения 2FA: %w", err)
+	}
+	return tx.Commit()
+}
+
+// UseStep запоминает принятый шаг TOTP. false — шаг уже использован
+// (код пришёл повторно, в том числе параллельным запросом).
+func (r *twoFactorRepository) UseStep(ctx context.Context, userID int, step int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE user_two_factor SET last_step = $2
+		WHERE user_id = $1 AND last_step < $2
+	`, userID, step)
+	if err != nil {
+		return false, fmt.Errorf("ошибка сохранения шага TOTP: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// ReplaceRecoveryCodes заменяет все коды восстановления пользователя.
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID int, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM two_factor_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("ошибка удаления кодов восстановления: %w", err)
+	}
+	for _, h := range codeHashes {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO two_factor_recovery_codes (user_id, code_hash) VALUES ($1, $2)
+		`, userID, h); err != nil {
+			return fmt.Errorf("ошибка сохранения кода восстановления: %w", err)
+		}
+	}
+	return nil
+}
+
+// UseRecoveryCode гасит код восстановления. false — кода нет или он уже использован.
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE two_factor_recovery_codes SET used_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("ошибка проверки кода восстановления: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// RecoveryCodesLeft возвращает число неиспользованных кодов восстановления.
+func (r *twoFactorRepository) RecoveryCodesLeft(ctx context.Context, userID int) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM two_factor_recovery_codes
+		WHERE user_id = $1 AND used_at IS NULL
+	`, userID).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка подсчёта кодов восстановления: %w", err)
+	}
+	return n, nil
+}
+
+// CreateChallenge сохраняет незавершённый вход и удаляет просроченные.
+func (r *twoFactorRepository) CreateChallenge(ctx context.Context, tokenHash string, userID int, expiresAt time.Time) error {
+	if _, err := r.db.ExecContext(ctx, `
+		DELETE FROM two_factor_challenges WHERE expires_at < CURRENT_TIMESTAMP
+	`); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO two_factor_challenges (token_hash, user_id, expires_at)
+		VALUES ($1, $2, $3)
+	`, tokenHash, userID, expiresAt)
+	return err
+}
+
+// ChallengeAttempt засчитывает попытку по незавершённому входу и возвращает его пользователя.
+// 0 — вход не найден, просрочен или попытки исчерпаны.
+func (r *twoFactorRepository) ChallengeAttempt(ctx context.Context, tokenHash string, maxAttempts int) (int, error) {
+	var userID int
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE two_factor_challenges SET attempts = attempts + 1
+		WHERE token_hash = $1 AND expires_at > CURRENT_TIMESTAMP AND attempts < $2
+		RETURNING user_id
+	`, tokenHash, maxAttempts).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка проверки входа: %w", err)
+	}
+	return userID, nil
+}
+
+// DeleteChallenge завершает вход.
+func (r *twoFactorRepository) DeleteChallenge(ctx context.Context, tokenHash string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM two_factor_challenges WHERE token_hash = $1`, tokenHash)
+	return err
+}
+
+// RequiredRoles возвращает роли, для которых учреждение требует 2FA.
+func (r *twoFactorRepository) RequiredRoles(ctx context.Context, institutionID int) ([]domainUser.Role, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT role FROM two_factor_policies WHERE institution_id = $1 ORDER BY role
+	`, institutionID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения политики 2FA: %w", err)
+	}
+	defer rows.Close()
+
+	roles := []domainUser.Role{}
+	for rows.Next() {
+		var role domainUser.Role
+		if err := rows.Scan(&role); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования политики 2FA: %w", err)
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+// SetRequiredRoles заменяет список ролей, для которых учреждение требует 2FA.
+func (r *twoFactorRepository) SetRequiredRoles(ctx context.Context, institutionID int, roles []domainUser.Role) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM two_factor_policies WHERE institution_id = $1`, institutionID); err != nil {
+		return fmt.Errorf("ошибка обновления политики 2FA: %w", err)
+	}
+	for _, role := range roles {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO two_factor_policies (institution_id, role) VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, institutionID, role); err != nil {
+			if repository.IsForeignKeyViolation(err) {
+				return domainInstitution.ErrNotFound
+			}
+			return fmt.Errorf("ошибка обновления политики 2FA: %w", err)
+		}
+	}
+	return tx.Commit()
+}
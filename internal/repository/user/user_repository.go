@@ -6,6 +6,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 // userRepository обеспечивает работу с таблицей пользователей.
@@ -20,9 +23,9 @@ func NewUserRepository(db *sql.DB) repository.UserRepository {
 func (r *userRepository) Create(ctx context.Context, tx *sql.Tx, user *domainUser.User) (int, error) {
 	var userID int
 	err := tx.QueryRowContext(ctx, `
-		INSERT INTO users (email, password_hash, full_name, is_teacher)
-		VALUES ($1, $2, $3, $4) RETURNING id
-	`, user.Email, user.PasswordHash, user.FullName, user.IsTeacher).Scan(&userID)
+		INSERT INTO users (email, password_hash, full_name, is_teacher, role, locale)
+		VALUES ($1, $2, $3, $4, $5, COALESCE(NULLIF($6, ''), 'ru')) RETURNING id
+	`, user.Email, user.PasswordHash, user.FullName, user.IsTeacher, user.Role, user.Locale).Scan(&userID)
 	return userID, err
 }
 
@@ -30,7 +33,7 @@ func (r *userRepository) ByEmail(ctx context.Context, email string) (*domainUser
 	user := &domainUser.User{}
 
 	err := r.db.QueryRowContext(ctx, `
-		SELECT id, email, password_hash, full_name, is_teacher, is_active
+		SELECT id, email, password_hash, full_name, is_teacher, is_active, role, locale, deleted_at
 		FROM users 
 		WHERE email = $1
 	`, email).Scan(
@@ -40,6 +43,9 @@ func (r *userRepository) ByEmail(ctx context.Context, email string) (*domainUser
 		&user.FullName,
 		&user.IsTeacher,
 		&user.IsActive,
+		&user.Role,
+		&user.Locale,
+		&user.DeletedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil // Пользователь не найден, возвращаем nil, nil
@@ -59,7 +65,7 @@ func (r *userRepository) ByID(ctx context.Context, ID int) (*domainUser.User, er
 	user := &domainUser.User{}
 
 	err := r.db.QueryRowContext(ctx, `
-		SELECT id, email, password_hash, full_name, is_teacher, is_active 
+		SELECT id, email, password_hash, full_name, is_teacher, is_active, role, locale, deleted_at
 		FROM users 
 		WHERE id = $1
 	`, ID).Scan(
@@ -69,6 +75,9 @@ func (r *userRepository) ByID(ctx context.Context, ID int) (*domainUser.User, er
 		&user.FullName,
 		&user.IsTeacher,
 		&user.IsActive,
+		&user.Role,
+		&user.Locale,
+		&user.DeletedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil // Пользователь не найден, возвращаем nil, nil
@@ -78,10 +87,46 @@ func (r *userRepository) ByID(ctx context.Context, ID int) (*domainUser.User, er
 	}
 	return user, err
 }
+
+// ByIDs возвращает пользователей с указанными ID одним запросом; отсутствующие пропускаются.
+func (r *userRepository) ByIDs(ctx context.Context, ids []int) ([]*domainUser.User, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, email, password_hash, full_name, is_teacher, is_active, role, locale, deleted_at
+		FROM users
+		WHERE id = ANY($1)
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения пользователей: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*domainUser.User
+	for rows.Next() {
+		user := &domainUser.User{}
+		if err := rows.Scan(
+			&user.ID,
+			&user.Email,
+			&user.PasswordHash,
+			&user.FullName,
+			&user.IsTeacher,
+			&user.IsActive,
+			&user.Role,
+			&user.Locale,
+			&user.DeletedAt,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования пользователя: %w", err)
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
 func (r *userRepository) Update(ctx context.Context, tx *sql.Tx, user *domainUser.User) error {
 	_, err := tx.ExecContext(ctx,
-		`UPDATE users SET full_name = $1 WHERE id = $2`,
-		user.FullName, user.ID,
+		`UPDATE users SET full_name = $1, locale = $2 WHERE id = $3`,
+		user.FullName, user.Locale, user.ID,
 	)
 	return err
 }
@@ -100,9 +145,79 @@ func (r *userRepository) UpdatePassword(ctx context.Context, userID int, hashedP
 	return err
 }
 
+// UpdateEmail меняет email пользователя. Если адрес занят, возвращает ErrUserAlreadyExists.
+func (r *userRepository) UpdateEmail(ctx context.Context, tx *sql.Tx, userID int, email string) error {
+	_, err := tx.ExecContext(ctx, `
+        UPDATE users SET email = $1 WHERE id = $2
+    `, email, userID)
+	if repository.IsUniqueViolation(err) {
+		return domainUser.ErrUserAlreadyExists
+	}
+	return err
+}
+
+// DeleteByID удаляет пользователя; его сообщения удаляются каскадно.
+// Ссылки чужих сообщений на группы из его сообщений снимаются заранее,
+// иначе внешний ключ message_group_id не даст удалить строки.
 func (r *userRepository) DeleteByID(ctx context.Context, tx *sql.Tx, userID int) error {
+	if _, err := tx.ExecContext(ctx, `
+        UPDATE messages SET message_group_id = NULL
+        WHERE user_id <> $1
+          AND message_group_id IN (SELECT id FROM messages WHERE user_id = $1)
+    `, userID); err != nil {
+		return err
+	}
 	_, err := tx.ExecContext(ctx, `
         DELETE FROM users WHERE id = $1
     `, userID)
 	return err
 }
+
+// SetRole меняет роль пользователя.
+func (r *userRepository) SetRole(ctx context.Context, userID int, role domainUser.Role) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE users SET role = $1 WHERE id = $2
+    `, role, userID)
+	return err
+}
+
+// SoftDelete помечает аккаунт удалённым.
+func (r *userRepository) SoftDelete(ctx context.Context, userID int) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE users SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL
+    `, userID)
+	return err
+}
+
+// Restore снимает пометку об удалении. Возвращает false, если аккаунт не был удалён.
+func (r *userRepository) Restore(ctx context.Context, userID int) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+        UPDATE users SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL
+    `, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// DeletedBefore возвращает аккаунты, удалённые раньше before.
+func (r *userRepository) DeletedBefore(ctx context.Context, before time.Time) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT id FROM users WHERE deleted_at < $1 ORDER BY deleted_at
+    `, before)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения удалённых аккаунтов: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования аккаунта: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
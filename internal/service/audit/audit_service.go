@@ -0,0 +1,84 @@
+package audit
+
+import (
+	domainAudit "EduSync/internal/domain/audit"
+	domainUser "EduSync/internal/domain/user"
+	"EduSync/internal/repository"
+	"EduSync/internal/service"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 200
+)
+
+type auditService struct {
+	repo repository.AuditRepository
+	log  *logrus.Logger
+}
+
+// NewAuditService создает сервис журнала административных действий.
+func NewAuditService(repo repository.AuditRepository, log *logrus.Logger) service.AuditService {
+	return &auditService{repo: repo, log: log}
+}
+
+// Record записывает действие в журнал. Ошибка записи только логируется:
+// изменение к этому моменту уже сохранено и откатывать его не нужно.
+func (s *auditService) Record(
+	ctx context.Context,
+	actor domainUser.Actor,
+	institutionID int,
+	entity domainAudit.Entity,
+	entityID int,
+	action domainAudit.Action,
+	details interface{},
+) {
+	e := &domainAudit.Entry{
+		Entity:   entity,
+		EntityID: entityID,
+		Action:   action,
+	}
+	if actor.ID != 0 {
+		e.UserID = &actor.ID
+	}
+	if institutionID != 0 {
+		e.InstitutionID = &institutionID
+	}
+	if details != nil {
+		raw, err := json.Marshal(details)
+		if err != nil {
+			s.log.Errorf("Ошибка сериализации записи журнала: %v", err)
+		}
+		e.Details = raw
+	}
+	if err := s.repo.Add(ctx, e); err != nil {
+		s.log.Errorf("Не удалось записать в журнал %s %s #%d: %v", action, entity, entityID, err)
+	}
+}
+
+// List возвращает журнал. Администратор учреждения видит только своё учреждение.
+func (s *auditService) List(ctx context.Context, actor domainUser.Actor, f domainAudit.Filter) ([]*domainAudit.Entry, error) {
+	if !actor.Role.Can(domainUser.PermSystemManage) {
+		f.InstitutionID = actor.InstitutionID
+	}
+	if f.Limit <= 0 {
+		f.Limit = defaultLimit
+	}
+	if f.Limit > maxLimit {
+		f.Limit = maxLimit
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+	entries, err := s.repo.List(ctx, f)
+	if err != nil {
+		s.log.Errorf("Ошибка получения журнала: %v", err)
+		return nil, fmt.Errorf("не удалось получить журнал")
+	}
+	return entries, nil
+}
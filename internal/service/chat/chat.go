@@ -1,6 +1,7 @@
 package chat
 
 import (
+	"EduSync/internal/delivery/ws"
 	"EduSync/internal/repository"
 	"EduSync/internal/service"
 	"context"
@@ -17,22 +18,28 @@ import (
 
 type chatService struct {
 	repo     repository.ChatRepository
+	msgRepo  repository.MessageRepository
 	subjRepo repository.SubjectRepository
 	userRepo repository.UserRepository
 	log      *logrus.Logger
+	hub      *ws.Hub
 }
 
 func NewChatService(
 	repo repository.ChatRepository,
+	msgRepo repository.MessageRepository,
 	subjRepo repository.SubjectRepository,
 	userRepo repository.UserRepository,
 	log *logrus.Logger,
+	hub *ws.Hub,
 ) service.ChatService {
 	return &chatService{
 		repo:     repo,
+		msgRepo:  msgRepo,
 		subjRepo: subjRepo,
 		userRepo: userRepo,
 		log:      log,
+		hub:      hub,
 	}
 }
 
@@ -62,7 +69,7 @@ func (s *chatService) ListForUser(ctx context.Context, userID int, isTeacher boo
 
 	out := make([]*dtoChat.ChatInfo, 0, len(chats))
 	for _, c := range chats {
-		info, err := s.buildChatInfo(ctx, c)
+		info, err := s.buildChatInfo(ctx, c, userID)
 		if err != nil {
 			s.log.Errorf("buildChatInfo %+v: %v", c, err)
 			continue
@@ -86,7 +93,7 @@ func (s *chatService) ChatParticipants(ctx context.Context, chatID int) ([]*doma
 func (s *chatService) JoinChat(ctx context.Context, userID int, code string) (*dtoChat.ChatInfo, error) {
 	c, err := s.repo.ChatByCode(ctx, code)
 	if err != nil {
-		s.log.Errorf("repo.ChatByCode(%d): %v", code, err)
+		s.log.Errorf("repo.ChatByCode(%s): %v", code, err)
 		return nil, fmt.Errorf("не удалось присоединиться к чату")
 	}
 	if c == nil {
@@ -104,7 +111,7 @@ func (s *chatService) JoinChat(ctx context.Context, userID int, code string) (*d
 	}
 
 	// вернём DTO
-	info, err := s.buildChatInfo(ctx, c)
+	info, err := s.buildChatInfo(ctx, c, userID)
 	if err != nil {
 		s.log.Errorf("buildChatInfo after join: %v", err)
 		return nil, fmt.Errorf("не удалось получить информацию о чате")
@@ -177,6 +184,27 @@ func (s *chatService) LeaveChat(ctx context.Context, chatID int, userID int) err
 	return nil
 }
 
+// MarkRead отмечает сообщения чата прочитанными до messageID включительно
+// (0 — до последнего) и рассылает участникам событие message:read.
+func (s *chatService) MarkRead(ctx context.Context, chatID, userID, messageID int) (*domainChat.ReadReceipt, error) {
+	rr, err := s.repo.MarkRead(ctx, chatID, userID, messageID)
+	if err != nil {
+		s.log.Errorf("repo.MarkRead(%d,%d,%d): %v", chatID, userID, messageID, err)
+		return nil, fmt.Errorf("не удалось отметить сообщения прочитанными")
+	}
+	if rr == nil {
+		return nil, domainChat.ErrNotFound
+	}
+	rr.ReadCount, err = s.repo.ReadCount(ctx, chatID, rr.MessageID)
+	if err != nil {
+		s.log.Errorf("repo.ReadCount(%d,%d): %v", chatID, rr.MessageID, err)
+	}
+
+	room := fmt.Sprintf("chat_%d", chatID)
+	s.hub.Broadcast(room, "message:read", rr)
+	return rr, nil
+}
+
 // generateRandomCode генерирует случайный код указанной длины.
 func generateRandomCode(length int) string {
 	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
@@ -188,8 +216,9 @@ func generateRandomCode(length int) string {
 	return code.String()
 }
 
-// buildChatInfo собирает ChatInfo из domain.Chat, добирая названия и ФИО.
-func (s *chatService) buildChatInfo(ctx context.Context, c *domainChat.Chat) (*dtoChat.ChatInfo, error) {
+// buildChatInfo собирает ChatInfo из domain.Chat, добирая названия, ФИО
+// и состояние прочтения для userID.
+func (s *chatService) buildChatInfo(ctx context.Context, c *domainChat.Chat, userID int) (*dtoChat.ChatInfo, error) {
 	// 1) subject name
 	subj, err := s.subjRepo.ByID(ctx, c.SubjectID)
 	if err != nil {
@@ -210,12 +239,29 @@ func (s *chatService) buildChatInfo(ctx context.Context, c *domainChat.Chat) (*d
 		ownerName = usr.FullName
 	}
 
+	// 3) последнее сообщение и непрочитанные
+	last, err := s.msgRepo.LastMessage(ctx, c.ID)
+	if err != nil {
+		return nil, fmt.Errorf("msgRepo.LastMessage: %w", err)
+	}
+	unread, err := s.repo.UnreadCount(ctx, c.ID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("repo.UnreadCount: %w", err)
+	}
+	lastRead, err := s.repo.LastReadMessageID(ctx, c.ID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("repo.LastReadMessageID: %w", err)
+	}
+
 	return &dtoChat.ChatInfo{
-		ID:            c.ID,
-		GroupID:       c.GroupID,
-		SubjectID:     c.SubjectID,
-		SubjectName:   subjName,
-		OwnerID:       c.OwnerID,
-		OwnerFullName: ownerName,
+		ID:                c.ID,
+		GroupID:           c.GroupID,
+		SubjectID:         c.SubjectID,
+		SubjectName:       subjName,
+		OwnerID:           c.OwnerID,
+		OwnerFullName:     ownerName,
+		UnreadCount:       unread,
+		LastReadMessageID: lastRead,
+		LastMessage:       last,
 	}, nil
 }
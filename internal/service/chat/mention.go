@@ -0,0 +1,54 @@
+package chat
+
+import (
+	"EduSync/internal/delivery/ws"
+	"context"
+	"strings"
+
+	domainChat "EduSync/internal/domain/chat"
+)
+
+// Mentions возвращает страницу сообщений, где упомянут пользователь, новые первыми.
+func (s *messageService) Mentions(ctx context.Context, userID int, q domainChat.MessageQuery) ([]*domainChat.Message, error) {
+	if q.BeforeID > 0 && q.AfterID > 0 {
+		return nil, domainChat.ErrInvalidCursor
+	}
+	q.Limit = pageSize(q.Limit)
+	msgs, err := s.repo.Mentions(ctx, userID, q)
+	if err != nil {
+		s.log.Errorf("Ошибка получения упоминаний пользователя %d: %v", userID, err)
+		return nil, ErrInternal
+	}
+	if msgs == nil {
+		msgs = []*domainChat.Message{}
+	}
+	s.decorate(ctx, msgs, userID)
+	return msgs, nil
+}
+
+// resolveMentions сопоставляет упоминания в тексте с участниками чата.
+func (s *messageService) resolveMentions(ctx context.Context, msg *domainChat.Message) ([]int, error) {
+	if msg.Text == nil || !strings.Contains(*msg.Text, "@") {
+		return nil, nil
+	}
+	participants, err := s.chatRepo.GetParticipants(ctx, msg.ChatID)
+	if err != nil {
+		s.log.Errorf("resolveMentions: GetParticipants(%d): %v", msg.ChatID, err)
+		return nil, ErrInternal
+	}
+	return domainChat.ParseMentions(*msg.Text, participants, msg.UserID), nil
+}
+
+// notifyMentions шлёт message:mention в личную комнату каждого упомянутого,
+// так что событие приходит, даже если он не подписан на чат.
+func (s *messageService) notifyMentions(msg *domainChat.Message, userIDs []int) {
+	event := domainChat.MentionEvent{
+		ChatID:    msg.ChatID,
+		MessageID: msg.ID,
+		AuthorID:  msg.UserID,
+		Message:   msg,
+	}
+	for _, id := range userIDs {
+		s.hub.Broadcast(ws.UserRoom(id), "message:mention", event)
+	}
+}
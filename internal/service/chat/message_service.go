@@ -2,12 +2,17 @@ package chat
 
 import (
 	"EduSync/internal/delivery/ws"
+	domainAudit "EduSync/internal/domain/audit"
+	domainEmail "EduSync/internal/domain/email"
+	domainUser "EduSync/internal/domain/user"
 	"EduSync/internal/repository"
 	"EduSync/internal/service"
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"html"
 	"mime/multipart"
 	"os"
 	"strings"
@@ -20,34 +25,312 @@ import (
 var ErrInternal = errors.New("внутренняя ошибка сервера")
 
 type messageService struct {
-	repo repository.MessageRepository
-	log  *logrus.Logger
-	hub  *ws.Hub
+	repo     repository.MessageRepository
+	chatRepo repository.ChatRepository
+	userRepo repository.UserRepository
+	audit    service.AuditService
+	mailer   service.Mailer
+	log      *logrus.Logger
+	hub      *ws.Hub
 }
 
-func NewMessageService(repo repository.MessageRepository, logger *logrus.Logger, hub *ws.Hub) service.MessageService {
+func NewMessageService(
+	repo repository.MessageRepository,
+	chatRepo repository.ChatRepository,
+	userRepo repository.UserRepository,
+	audit service.AuditService,
+	mailer service.Mailer,
+	logger *logrus.Logger,
+	hub *ws.Hub,
+) service.MessageService {
 	return &messageService{
-		repo: repo,
-		log:  logger,
-		hub:  hub,
+		repo:     repo,
+		chatRepo: chatRepo,
+		userRepo: userRepo,
+		audit:    audit,
+		mailer:   mailer,
+		log:      logger,
+		hub:      hub,
 	}
 }
 
-func (s *messageService) Messages(ctx context.Context, chatID, limit, offset int) ([]*domainChat.Message, error) {
-	// Проверка, что значения limit и offset корректны
+const (
+	defaultPageSize = 10
+	maxPageSize     = 100
+)
+
+// Messages возвращает страницу сообщений чата, новые первыми. Страницы задаются
+// границей по id, поэтому новые сообщения не сдвигают уже загруженные.
+func (s *messageService) Messages(ctx context.Context, chatID int, q domainChat.MessageQuery) ([]*domainChat.Message, error) {
+	if q.BeforeID > 0 && q.AfterID > 0 {
+		return nil, domainChat.ErrInvalidCursor
+	}
+	q.Limit = pageSize(q.Limit)
+	msgs, err := s.repo.Messages(ctx, chatID, q)
+	if err != nil {
+		s.log.Errorf("Ошибка получения сообщений для чата %d: %v", chatID, err)
+		return nil, fmt.Errorf("не удалось получить сообщения")
+	}
+
+	s.decorate(ctx, msgs, q.ViewerID)
+	return msgs, nil
+}
+
+// Thread возвращает сообщение и страницу ответов на него, новые первыми.
+func (s *messageService) Thread(ctx context.Context, chatID, messageID int, q domainChat.MessageQuery) (*domainChat.Thread, error) {
+	parent, err := s.repo.ByID(ctx, messageID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domainChat.ErrNotFound
+	}
+	if err != nil {
+		s.log.Errorf("Thread: ByID(%d): %v", messageID, err)
+		return nil, ErrInternal
+	}
+	if parent.ChatID != chatID {
+		return nil, domainChat.ErrNotFound
+	}
+
+	q.ParentID = messageID
+	replies, err := s.Messages(ctx, chatID, q)
+	if err != nil {
+		return nil, err
+	}
+	if replies == nil {
+		replies = []*domainChat.Message{}
+	}
+	s.decorate(ctx, []*domainChat.Message{parent}, q.ViewerID)
+	return &domainChat.Thread{Parent: parent, Replies: replies}, nil
+}
+
+// Sync возвращает изменения в чате после версии since: новые и отредактированные
+// сообщения и ID удалённых. Клиент сохраняет Cursor и передаёт его в следующий запрос.
+// Изменение реакций тоже поднимает версию сообщения.
+func (s *messageService) Sync(ctx context.Context, chatID, userID int, since int64, limit int) (*domainChat.SyncResult, error) {
+	if since < 0 {
+		since = 0
+	}
+	limit = pageSize(limit)
+	changes, err := s.repo.Changes(ctx, chatID, since, limit+1)
+	if err != nil {
+		s.log.Errorf("Ошибка синхронизации чата %d: %v", chatID, err)
+		return nil, fmt.Errorf("не удалось получить изменения")
+	}
+
+	res := &domainChat.SyncResult{
+		Messages:   []*domainChat.Message{},
+		DeletedIDs: []int{},
+		Cursor:     since,
+		HasMore:    len(changes) > limit,
+	}
+	if res.HasMore {
+		changes = changes[:limit]
+	}
+	for _, m := range changes {
+		if m.DeletedAt != nil {
+			res.DeletedIDs = append(res.DeletedIDs, m.ID)
+		} else {
+			res.Messages = append(res.Messages, m)
+		}
+		res.Cursor = m.Version
+	}
+	s.decorate(ctx, res.Messages, userID)
+	return res, nil
+}
+
+// pageSize приводит размер страницы к допустимому диапазону.
+func pageSize(limit int) int {
 	if limit <= 0 {
-		limit = 10 // значение по умолчанию
+		return defaultPageSize
+	}
+	if limit > maxPageSize {
+		return maxPageSize
+	}
+	return limit
+}
+
+// decorate добирает файлы, сводку ответов, превью родителей и реакции;
+// ReactedByMe отмечается для viewerID.
+func (s *messageService) decorate(ctx context.Context, msgs []*domainChat.Message, viewerID int) {
+	s.attachFiles(ctx, msgs)
+	s.attachThreads(ctx, msgs)
+	s.attachReactions(ctx, msgs, viewerID)
+}
+
+// attachThreads заполняет ReplyCount, LastReplyAt и Parent.
+func (s *messageService) attachThreads(ctx context.Context, msgs []*domainChat.Message) {
+	if len(msgs) == 0 {
+		return
 	}
-	if offset < 0 {
-		offset = 0
+	ids := make([]int, 0, len(msgs))
+	var parentIDs []int
+	for _, m := range msgs {
+		ids = append(ids, m.ID)
+		if m.ParentMessageID != nil {
+			parentIDs = append(parentIDs, *m.ParentMessageID)
+		}
 	}
-	msgs, err := s.repo.Messages(ctx, chatID, limit, offset)
+
+	stats, err := s.repo.ReplyStats(ctx, ids)
 	if err != nil {
-		s.log.Errorf("Ошибка получения сообщений для чата %d: %v", chatID, err)
-		return nil, fmt.Errorf("не удалось получить сообщения")
+		s.log.Errorf("ReplyStats: %v", err)
+	}
+	previews, err := s.repo.Previews(ctx, parentIDs)
+	if err != nil {
+		s.log.Errorf("Previews: %v", err)
+	}
+	for _, m := range msgs {
+		if st, ok := stats[m.ID]; ok {
+			lastReplyAt := st.LastReplyAt
+			m.ReplyCount = st.Count
+			m.LastReplyAt = &lastReplyAt
+		}
+		if m.ParentMessageID != nil {
+			m.Parent = previews[*m.ParentMessageID]
+		}
+	}
+}
+
+// checkParent проверяет, что сообщение, на которое отвечают, есть в том же чате и не удалено.
+func (s *messageService) checkParent(ctx context.Context, msg *domainChat.Message) error {
+	if msg.ParentMessageID == nil {
+		return nil
+	}
+	parent, err := s.repo.ByID(ctx, *msg.ParentMessageID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domainChat.ErrInvalidParent
+	}
+	if err != nil {
+		s.log.Errorf("checkParent: ByID(%d): %v", *msg.ParentMessageID, err)
+		return ErrInternal
+	}
+	if parent.ChatID != msg.ChatID || parent.DeletedAt != nil {
+		return domainChat.ErrInvalidParent
+	}
+	return nil
+}
+
+// checkKind проверяет вид сообщения; объявления публикует только владелец чата.
+func (s *messageService) checkKind(ctx context.Context, msg *domainChat.Message) error {
+	switch msg.Kind {
+	case "":
+		msg.Kind = domainChat.KindMessage
+	case domainChat.KindMessage:
+	case domainChat.KindAnnouncement:
+		owner, err := s.chatRepo.IsOwner(ctx, msg.ChatID, msg.UserID)
+		if err != nil {
+			s.log.Errorf("checkKind: IsOwner: %v", err)
+			return ErrInternal
+		}
+		if !owner {
+			return domainChat.ErrPermissionDenied
+		}
+	default:
+		return domainChat.ErrInvalidKind
+	}
+	return nil
+}
+
+// notifyAnnouncement ставит в очередь в транзакции tx письмо об объявлении
+// каждому участнику чата, кроме автора. Удалённые и неактивные аккаунты пропускаются.
+func (s *messageService) notifyAnnouncement(ctx context.Context, tx *sql.Tx, msg *domainChat.Message) error {
+	participants, err := s.chatRepo.GetParticipants(ctx, msg.ChatID)
+	if err != nil {
+		s.log.Errorf("notifyAnnouncement: GetParticipants(%d): %v", msg.ChatID, err)
+		return ErrInternal
+	}
+	vars := map[string]interface{}{"Author": "", "Text": ""}
+	if msg.Text != nil {
+		vars["Text"] = *msg.Text
+	}
+	for _, p := range participants {
+		if p.UserID == msg.UserID {
+			vars["Author"] = p.FullName
+		}
+	}
+
+	ids := make([]int, 0, len(participants))
+	for _, p := range participants {
+		if p.UserID != msg.UserID {
+			ids = append(ids, p.UserID)
+		}
+	}
+	users, err := s.userRepo.ByIDs(ctx, ids)
+	if err != nil {
+		s.log.Errorf("notifyAnnouncement: ByIDs: %v", err)
+		return ErrInternal
+	}
+	for _, user := range users {
+		if !user.IsActive || user.DeletedAt != nil {
+			continue
+		}
+		if err := s.mailer.Send(ctx, tx, user.Email, user, 0, "announcement", domainEmail.Data{Vars: vars}); err != nil {
+			s.log.Errorf("notifyAnnouncement: mailer.Send(%d): %v", user.ID, err)
+			return ErrInternal
+		}
+	}
+	return nil
+}
+
+// createMessageTx создаёт сообщение в транзакции tx: упоминания сохраняются, а письма
+// об объявлении ставятся в очередь в той же транзакции. Перед фиксацией tx сообщению
+// нужно выдать версию через stampTx.
+func (s *messageService) createMessageTx(ctx context.Context, tx *sql.Tx, msg *domainChat.Message, mentioned []int) (int, error) {
+	id, err := s.repo.CreateMessageTx(ctx, tx, msg)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.repo.AddMentionsTx(ctx, tx, id, mentioned); err != nil {
+		return 0, err
 	}
+	if msg.Kind == domainChat.KindAnnouncement {
+		if err := s.notifyAnnouncement(ctx, tx, msg); err != nil {
+			return 0, err
+		}
+	}
+	return id, nil
+}
 
-	// Для каждого сообщения добираем список файлов
+// createMessage создаёт сообщение в собственной транзакции.
+func (s *messageService) createMessage(ctx context.Context, msg *domainChat.Message, mentioned []int) (int, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	id, err := s.createMessageTx(ctx, tx, msg, mentioned)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.stampTx(ctx, tx, msg, id); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// stampTx выдаёт новому сообщению id версию последним действием перед фиксацией tx:
+// версии чата блокируются до фиксации (см. TouchMessageTx), и блокировка не должна
+// держаться, пока пишутся файлы и ставятся в очередь письма. Ответ поднимает и версию
+// родителя, чтобы синхронизация вернула его с новыми ReplyCount и LastReplyAt.
+func (s *messageService) stampTx(ctx context.Context, tx *sql.Tx, msg *domainChat.Message, id int) error {
+	if err := s.repo.TouchMessageTx(ctx, tx, id); err != nil {
+		return err
+	}
+	return s.touchParentTx(ctx, tx, msg)
+}
+
+// touchParentTx поднимает версию родителя ответа msg: его сводка ответов изменилась.
+func (s *messageService) touchParentTx(ctx context.Context, tx *sql.Tx, msg *domainChat.Message) error {
+	if msg.ParentMessageID == nil {
+		return nil
+	}
+	return s.repo.TouchMessageTx(ctx, tx, *msg.ParentMessageID)
+}
+
+// attachFiles добирает для каждого сообщения список файлов.
+func (s *messageService) attachFiles(ctx context.Context, msgs []*domainChat.Message) {
 	for _, m := range msgs {
 		files, ferr := s.repo.MessageFileInfo(ctx, m.ID)
 		if ferr != nil {
@@ -59,8 +342,6 @@ func (s *messageService) Messages(ctx context.Context, chatID, limit, offset int
 			}
 		}
 	}
-
-	return msgs, nil
 }
 
 func (s *messageService) SendMessage(ctx context.Context, msg domainChat.Message) (int, error) {
@@ -80,7 +361,17 @@ func (s *messageService) SendMessage(ctx context.Context, msg domainChat.Message
 	if msg.ChatID <= 0 || msg.UserID <= 0 {
 		return 0, fmt.Errorf("неверные данные: chat_id или user_id отсутствуют")
 	}
-	id, err := s.repo.CreateMessage(ctx, &msg)
+	if err := s.checkParent(ctx, &msg); err != nil {
+		return 0, err
+	}
+	if err := s.checkKind(ctx, &msg); err != nil {
+		return 0, err
+	}
+	mentioned, err := s.resolveMentions(ctx, &msg)
+	if err != nil {
+		return 0, err
+	}
+	id, err := s.createMessage(ctx, &msg, mentioned)
 	if err != nil {
 		s.log.Errorf("Ошибка создания сообщения: %v", err)
 		return 0, fmt.Errorf("не удалось создать сообщение")
@@ -91,9 +382,11 @@ func (s *messageService) SendMessage(ctx context.Context, msg domainChat.Message
 	if err != nil {
 		s.log.Errorf("ошибка получения нового сообщения %d: %v", id, err)
 	} else if created != nil {
+		s.decorate(ctx, []*domainChat.Message{created}, created.UserID)
 		// broadcast в комнату chat_<chatID>
 		room := fmt.Sprintf("chat_%d", created.ChatID)
 		s.hub.Broadcast(room, "message:new", created)
+		s.notifyMentions(created, mentioned)
 	}
 
 	return id, nil
@@ -105,7 +398,7 @@ func (s *messageService) DeleteMessage(ctx context.Context, messageID int, reque
 		s.log.Errorf("Ошибка получения сообщения %d: %v", messageID, err)
 		return fmt.Errorf("не удалось удалить сообщение")
 	}
-	if msg == nil {
+	if msg == nil || msg.DeletedAt != nil {
 		return fmt.Errorf("сообщение не найдено")
 	}
 	isTeacher := ctx.Value("is_teacher")
@@ -131,13 +424,30 @@ func (s *messageService) DeleteMessage(ctx context.Context, messageID int, reque
 		return ErrInternal
 	}
 
-	// 2) удаляем записи в БД
+	// 2) сохраняем текст в историю, удаляем файлы и оставляем надгробие
+	if err = s.repo.AddRevisionTx(ctx, tx, &domainChat.MessageRevision{
+		MessageID: messageID,
+		Text:      msg.Text,
+		Action:    domainChat.RevisionDelete,
+		UserID:    &requesterID,
+	}); err != nil {
+		s.log.Error("AddRevisionTx:", err)
+		return ErrInternal
+	}
 	if err = s.repo.DeleteMessageFilesTx(ctx, tx, messageID); err != nil {
 		s.log.Error("DeleteMessageFilesTx:", err)
 		return ErrInternal
 	}
-	if err = s.repo.DeleteMessageTx(ctx, tx, messageID); err != nil {
-		s.log.Error("DeleteMessageTx:", err)
+	if err = s.repo.SoftDeleteMessageTx(ctx, tx, messageID, requesterID); err != nil {
+		s.log.Error("SoftDeleteMessageTx:", err)
+		return ErrInternal
+	}
+	if err = s.touchParentTx(ctx, tx, msg); err != nil {
+		s.log.Error("touchParentTx:", err)
+		return ErrInternal
+	}
+
+	if err = tx.Commit(); err != nil {
 		return ErrInternal
 	}
 
@@ -146,54 +456,199 @@ func (s *messageService) DeleteMessage(ctx context.Context, messageID int, reque
 		os.Remove(f.FileURL) // игнорируем ошибку
 	}
 
-	if err = tx.Commit(); err != nil {
+	// после успешного удаления
+	event := domainChat.MessageDeletion{ID: messageID, ChatID: msg.ChatID, DeletedBy: requesterID, DeletedAt: time.Now()}
+	if deleted, err := s.repo.ByID(ctx, messageID); err != nil {
+		s.log.Errorf("ошибка получения удалённого сообщения %d: %v", messageID, err)
+	} else if deleted.DeletedAt != nil {
+		event.DeletedAt = *deleted.DeletedAt
+		event.Version = deleted.Version
+	}
+	room := fmt.Sprintf("chat_%d", msg.ChatID)
+	s.hub.Broadcast(room, "message:delete", event)
+
+	return nil
+}
+
+// Revisions возвращает историю правок и удаления сообщения. Доступна только владельцу чата.
+func (s *messageService) Revisions(ctx context.Context, chatID, messageID, requesterID int) ([]*domainChat.MessageRevision, error) {
+	owner, err := s.chatRepo.IsOwner(ctx, chatID, requesterID)
+	if err != nil {
+		s.log.Errorf("Revisions: IsOwner: %v", err)
+		return nil, ErrInternal
+	}
+	if !owner {
+		return nil, domainChat.ErrPermissionDenied
+	}
+	msg, err := s.repo.ByID(ctx, messageID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domainChat.ErrNotFound
+	}
+	if err != nil {
+		s.log.Errorf("Revisions: ByID: %v", err)
+		return nil, ErrInternal
+	}
+	if msg.ChatID != chatID {
+		return nil, domainChat.ErrNotFound
+	}
+
+	revs, err := s.repo.Revisions(ctx, messageID)
+	if err != nil {
+		s.log.Errorf("Revisions(%d): %v", messageID, err)
+		return nil, ErrInternal
+	}
+	return revs, nil
+}
+
+// PurgeMessage безвозвратно стирает содержимое сообщения вместе с историей и файлами,
+// оставляя надгробие для синхронизации. Доступно модераторам учреждения, которому принадлежит чат.
+func (s *messageService) PurgeMessage(ctx context.Context, actor domainUser.Actor, messageID int) error {
+	msg, err := s.repo.ByID(ctx, messageID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domainChat.ErrNotFound
+	}
+	if err != nil {
+		s.log.Errorf("PurgeMessage: ByID: %v", err)
 		return ErrInternal
 	}
+	institutionID, err := s.chatRepo.InstitutionID(ctx, msg.ChatID)
+	if err != nil {
+		s.log.Errorf("PurgeMessage: InstitutionID: %v", err)
+		return ErrInternal
+	}
+	if !actor.Role.Can(domainUser.PermMessagesModerate) || !actor.Manages(institutionID) {
+		return domainChat.ErrPermissionDenied
+	}
 
-	// после успешного удаления
-	room := fmt.Sprintf("chat_%d", msg.ChatID)
-	s.hub.Broadcast(room, "message:delete", map[string]int{"id": messageID})
+	files, err := s.repo.MessageFileInfo(ctx, messageID)
+	if err != nil {
+		s.log.Errorf("PurgeMessage: MessageFileInfo: %v", err)
+		return ErrInternal
+	}
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		s.log.Errorf("PurgeMessage: begin tx: %v", err)
+		return ErrInternal
+	}
+	defer tx.Rollback()
+	// От сообщения остаётся надгробие: без него синхронизация не узнала бы об удалении
+	deletedAt, version, err := s.repo.PurgeMessageTx(ctx, tx, messageID, actor.ID)
+	if err != nil {
+		s.log.Errorf("PurgeMessage: PurgeMessageTx: %v", err)
+		return ErrInternal
+	}
+	if err := s.touchParentTx(ctx, tx, msg); err != nil {
+		s.log.Errorf("PurgeMessage: touchParentTx: %v", err)
+		return ErrInternal
+	}
+	if err := tx.Commit(); err != nil {
+		s.log.Errorf("PurgeMessage: commit: %v", err)
+		return ErrInternal
+	}
+	for _, f := range files {
+		if err := os.Remove(f.FileURL); err != nil && !os.IsNotExist(err) {
+			s.log.Errorf("PurgeMessage: удаление файла %s: %v", f.FileURL, err)
+		}
+	}
 
+	s.audit.Record(ctx, actor, institutionID, domainAudit.EntityMessage, messageID, domainAudit.ActionPurge, map[string]int{
+		"chat_id": msg.ChatID,
+		"user_id": msg.UserID,
+	})
+	room := fmt.Sprintf("chat_%d", msg.ChatID)
+	s.hub.Broadcast(room, "message:delete", domainChat.MessageDeletion{
+		ID:        messageID,
+		ChatID:    msg.ChatID,
+		DeletedBy: actor.ID,
+		DeletedAt: deletedAt,
+		Version:   version,
+		Purged:    true,
+	})
 	return nil
 }
 
 func (s *messageService) ReplyMessage(ctx context.Context, parentMessageID int, msg domainChat.Message) (int, error) {
 	// Устанавливаем parent_message_id
 	msg.ParentMessageID = &parentMessageID
-	id, err := s.repo.CreateMessage(ctx, &msg)
+	if err := s.checkParent(ctx, &msg); err != nil {
+		return 0, err
+	}
+	if err := s.checkKind(ctx, &msg); err != nil {
+		return 0, err
+	}
+	mentioned, err := s.resolveMentions(ctx, &msg)
+	if err != nil {
+		return 0, err
+	}
+	id, err := s.createMessage(ctx, &msg, mentioned)
 	if err != nil {
 		s.log.Errorf("Ошибка создания ответа на сообщение %d: %v", parentMessageID, err)
 		return 0, fmt.Errorf("не удалось создать ответ")
 	}
 	reply, err := s.repo.ByID(ctx, id)
 	if err == nil && reply != nil {
+		s.decorate(ctx, []*domainChat.Message{reply}, reply.UserID)
 		room := fmt.Sprintf("chat_%d", reply.ChatID)
 		s.hub.Broadcast(room, "message:new", reply)
+		s.notifyMentions(reply, mentioned)
 	}
 
 	return id, nil
 }
 
-func (s *messageService) SearchMessages(ctx context.Context, chatID int, query string, limit, offset int) ([]*domainChat.Message, error) {
-	msgs, err := s.repo.SearchMessages(ctx, chatID, query, limit, offset)
+// SearchMessages ищет сообщения в одном чате; результаты упорядочены по релевантности.
+func (s *messageService) SearchMessages(ctx context.Context, chatID, userID int, query string, limit, offset int) ([]*domainChat.Message, error) {
+	hits, err := s.Search(ctx, domainChat.SearchFilter{
+		UserID: userID,
+		ChatID: chatID,
+		Query:  query,
+		Limit:  limit,
+		Offset: offset,
+	})
 	if err != nil {
-		s.log.Errorf("Ошибка поиска сообщений в чате %d: %v", chatID, err)
-		return nil, fmt.Errorf("не удалось найти сообщения")
+		return nil, err
 	}
-	for _, m := range msgs {
-		files, ferr := s.repo.MessageFileInfo(ctx, m.ID)
-		if ferr != nil {
-			s.log.Errorf("MessageFileInfo(%d): %v", m.ID, ferr)
-		} else {
-			m.Files = make([]domainChat.FileInfo, len(files))
-			for i, f := range files {
-				m.Files[i] = *f
-			}
-		}
+	msgs := make([]*domainChat.Message, len(hits))
+	for i, h := range hits {
+		msgs[i] = h.Message
 	}
 	return msgs, nil
 }
 
+// Search ищет сообщения во всех чатах пользователя с учётом русской морфологии
+// и возвращает их с подсвеченными фрагментами текста.
+func (s *messageService) Search(ctx context.Context, f domainChat.SearchFilter) ([]*domainChat.SearchHit, error) {
+	f.Query = strings.TrimSpace(f.Query)
+	if f.Query == "" {
+		return nil, domainChat.ErrEmptyQuery
+	}
+	f.Limit = pageSize(f.Limit)
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+	hits, err := s.repo.Search(ctx, f)
+	if err != nil {
+		s.log.Errorf("Ошибка поиска сообщений пользователя %d: %v", f.UserID, err)
+		return nil, fmt.Errorf("не удалось найти сообщения")
+	}
+
+	msgs := make([]*domainChat.Message, len(hits))
+	for i, h := range hits {
+		h.Headline = highlight(h.Headline)
+		msgs[i] = h.Message
+	}
+	s.decorate(ctx, msgs, f.UserID)
+	return hits, nil
+}
+
+// highlight экранирует фрагмент текста и размечает найденные слова тегом <mark>.
+func highlight(headline string) string {
+	return strings.NewReplacer(
+		domainChat.HighlightStart, "<mark>",
+		domainChat.HighlightStop, "</mark>",
+	).Replace(html.EscapeString(headline))
+}
+
 func (s *messageService) MessageFiles(ctx context.Context, messageID int) ([]*domainChat.FileInfo, error) {
 	files, err := s.repo.MessageFileInfo(ctx, messageID)
 	if err != nil {
@@ -213,20 +668,26 @@ func (s *messageService) SendMessageWithFiles(
 	if msg.Text == nil && len(files) == 0 {
 		return 0, errors.New("сообщение должно содержать текст или файл")
 	}
+	if err := s.checkParent(ctx, &msg); err != nil {
+		return 0, err
+	}
+	if err := s.checkKind(ctx, &msg); err != nil {
+		return 0, err
+	}
+	mentioned, err := s.resolveMentions(ctx, &msg)
+	if err != nil {
+		return 0, err
+	}
 	// Начинаем транзакцию через репозиторий
 	tx, err := s.repo.BeginTx(ctx)
 	if err != nil {
 		s.log.Error("tx begin:", err)
 		return 0, errors.New("unexpected error")
 	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
+	defer tx.Rollback()
 
 	// 1) Создаём сообщение
-	msgID, err := s.repo.CreateMessageTx(ctx, tx, &msg)
+	msgID, err := s.createMessageTx(ctx, tx, &msg, mentioned)
 	if err != nil {
 		s.log.Error("create msg:", err)
 		return 0, errors.New("failed to save message")
@@ -251,6 +712,10 @@ func (s *messageService) SendMessageWithFiles(
 			FileURL: dst,
 		})
 	}
+	if err = s.stampTx(ctx, tx, &msg, msgID); err != nil {
+		s.log.Error("stamp msg:", err)
+		return 0, errors.New("failed to save message")
+	}
 
 	if err = tx.Commit(); err != nil {
 		s.log.Error("tx commit:", err)
@@ -263,11 +728,21 @@ func (s *messageService) SendMessageWithFiles(
 		Text:            msg.Text,
 		MessageGroupID:  msg.MessageGroupID,
 		ParentMessageID: msg.ParentMessageID,
+		Kind:            msg.Kind,
 		CreatedAt:       time.Now(),
 		Files:           attachedFiles,
 	}
+	// Версия нужна клиенту как курсор синхронизации
+	if created, err := s.repo.ByID(ctx, msgID); err != nil {
+		s.log.Errorf("ошибка получения нового сообщения %d: %v", msgID, err)
+	} else {
+		outgoing.CreatedAt = created.CreatedAt
+		outgoing.Version = created.Version
+	}
+	s.attachThreads(ctx, []*domainChat.Message{&outgoing})
 	room := fmt.Sprintf("chat_%d", msg.ChatID)
 	s.hub.Broadcast(room, "message:new", outgoing)
+	s.notifyMentions(&outgoing, mentioned)
 	return msgID, nil
 }
 
@@ -282,7 +757,7 @@ func (s *messageService) UpdateMessage(
 		s.log.Errorf("UpdateMessage: fetch orig: %v", err)
 		return nil, ErrInternal
 	}
-	if orig == nil {
+	if orig == nil || orig.DeletedAt != nil {
 		return nil, domainChat.ErrNotFound
 	}
 	// 2) Проверить права: автор или преподаватель
@@ -312,7 +787,16 @@ func (s *messageService) UpdateMessage(
 			tx.Rollback()
 		}
 	}()
-	// 5) Обновить
+	// 5) Сохранить прежний текст и обновить
+	if err = s.repo.AddRevisionTx(ctx, tx, &domainChat.MessageRevision{
+		MessageID: messageID,
+		Text:      orig.Text,
+		Action:    domainChat.RevisionEdit,
+		UserID:    &requesterID,
+	}); err != nil {
+		s.log.Errorf("UpdateMessage: add revision: %v", err)
+		return nil, ErrInternal
+	}
 	if err = s.repo.UpdateMessageTx(ctx, tx, messageID, text); err != nil {
 		s.log.Errorf("UpdateMessage: exec update: %v", err)
 		return nil, ErrInternal
@@ -0,0 +1,88 @@
+package chat
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	domainChat "EduSync/internal/domain/chat"
+)
+
+// Pins возвращает закреплённые сообщения чата, последние закреплённые первыми.
+func (s *messageService) Pins(ctx context.Context, chatID, userID int) ([]*domainChat.Message, error) {
+	msgs, err := s.repo.Pins(ctx, chatID)
+	if err != nil {
+		s.log.Errorf("Pins(%d): %v", chatID, err)
+		return nil, ErrInternal
+	}
+	if msgs == nil {
+		msgs = []*domainChat.Message{}
+	}
+	s.decorate(ctx, msgs, userID)
+	return msgs, nil
+}
+
+// PinMessage закрепляет сообщение. Доступно только владельцу чата;
+// повторное закрепление ничего не меняет.
+func (s *messageService) PinMessage(ctx context.Context, chatID, messageID, userID int) (*domainChat.Message, error) {
+	return s.setPinned(ctx, chatID, messageID, userID, true)
+}
+
+// UnpinMessage снимает закрепление. Доступно только владельцу чата.
+func (s *messageService) UnpinMessage(ctx context.Context, chatID, messageID, userID int) (*domainChat.Message, error) {
+	return s.setPinned(ctx, chatID, messageID, userID, false)
+}
+
+// setPinned меняет закрепление и рассылает message:pinned в комнату чата, если что-то изменилось.
+func (s *messageService) setPinned(ctx context.Context, chatID, messageID, userID int, pin bool) (*domainChat.Message, error) {
+	owner, err := s.chatRepo.IsOwner(ctx, chatID, userID)
+	if err != nil {
+		s.log.Errorf("setPinned: IsOwner: %v", err)
+		return nil, ErrInternal
+	}
+	if !owner {
+		return nil, domainChat.ErrPermissionDenied
+	}
+	msg, err := s.repo.ByID(ctx, messageID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domainChat.ErrNotFound
+	}
+	if err != nil {
+		s.log.Errorf("setPinned: ByID(%d): %v", messageID, err)
+		return nil, ErrInternal
+	}
+	if msg.ChatID != chatID || msg.DeletedAt != nil {
+		return nil, domainChat.ErrNotFound
+	}
+
+	var changed bool
+	if pin {
+		changed, err = s.repo.Pin(ctx, messageID, userID)
+	} else {
+		changed, err = s.repo.Unpin(ctx, messageID)
+	}
+	if err != nil {
+		s.log.Errorf("setPinned(%d): %v", messageID, err)
+		return nil, ErrInternal
+	}
+	if changed {
+		if msg, err = s.repo.ByID(ctx, messageID); err != nil {
+			s.log.Errorf("setPinned: ByID(%d): %v", messageID, err)
+			return nil, ErrInternal
+		}
+	}
+	s.decorate(ctx, []*domainChat.Message{msg}, userID)
+
+	if changed {
+		room := fmt.Sprintf("chat_%d", chatID)
+		s.hub.Broadcast(room, "message:pinned", domainChat.PinEvent{
+			MessageID: messageID,
+			ChatID:    chatID,
+			Pinned:    pin,
+			UserID:    userID,
+			Message:   msg,
+		})
+	}
+	return msg, nil
+}
@@ -0,0 +1,120 @@
+package chat
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	domainChat "EduSync/internal/domain/chat"
+)
+
+// Действия события message:reaction.
+const (
+	reactionAdd    = "add"
+	reactionRemove = "remove"
+)
+
+// AddReaction ставит реакцию на сообщение и возвращает обновлённую сводку реакций.
+// Повторная реакция тем же эмодзи ничего не меняет.
+func (s *messageService) AddReaction(ctx context.Context, chatID, messageID, userID int, emoji string) ([]domainChat.Reaction, error) {
+	return s.react(ctx, chatID, messageID, userID, emoji, reactionAdd)
+}
+
+// RemoveReaction снимает реакцию пользователя и возвращает обновлённую сводку реакций.
+func (s *messageService) RemoveReaction(ctx context.Context, chatID, messageID, userID int, emoji string) ([]domainChat.Reaction, error) {
+	return s.react(ctx, chatID, messageID, userID, emoji, reactionRemove)
+}
+
+// react меняет реакцию, поднимает версию сообщения для синхронизации
+// и рассылает message:reaction в комнату чата, если что-то изменилось.
+func (s *messageService) react(ctx context.Context, chatID, messageID, userID int, emoji, action string) ([]domainChat.Reaction, error) {
+	if !domainChat.ValidReaction(emoji) {
+		return nil, domainChat.ErrInvalidReaction
+	}
+	msg, err := s.repo.ByID(ctx, messageID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domainChat.ErrNotFound
+	}
+	if err != nil {
+		s.log.Errorf("react: ByID(%d): %v", messageID, err)
+		return nil, ErrInternal
+	}
+	if msg.ChatID != chatID || msg.DeletedAt != nil {
+		return nil, domainChat.ErrNotFound
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		s.log.Errorf("react: begin tx: %v", err)
+		return nil, ErrInternal
+	}
+	defer tx.Rollback()
+
+	var changed bool
+	if action == reactionAdd {
+		changed, err = s.repo.AddReactionTx(ctx, tx, messageID, userID, emoji)
+	} else {
+		changed, err = s.repo.RemoveReactionTx(ctx, tx, messageID, userID, emoji)
+	}
+	if err != nil {
+		s.log.Errorf("react: %s реакции на сообщение %d: %v", action, messageID, err)
+		return nil, ErrInternal
+	}
+	if changed {
+		if err := s.repo.TouchMessageTx(ctx, tx, messageID); err != nil {
+			s.log.Errorf("react: TouchMessageTx(%d): %v", messageID, err)
+			return nil, ErrInternal
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		s.log.Errorf("react: commit: %v", err)
+		return nil, ErrInternal
+	}
+
+	if changed {
+		count, err := s.repo.ReactionCount(ctx, messageID, emoji)
+		if err != nil {
+			s.log.Errorf("react: ReactionCount(%d): %v", messageID, err)
+		} else {
+			room := fmt.Sprintf("chat_%d", chatID)
+			s.hub.Broadcast(room, "message:reaction", domainChat.ReactionEvent{
+				MessageID: messageID,
+				ChatID:    chatID,
+				UserID:    userID,
+				Emoji:     emoji,
+				Action:    action,
+				Count:     count,
+			})
+		}
+	}
+
+	reactions, err := s.repo.Reactions(ctx, []int{messageID}, userID)
+	if err != nil {
+		s.log.Errorf("react: Reactions(%d): %v", messageID, err)
+		return nil, ErrInternal
+	}
+	if reactions[messageID] == nil {
+		return []domainChat.Reaction{}, nil
+	}
+	return reactions[messageID], nil
+}
+
+// attachReactions заполняет Reactions; ReactedByMe отмечается для viewerID.
+func (s *messageService) attachReactions(ctx context.Context, msgs []*domainChat.Message, viewerID int) {
+	if len(msgs) == 0 {
+		return
+	}
+	ids := make([]int, len(msgs))
+	for i, m := range msgs {
+		ids[i] = m.ID
+	}
+	reactions, err := s.repo.Reactions(ctx, ids, viewerID)
+	if err != nil {
+		s.log.Errorf("Reactions: %v", err)
+		return
+	}
+	for _, m := range msgs {
+		m.Reactions = reactions[m.ID]
+	}
+}
@@ -1,51 +1,53 @@
 package email
 
 import (
+	domainEmail "EduSync/internal/domain/email"
+	domainUser "EduSync/internal/domain/user"
 	"EduSync/internal/repository"
 	"EduSync/internal/service"
+	"EduSync/internal/util"
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"golang.org/x/crypto/bcrypt"
-	"math/rand"
 	"time"
 )
 
+// errInvalidCode — общее сообщение, чтобы по ответу нельзя было понять, существует ли пользователь.
+var errInvalidCode = errors.New("код неверен или просрочен")
+
 type confirmationService struct {
-	repo      repository.EmailConfirmationsRepository
-	userRepo  repository.UserRepository
-	emailSvc  service.EmailService
-	throttle  time.Duration
-	codeTTL   time.Duration
-	templates map[string]string
-	baseURL   string
+	repo     repository.EmailConfirmationsRepository
+	userRepo repository.UserRepository
+	mailer   service.Mailer
+	throttle time.Duration
+	codeTTL  time.Duration
+	attempts int // неверных попыток до аннулирования кода
+	baseURL  string
 }
 
+// NewConfirmationService создаёт сервис кодов подтверждения. Письмо для действия
+// берётся из шаблона с тем же именем: register, reset_password, delete_account,
+// two_factor_reset (запрашивается со второго шага входа, когда нет ни приложения,
+// ни кодов восстановления).
 func NewConfirmationService(
 	repo repository.EmailConfirmationsRepository,
 	userRepo repository.UserRepository,
-	emailSvc service.EmailService,
+	mailer service.Mailer,
 	baseURL string,
 ) service.ConfirmationService {
 	return &confirmationService{
 		repo:     repo,
 		userRepo: userRepo,
-		emailSvc: emailSvc,
+		mailer:   mailer,
 		throttle: 2 * time.Minute,
 		codeTTL:  2 * time.Hour,
+		attempts: 5,
 		baseURL:  baseURL,
-		templates: map[string]string{
-			"register":       "Подтверждение регистрации",
-			"reset_password": "Сброс пароля",
-			"delete_account": "Подтверждение удаления аккаунта",
-		},
 	}
 }
 
-func (s *confirmationService) genCode() string {
-	n := rand.Intn(1e6)
-	return fmt.Sprintf("%06d", n)
-}
-
 func (s *confirmationService) RequestCode(ctx context.Context, email, action string) error {
 	user, err := s.userRepo.ByEmail(ctx, email)
 	if err != nil {
@@ -61,80 +63,95 @@ func (s *confirmationService) RequestCode(ctx context.Context, email, action str
 	if !ok {
 		return fmt.Errorf("код уже отправлялся недавно")
 	}
-	code := s.genCode()
+
+	tx, err := s.userRepo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := s.IssueCode(ctx, tx, user, 0, action); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// IssueCode создаёт код и ставит письмо с ним в очередь в транзакции tx.
+// institutionID нужен для оформления письма, если пользователь создаётся в той же транзакции.
+func (s *confirmationService) IssueCode(ctx context.Context, tx *sql.Tx, user *domainUser.User, institutionID int, action string) error {
+	code, err := util.NewEmailCode()
+	if err != nil {
+		return err
+	}
 	expires := time.Now().Add(s.codeTTL)
-	if err := s.repo.Create(ctx, user.ID, action, code, expires); err != nil {
+	if err := s.repo.Create(ctx, tx, user.ID, action, code, expires); err != nil {
 		return err
 	}
-	subj := s.templates[action]
 
-	var body string
+	data := domainEmail.Data{Code: code, ExpiresIn: int(s.codeTTL.Hours())}
 	if action == "register" {
-		// Формируем ссылку на активацию
-		activationLink := fmt.Sprintf(
+		// Для регистрации вместо кода — ссылка на активацию
+		data.Link = fmt.Sprintf(
 			"%s/api/confirm/activate?user_id=%d&code=%s",
 			s.baseURL, user.ID, code,
 		)
-		body = fmt.Sprintf(
-			"Чтобы активировать аккаунт, перейдите по ссылке:\n\n%s\n\nСсылка действительна %d часов.",
-			activationLink, int(s.codeTTL.Hours()),
-		)
-	} else {
-		// Для остальных действий остаётся код
-		body = fmt.Sprintf("Ваш код подтверждения: %s\nОн действителен %d часов.", code, int(s.codeTTL.Hours()))
 	}
-
-	return s.emailSvc.SendCode(ctx, email, subj, body)
+	return s.mailer.Send(ctx, tx, user.Email, user, institutionID, action, data)
 }
 
-func (s *confirmationService) VerifyCode(ctx context.Context, action, code string, userID *int) error {
-	if userID == nil {
-		newUserID, err := s.repo.GetByActionCode(ctx, action, code)
-		if err != nil {
-			return err
-		}
-		userID = &newUserID
-	}
-
-	valid, err := s.repo.GetValid(ctx, *userID, action, code)
+// VerifyCode проверяет код пользователя и выполняет действие.
+func (s *confirmationService) VerifyCode(ctx context.Context, userID int, action, code string) error {
+	valid, err := s.repo.Verify(ctx, userID, action, code, s.attempts)
 	if err != nil {
 		return err
 	}
 	if !valid {
-		return fmt.Errorf("код неверен или просрочен")
+		return errInvalidCode
 	}
 
 	if action != "reset_password" {
-		if err := s.repo.MarkUsed(ctx, *userID, action, code); err != nil {
+		if err := s.repo.MarkUsed(ctx, userID, action, code); err != nil {
 			return err
 		}
 	}
 	switch action {
 	case "register":
-		if err := s.userRepo.Activate(ctx, *userID); err != nil {
+		if err := s.userRepo.Activate(ctx, userID); err != nil {
 			return fmt.Errorf("не удалось активировать пользователя")
 		}
 	}
 	return nil
 }
 
-func (s *confirmationService) UserIDByCode(ctx context.Context, action, code string) (int, error) {
-	// допустим, в email_confirmations есть user_id
-	userID, err := s.repo.GetByActionCode(ctx, action, code)
+// VerifyEmailCode проверяет код, отправленный на email.
+func (s *confirmationService) VerifyEmailCode(ctx context.Context, email, action, code string) error {
+	user, err := s.userRepo.ByEmail(ctx, email)
 	if err != nil {
-		return 0, err
+		return err
+	}
+	if user == nil {
+		return errInvalidCode
 	}
-	return userID, nil
+	return s.VerifyCode(ctx, user.ID, action, code)
 }
 
-func (s *confirmationService) ResetPassword(ctx context.Context, code, newPassword string) error {
-	// 1) найти userID по коду
-	userID, err := s.repo.GetByActionCode(ctx, "reset_password", code)
+func (s *confirmationService) ResetPassword(ctx context.Context, email, code, newPassword string) error {
+	// 1) проверить код пользователя
+	user, err := s.userRepo.ByEmail(ctx, email)
 	if err != nil {
-		return fmt.Errorf("invalid or expired code")
+		return err
+	}
+	if user == nil {
+		return errInvalidCode
+	}
+	valid, err := s.repo.Verify(ctx, user.ID, "reset_password", code, s.attempts)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return errInvalidCode
 	}
 	// 2) пометить код использованным
-	if err := s.repo.MarkUsed(ctx, userID, "reset_password", code); err != nil {
+	if err := s.repo.MarkUsed(ctx, user.ID, "reset_password", code); err != nil {
 		return err
 	}
 	// 3) обновить пароль
@@ -142,5 +159,5 @@ func (s *confirmationService) ResetPassword(ctx context.Context, code, newPasswo
 	if err != nil {
 		return err
 	}
-	return s.userRepo.UpdatePassword(ctx, userID, string(hashed))
+	return s.userRepo.UpdatePassword(ctx, user.ID, string(hashed))
 }
@@ -0,0 +1,130 @@
+package email
+
+import (
+	domainEmail "EduSync/internal/domain/email"
+	domainUser "EduSync/internal/domain/user"
+	"EduSync/internal/repository"
+	"EduSync/internal/service"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Оформление писем, если у учреждения нет своего.
+const (
+	defaultBrandName  = "EduSync"
+	defaultBrandColor = "#1a73e8"
+)
+
+type mailer struct {
+	renderer        *Renderer
+	outboxRepo      repository.EmailOutboxRepository
+	studentRepo     repository.StudentRepository
+	teacherRepo     repository.TeacherRepository
+	institutionRepo repository.InstitutionRepository
+	log             *logrus.Logger
+}
+
+// NewMailer создаёт сервис писем по шаблонам. Письма ставятся в очередь,
+// отправляет их OutboxService.
+func NewMailer(
+	renderer *Renderer,
+	outboxRepo repository.EmailOutboxRepository,
+	studentRepo repository.StudentRepository,
+	teacherRepo repository.TeacherRepository,
+	institutionRepo repository.InstitutionRepository,
+	log *logrus.Logger,
+) service.Mailer {
+	return &mailer{
+		renderer:        renderer,
+		outboxRepo:      outboxRepo,
+		studentRepo:     studentRepo,
+		teacherRepo:     teacherRepo,
+		institutionRepo: institutionRepo,
+		log:             log,
+	}
+}
+
+// Send собирает письмо template на языке пользователя с оформлением его учреждения
+// и ставит в очередь в транзакции tx на адрес to (он может отличаться от текущего
+// адреса пользователя). institutionID = 0 — учреждение определяется по пользователю;
+// его нужно передать, если пользователь создаётся в той же транзакции.
+func (m *mailer) Send(ctx context.Context, tx *sql.Tx, to string, user *domainUser.User, institutionID int, template string, data domainEmail.Data) error {
+	if institutionID == 0 {
+		institutionID = m.institutionOf(ctx, user)
+	}
+	data.FullName = user.FullName
+	data.Brand = m.branding(ctx, institutionID)
+
+	msg, err := m.renderer.Render(template, user.Locale, data)
+	if err != nil {
+		m.log.Errorf("Ошибка сборки письма %s: %v", template, err)
+		return err
+	}
+	out := &domainEmail.OutboxMessage{
+		Template: template,
+		To:       to,
+		Subject:  msg.Subject,
+		Text:     msg.Text,
+		HTML:     msg.HTML,
+	}
+	if user.ID != 0 {
+		out.UserID = &user.ID
+	}
+	if institutionID != 0 {
+		out.InstitutionID = &institutionID
+	}
+	if _, err := m.outboxRepo.Add(ctx, tx, out); err != nil {
+		m.log.Errorf("outboxRepo.Add: %v", err)
+		return fmt.Errorf("не удалось поставить письмо в очередь")
+	}
+	return nil
+}
+
+// institutionOf возвращает учреждение пользователя или 0, если его не удалось определить.
+func (m *mailer) institutionOf(ctx context.Context, user *domainUser.User) int {
+	if user.IsTeacher {
+		t, err := m.teacherRepo.ByUserID(ctx, user.ID)
+		if err != nil {
+			m.log.Errorf("teacherRepo.ByUserID: %v", err)
+		}
+		if t != nil {
+			return t.InstitutionID
+		}
+		return 0
+	}
+	st, err := m.studentRepo.ByUserID(ctx, user.ID)
+	if err != nil {
+		m.log.Errorf("studentRepo.ByUserID: %v", err)
+	}
+	if st != nil {
+		return st.InstitutionID
+	}
+	return 0
+}
+
+// branding возвращает оформление учреждения. Ошибки не мешают отправке:
+// письмо уйдёт с оформлением EduSync.
+func (m *mailer) branding(ctx context.Context, institutionID int) domainEmail.Branding {
+	brand := domainEmail.Branding{Name: defaultBrandName, Color: defaultBrandColor}
+	if institutionID == 0 {
+		return brand
+	}
+
+	inst, err := m.institutionRepo.ByID(ctx, institutionID)
+	if err != nil {
+		m.log.Errorf("institutionRepo.ByID: %v", err)
+		return brand
+	}
+	if inst == nil {
+		return brand
+	}
+	brand.Name = inst.Name
+	brand.LogoURL = inst.LogoURL
+	if inst.BrandColor != "" {
+		brand.Color = inst.BrandColor
+	}
+	return brand
+}
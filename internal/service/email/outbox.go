@@ -0,0 +1,124 @@
+package email
+
+import (
+	domainEmail "EduSync/internal/domain/email"
+	domainUser "EduSync/internal/domain/user"
+	"EduSync/internal/repository"
+	"EduSync/internal/service"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Параметры доставки писем из очереди.
+const (
+	outboxBatch       = 50
+	outboxLease       = 5 * time.Minute // сколько письмо считается отправляемым одним обработчиком
+	outboxMaxAttempts = 8
+	outboxBaseDelay   = time.Minute // задержка перед второй попыткой, дальше удваивается
+	outboxMaxDelay    = 6 * time.Hour
+	outboxSendTimeout = time.Minute
+
+	defaultOutboxLimit = 50
+	maxOutboxLimit     = 200
+)
+
+type outboxService struct {
+	repo      repository.EmailOutboxRepository
+	transport service.EmailService
+	log       *logrus.Logger
+}
+
+// NewOutboxService создаёт сервис доставки писем из очереди через transport.
+func NewOutboxService(repo repository.EmailOutboxRepository, transport service.EmailService, log *logrus.Logger) service.OutboxService {
+	return &outboxService{repo: repo, transport: transport, log: log}
+}
+
+// StartWorker запускает фоновую отправку писем из очереди.
+func (s *outboxService) StartWorker(interval time.Duration) {
+	go func() {
+		for {
+			s.deliver(context.Background())
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// deliver отправляет письма, которым подошло время, пока очередь не опустеет.
+func (s *outboxService) deliver(ctx context.Context) {
+	for {
+		batch, err := s.repo.Claim(ctx, outboxBatch, outboxLease)
+		if err != nil {
+			s.log.Errorf("Ошибка выборки писем из очереди: %v", err)
+			return
+		}
+		for _, m := range batch {
+			s.send(ctx, m)
+		}
+		if len(batch) < outboxBatch {
+			return
+		}
+	}
+}
+
+func (s *outboxService) send(ctx context.Context, m *domainEmail.OutboxMessage) {
+	sendCtx, cancel := context.WithTimeout(ctx, outboxSendTimeout)
+	err := s.transport.Send(sendCtx, m.Message())
+	cancel()
+
+	if err == nil {
+		if err := s.repo.MarkSent(ctx, m.ID); err != nil {
+			s.log.Errorf("Письмо %d отправлено, но не отмечено: %v", m.ID, err)
+		}
+		return
+	}
+
+	if m.Attempts >= outboxMaxAttempts {
+		s.log.Errorf("Письмо %d (%s) не доставлено после %d попыток: %v", m.ID, m.Template, m.Attempts, err)
+		if err := s.repo.MarkFailed(ctx, m.ID, err.Error()); err != nil {
+			s.log.Errorf("outboxRepo.MarkFailed: %v", err)
+		}
+		return
+	}
+	delay := retryDelay(m.Attempts)
+	s.log.Warnf("Письмо %d (%s) не отправлено, попытка %d, повтор через %s: %v", m.ID, m.Template, m.Attempts, delay, err)
+	if err := s.repo.MarkRetry(ctx, m.ID, time.Now().Add(delay), err.Error()); err != nil {
+		s.log.Errorf("outboxRepo.MarkRetry: %v", err)
+	}
+}
+
+// retryDelay возвращает задержку после неудачной попытки с номером attempt (с единицы).
+func retryDelay(attempt int) time.Duration {
+	d := outboxBaseDelay
+	for i := 1; i < attempt && d < outboxMaxDelay; i++ {
+		d *= 2
+	}
+	if d > outboxMaxDelay {
+		d = outboxMaxDelay
+	}
+	return d
+}
+
+// List возвращает письма из очереди. Администратор учреждения видит только письма своего учреждения.
+func (s *outboxService) List(ctx context.Context, actor domainUser.Actor, f domainEmail.OutboxFilter) ([]*domainEmail.OutboxMessage, error) {
+	if !actor.Role.Can(domainUser.PermSystemManage) {
+		f.InstitutionID = actor.InstitutionID
+	}
+	if f.Limit <= 0 {
+		f.Limit = defaultOutboxLimit
+	}
+	if f.Limit > maxOutboxLimit {
+		f.Limit = maxOutboxLimit
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+	messages, err := s.repo.List(ctx, f)
+	if err != nil {
+		s.log.Errorf("Ошибка получения очереди писем: %v", err)
+		return nil, fmt.Errorf("не удалось получить очередь писем")
+	}
+	return messages, nil
+}
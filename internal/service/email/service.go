@@ -1,6 +1,7 @@
 package email
 
 import (
+	domainEmail "EduSync/internal/domain/email"
 	"EduSync/internal/service"
 	"context"
 	"fmt"
@@ -19,12 +20,16 @@ func NewSMTPEmailService(host string, port int, username, password, from string)
 	return &smtpEmailService{host, port, username, password, from}
 }
 
-func (s *smtpEmailService) SendCode(ctx context.Context, toEmail, subject, body string) error {
+// Send отправляет письмо; HTML-версия идёт альтернативой к текстовой.
+func (s *smtpEmailService) Send(ctx context.Context, msg *domainEmail.Message) error {
 	m := gomail.NewMessage()
 	m.SetHeader("From", s.from)
-	m.SetHeader("To", toEmail)
-	m.SetHeader("Subject", subject)
-	m.SetBody("text/plain", body)
+	m.SetHeader("To", msg.To)
+	m.SetHeader("Subject", msg.Subject)
+	m.SetBody("text/plain", msg.Text)
+	if msg.HTML != "" {
+		m.AddAlternative("text/html", msg.HTML)
+	}
 	d := gomail.NewDialer(s.host, s.port, s.username, s.password)
 	if err := d.DialAndSend(m); err != nil {
 		return fmt.Errorf("smtp send: %w", err)
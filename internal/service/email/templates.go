@@ -0,0 +1,163 @@
+package email
+
+import (
+	domainEmail "EduSync/internal/domain/email"
+	domainUser "EduSync/internal/domain/user"
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Встроенные шаблоны писем: templates/<язык>/<письмо>.txt и .html.
+// В .txt блоки "subject" и "content", в .html — блок "content";
+// общая обёртка письма — в layout.txt и layout.html того же языка.
+//
+//go:embed templates
+var embeddedTemplates embed.FS
+
+// templateSet — разобранные шаблоны одного письма на одном языке.
+type templateSet struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template // nil — письмо только текстовое
+}
+
+// Renderer собирает письма из шаблонов. Шаблоны из каталога переопределяют
+// встроенные файл за файлом, так что можно поменять одно письмо или только обёртку.
+type Renderer struct {
+	sets map[string]*templateSet // ключ "<язык>/<письмо>"
+}
+
+// NewRenderer разбирает встроенные шаблоны и шаблоны из dir (может быть пустым).
+// Ошибка в любом шаблоне останавливает запуск, а не отправку письма.
+func NewRenderer(dir string) (*Renderer, error) {
+	base, err := fs.Sub(embeddedTemplates, "templates")
+	if err != nil {
+		return nil, err
+	}
+	fsys := base
+	if dir != "" {
+		if _, err := os.Stat(dir); err != nil {
+			return nil, fmt.Errorf("каталог шаблонов писем: %w", err)
+		}
+		fsys = overlayFS{top: os.DirFS(dir), base: base}
+	}
+
+	r := &Renderer{sets: make(map[string]*templateSet)}
+	for _, locale := range []string{domainUser.LocaleRU, domainUser.LocaleEN} {
+		names, err := templateNames(fsys, locale)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			set, err := parseSet(fsys, locale, name)
+			if err != nil {
+				return nil, fmt.Errorf("шаблон %s/%s: %w", locale, name, err)
+			}
+			r.sets[locale+"/"+name] = set
+		}
+	}
+	return r, nil
+}
+
+// Render собирает письмо name на языке locale. Если перевода нет, используется русский.
+func (r *Renderer) Render(name, locale string, data domainEmail.Data) (*domainEmail.Message, error) {
+	set, ok := r.sets[locale+"/"+name]
+	if !ok {
+		set, ok = r.sets[domainUser.LocaleRU+"/"+name]
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domainEmail.ErrTemplateNotFound, name)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := set.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("тема письма %s: %w", name, err)
+	}
+	if err := set.text.ExecuteTemplate(&text, "layout", data); err != nil {
+		return nil, fmt.Errorf("текст письма %s: %w", name, err)
+	}
+	if set.html != nil {
+		if err := set.html.ExecuteTemplate(&html, "layout", data); err != nil {
+			return nil, fmt.Errorf("HTML письма %s: %w", name, err)
+		}
+	}
+	return &domainEmail.Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// templateNames возвращает письма, для которых есть текстовый шаблон на языке locale.
+func templateNames(fsys fs.FS, locale string) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, locale)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".txt")
+		if !ok || e.IsDir() || name == "layout" {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func parseSet(fsys fs.FS, locale, name string) (*templateSet, error) {
+	text, err := texttemplate.ParseFS(fsys, path.Join(locale, "layout.txt"), path.Join(locale, name+".txt"))
+	if err != nil {
+		return nil, err
+	}
+	set := &templateSet{text: text}
+
+	htmlFile := path.Join(locale, name+".html")
+	if _, err := fs.Stat(fsys, htmlFile); errors.Is(err, fs.ErrNotExist) {
+		return set, nil
+	}
+	if set.html, err = htmltemplate.ParseFS(fsys, path.Join(locale, "layout.html"), htmlFile); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// overlayFS отдаёт файлы из top, а отсутствующие — из base. Каталоги объединяются.
+type overlayFS struct {
+	top, base fs.FS
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	if f, err := o.top.Open(name); err == nil {
+		return f, nil
+	}
+	return o.base.Open(name)
+}
+
+func (o overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	top, topErr := fs.ReadDir(o.top, name)
+	base, baseErr := fs.ReadDir(o.base, name)
+	if topErr != nil && baseErr != nil {
+		return nil, baseErr
+	}
+	seen := make(map[string]bool, len(top))
+	for _, e := range top {
+		seen[e.Name()] = true
+	}
+	for _, e := range base {
+		if !seen[e.Name()] {
+			top = append(top, e)
+		}
+	}
+	return top, nil
+}
@@ -0,0 +1,77 @@
+package email
+
+import (
+	domainEmail "EduSync/internal/domain/email"
+	"EduSync/internal/service"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/gomail.v2"
+)
+
+// fileEmailService сохраняет письма в каталог файлами .eml — для локальной разработки и тестов.
+type fileEmailService struct {
+	dir string
+}
+
+// NewFileEmailService создаёт транспорт, который пишет письма в dir вместо отправки.
+func NewFileEmailService(dir string) (service.EmailService, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("ошибка создания каталога писем: %w", err)
+	}
+	return &fileEmailService{dir: dir}, nil
+}
+
+func (s *fileEmailService) Send(ctx context.Context, msg *domainEmail.Message) error {
+	m := gomail.NewMessage()
+	m.SetHeader("To", msg.To)
+	m.SetHeader("Subject", msg.Subject)
+	m.SetBody("text/plain", msg.Text)
+	if msg.HTML != "" {
+		m.AddAlternative("text/html", msg.HTML)
+	}
+
+	suffix, err := randomSuffix()
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405.000"), suffix)
+	f, err := os.Create(filepath.Join(s.dir, name))
+	if err != nil {
+		return fmt.Errorf("file send: %w", err)
+	}
+	defer f.Close()
+	if _, err := m.WriteTo(f); err != nil {
+		return fmt.Errorf("file send: %w", err)
+	}
+	return nil
+}
+
+// logEmailService пишет письма в лог вместо отправки.
+type logEmailService struct {
+	log *logrus.Logger
+}
+
+// NewLogEmailService создаёт транспорт, который только логирует письма.
+func NewLogEmailService(log *logrus.Logger) service.EmailService {
+	return &logEmailService{log: log}
+}
+
+func (s *logEmailService) Send(ctx context.Context, msg *domainEmail.Message) error {
+	s.log.Infof("Письмо для %s: %s\n%s", msg.To, msg.Subject, msg.Text)
+	return nil
+}
+
+func randomSuffix() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1<<32))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%08x", n.Int64()), nil
+}
@@ -1,35 +1,75 @@
 package group
 
 import (
+	domainAudit "EduSync/internal/domain/audit"
 	domainGroup "EduSync/internal/domain/group"
-	"EduSync/internal/integration/parser/rksi/group"
+	domainInstitution "EduSync/internal/domain/institution"
+	domainUser "EduSync/internal/domain/user"
+	"EduSync/internal/integration/provider"
 	"EduSync/internal/repository"
 	"EduSync/internal/service"
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"github.com/sirupsen/logrus"
+	"strings"
 	"time"
 )
 
 type Service struct {
-	repo   repository.GroupRepository
-	parser group.Parser // Интерфейс, описывающий методы парсинга групп
-	log    *logrus.Logger
+	repo            repository.GroupRepository
+	institutionRepo repository.InstitutionRepository
+	providers       *provider.Registry // Источники расписания учреждений
+	audit           service.AuditService
+	log             *logrus.Logger
 }
 
 // NewGroupService создает новый сервис групп.
-func NewGroupService(repo repository.GroupRepository, parser group.Parser, logger *logrus.Logger) service.GroupService {
+func NewGroupService(
+	repo repository.GroupRepository,
+	institutionRepo repository.InstitutionRepository,
+	providers *provider.Registry,
+	audit service.AuditService,
+	logger *logrus.Logger,
+) service.GroupService {
 	return &Service{
-		repo:   repo,
-		parser: parser,
-		log:    logger,
+		repo:            repo,
+		institutionRepo: institutionRepo,
+		providers:       providers,
+		audit:           audit,
+		log:             logger,
 	}
 }
 
-// Update получает группы через парсер и сохраняет их в БД.
+// Update получает группы из источников всех учреждений и сохраняет их в БД.
 func (s *Service) Update(ctx context.Context) error {
 	s.log.Info("Обновление групп")
 
-	groupNames, institutionId, err := s.parser.FetchGroups(ctx)
+	institutions, err := s.institutionRepo.WithScheduleProvider(ctx)
+	if err != nil {
+		s.log.Errorf("Ошибка получения учреждений: %v", err)
+		return err
+	}
+
+	var errs []error
+	for _, inst := range institutions {
+		if err := s.updateInstitution(ctx, inst); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Service) updateInstitution(ctx context.Context, inst *domainInstitution.Institution) error {
+	institutionId := inst.ID
+	p, err := s.providers.ForInstitution(inst)
+	if err != nil {
+		s.log.Errorf("Источник расписания учреждения %d: %v", institutionId, err)
+		return err
+	}
+
+	groupNames, err := p.Groups(ctx)
 	if err != nil {
 		s.log.Errorf("Ошибка парсинга групп: %v", err)
 		return err
@@ -44,7 +84,7 @@ func (s *Service) Update(ctx context.Context) error {
 	}
 
 	// Сохраняем группы в БД
-	if err := s.repo.Save(context.Background(), groups); err != nil {
+	if err := s.repo.Save(ctx, groups); err != nil {
 		s.log.Errorf("Ошибка парсинга групп: %v", err)
 		return err
 	}
@@ -63,6 +103,90 @@ func (s *Service) ById(ctx context.Context, groupId int) (*domainGroup.Group, er
 	return s.repo.ById(ctx, groupId)
 }
 
+// Create добавляет группу, которой нет в источнике расписания.
+func (s *Service) Create(ctx context.Context, actor domainUser.Actor, name string, institutionID int) (*domainGroup.Group, error) {
+	if !actor.Manages(institutionID) {
+		return nil, domainUser.ErrForbidden
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, domainGroup.ErrInvalidName
+	}
+	id, err := s.repo.Create(ctx, name, institutionID)
+	if err != nil {
+		if errors.Is(err, domainGroup.ErrExists) {
+			return nil, err
+		}
+		s.log.Errorf("Ошибка создания группы %s: %v", name, err)
+		return nil, fmt.Errorf("не удалось создать группу")
+	}
+	s.audit.Record(ctx, actor, institutionID, domainAudit.EntityGroup, id, domainAudit.ActionCreate, map[string]string{
+		"name": name,
+	})
+	return &domainGroup.Group{ID: id, Name: name, InstitutionID: institutionID}, nil
+}
+
+// Rename меняет название группы. Если источник расписания продолжит отдавать
+// прежнее название, при следующем обновлении появится новая группа.
+func (s *Service) Rename(ctx context.Context, actor domainUser.Actor, id int, name string) (*domainGroup.Group, error) {
+	g, err := s.managedGroup(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, domainGroup.ErrInvalidName
+	}
+	if err := s.repo.Rename(ctx, id, name); err != nil {
+		if errors.Is(err, domainGroup.ErrExists) {
+			return nil, err
+		}
+		s.log.Errorf("Ошибка переименования группы %d: %v", id, err)
+		return nil, fmt.Errorf("не удалось переименовать группу")
+	}
+	s.audit.Record(ctx, actor, g.InstitutionID, domainAudit.EntityGroup, id, domainAudit.ActionUpdate, map[string]string{
+		"before": g.Name,
+		"after":  name,
+	})
+	g.Name = name
+	return g, nil
+}
+
+// Delete удаляет группу без расписания и чатов.
+func (s *Service) Delete(ctx context.Context, actor domainUser.Actor, id int) error {
+	g, err := s.managedGroup(ctx, actor, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, domainGroup.ErrInUse) {
+			return err
+		}
+		s.log.Errorf("Ошибка удаления группы %d: %v", id, err)
+		return fmt.Errorf("не удалось удалить группу")
+	}
+	s.audit.Record(ctx, actor, g.InstitutionID, domainAudit.EntityGroup, id, domainAudit.ActionDelete, map[string]string{
+		"name": g.Name,
+	})
+	return nil
+}
+
+// managedGroup возвращает группу, если пользователь управляет её учреждением.
+func (s *Service) managedGroup(ctx context.Context, actor domainUser.Actor, id int) (*domainGroup.Group, error) {
+	g, err := s.repo.ById(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domainGroup.ErrNotFound
+	}
+	if err != nil {
+		s.log.Errorf("Ошибка получения группы %d: %v", id, err)
+		return nil, fmt.Errorf("не удалось получить группу")
+	}
+	if !actor.Manages(g.InstitutionID) {
+		return nil, domainUser.ErrForbidden
+	}
+	return g, nil
+}
+
 // Запуск воркера для периодического обновления групп (например, раз в 24 часа).
 func (s *Service) StartWorker(interval time.Duration) {
 	ctx := context.Background()
@@ -1,24 +1,30 @@
 package institution
 
 import (
+	domainAudit "EduSync/internal/domain/audit"
 	domainInstitution "EduSync/internal/domain/institution"
+	domainUser "EduSync/internal/domain/user"
 	"EduSync/internal/repository"
 	"EduSync/internal/service"
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 )
 
 type emailMaskService struct {
-	repo repository.EmailMaskRepository
-	log  *logrus.Logger
+	repo  repository.EmailMaskRepository
+	audit service.AuditService
+	log   *logrus.Logger
 }
 
-func NewEmailMaskService(repo repository.EmailMaskRepository, logger *logrus.Logger) service.EmailMaskService {
+func NewEmailMaskService(repo repository.EmailMaskRepository, audit service.AuditService, logger *logrus.Logger) service.EmailMaskService {
 	return &emailMaskService{
-		repo: repo,
-		log:  logger,
+		repo:  repo,
+		audit: audit,
+		log:   logger,
 	}
 }
 
@@ -44,3 +50,65 @@ func (s *emailMaskService) MaskByValue(ctx context.Context, mask string) (*domai
 	}
 	return result, nil
 }
+
+// Create закрепляет почтовый домен за учреждением: преподаватели с почтой
+// на этом домене смогут зарегистрироваться в учреждении.
+func (s *emailMaskService) Create(ctx context.Context, actor domainUser.Actor, institutionID int, mask string) (*domainInstitution.EmailMask, error) {
+	if !actor.Manages(institutionID) {
+		return nil, domainUser.ErrForbidden
+	}
+	mask = normalizeMask(mask)
+	if !validMask(mask) {
+		return nil, domainInstitution.ErrInvalidMask
+	}
+
+	id, err := s.repo.Create(ctx, institutionID, mask)
+	if err != nil {
+		if errors.Is(err, domainInstitution.ErrMaskTaken) || errors.Is(err, domainInstitution.ErrNotFound) {
+			return nil, err
+		}
+		s.log.Errorf("Ошибка создания почтовой маски %s: %v", mask, err)
+		return nil, fmt.Errorf("не удалось создать почтовую маску")
+	}
+	s.audit.Record(ctx, actor, institutionID, domainAudit.EntityEmailMask, id, domainAudit.ActionCreate, map[string]string{
+		"email_mask": mask,
+	})
+	return &domainInstitution.EmailMask{ID: id, InstitutionID: institutionID, EmailMask: mask}, nil
+}
+
+// Delete удаляет почтовую маску учреждения.
+func (s *emailMaskService) Delete(ctx context.Context, actor domainUser.Actor, id int) error {
+	mask, err := s.repo.ByID(ctx, id)
+	if err != nil {
+		s.log.Errorf("Ошибка получения почтовой маски %d: %v", id, err)
+		return fmt.Errorf("не удалось получить маску")
+	}
+	if mask == nil {
+		return domainInstitution.ErrMaskNotFound
+	}
+	if !actor.Manages(mask.InstitutionID) {
+		return domainUser.ErrForbidden
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		s.log.Errorf("Ошибка удаления почтовой маски %d: %v", id, err)
+		return fmt.Errorf("не удалось удалить маску")
+	}
+	s.audit.Record(ctx, actor, mask.InstitutionID, domainAudit.EntityEmailMask, id, domainAudit.ActionDelete, map[string]string{
+		"email_mask": mask.EmailMask,
+	})
+	return nil
+}
+
+// normalizeMask приводит маску к виду, в котором её сравнивает регистрация: домен без "@".
+func normalizeMask(mask string) string {
+	mask = strings.ToLower(strings.TrimSpace(mask))
+	return strings.TrimPrefix(mask, "@")
+}
+
+func validMask(mask string) bool {
+	if mask == "" || strings.ContainsAny(mask, "@ /") {
+		return false
+	}
+	dot := strings.LastIndex(mask, ".")
+	return dot > 0 && dot < len(mask)-1
+}
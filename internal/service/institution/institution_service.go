@@ -1,23 +1,43 @@
 package institution
 
 import (
+	domainAudit "EduSync/internal/domain/audit"
 	domainInstitution "EduSync/internal/domain/institution"
+	domainUser "EduSync/internal/domain/user"
+	"EduSync/internal/integration/provider"
 	"EduSync/internal/repository"
 	"EduSync/internal/service"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 )
 
 type Service struct {
-	repo repository.InstitutionRepository
-	log  *logrus.Logger
+	repo      repository.InstitutionRepository
+	providers *provider.Registry // Допустимые источники расписания
+	audit     service.AuditService
+	log       *logrus.Logger
 }
 
-func NewInstitutionService(repo repository.InstitutionRepository, logger *logrus.Logger) service.InstitutionService {
+func NewInstitutionService(
+	repo repository.InstitutionRepository,
+	providers *provider.Registry,
+	audit service.AuditService,
+	logger *logrus.Logger,
+) service.InstitutionService {
 	return &Service{
-		repo: repo,
-		log:  logger,
+		repo:      repo,
+		providers: providers,
+		audit:     audit,
+		log:       logger,
 	}
 }
 
@@ -30,3 +50,157 @@ func (s *Service) All(ctx context.Context) ([]*domainInstitution.Institution, er
 	s.log.Info("Получение списка всех учебных заведений")
 	return s.repo.All(ctx)
 }
+
+// Create добавляет учебное заведение. Доступно только системному администратору.
+func (s *Service) Create(ctx context.Context, actor domainUser.Actor, inst *domainInstitution.Institution) (int, error) {
+	if !actor.Role.Can(domainUser.PermSystemManage) {
+		return 0, domainUser.ErrForbidden
+	}
+	inst.Name = strings.TrimSpace(inst.Name)
+	if inst.Name == "" {
+		return 0, domainInstitution.ErrInvalidName
+	}
+	if err := s.validateProvider(inst.ScheduleProvider, inst.ProviderConfig); err != nil {
+		return 0, err
+	}
+	if err := validateBranding(inst.LogoURL, inst.BrandColor); err != nil {
+		return 0, err
+	}
+
+	id, err := s.repo.Create(ctx, inst)
+	if err != nil {
+		s.log.Errorf("Ошибка создания учреждения: %v", err)
+		return 0, fmt.Errorf("не удалось создать учреждение")
+	}
+	inst.ID = id
+	s.audit.Record(ctx, actor, id, domainAudit.EntityInstitution, id, domainAudit.ActionCreate, map[string]interface{}{
+		"name":              inst.Name,
+		"schedule_provider": inst.ScheduleProvider,
+	})
+	return id, nil
+}
+
+// Update меняет название, источник расписания и оформление писем учреждения.
+func (s *Service) Update(ctx context.Context, actor domainUser.Actor, id int, upd domainInstitution.Update) (*domainInstitution.Institution, error) {
+	if !actor.Manages(id) {
+		return nil, domainUser.ErrForbidden
+	}
+	inst, err := s.repo.ByID(ctx, id)
+	if err != nil {
+		s.log.Errorf("Ошибка получения учреждения %d: %v", id, err)
+		return nil, fmt.Errorf("не удалось получить учреждение")
+	}
+	if inst == nil {
+		return nil, domainInstitution.ErrNotFound
+	}
+
+	before := *inst
+	if upd.Name != nil {
+		name := strings.TrimSpace(*upd.Name)
+		if name == "" {
+			return nil, domainInstitution.ErrInvalidName
+		}
+		inst.Name = name
+	}
+	if upd.ScheduleProvider != nil {
+		inst.ScheduleProvider = *upd.ScheduleProvider
+	}
+	if upd.ProviderConfig != nil {
+		// Источник скачивает адрес из настроек изнутри сервера, поэтому менять
+		// настройки может только системный администратор
+		if !actor.Role.Can(domainUser.PermSystemManage) && !sameJSON(upd.ProviderConfig, inst.ProviderConfig) {
+			return nil, domainUser.ErrForbidden
+		}
+		inst.ProviderConfig = upd.ProviderConfig
+	}
+	if upd.LogoURL != nil {
+		inst.LogoURL = strings.TrimSpace(*upd.LogoURL)
+	}
+	if upd.BrandColor != nil {
+		inst.BrandColor = strings.ToLower(strings.TrimSpace(*upd.BrandColor))
+	}
+	if err := s.validateProvider(inst.ScheduleProvider, inst.ProviderConfig); err != nil {
+		return nil, err
+	}
+	if err := validateBranding(inst.LogoURL, inst.BrandColor); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, inst); err != nil {
+		s.log.Errorf("Ошибка обновления учреждения %d: %v", id, err)
+		return nil, fmt.Errorf("не удалось обновить учреждение")
+	}
+	s.audit.Record(ctx, actor, id, domainAudit.EntityInstitution, id, domainAudit.ActionUpdate, map[string]interface{}{
+		"before": map[string]string{"name": before.Name, "schedule_provider": before.ScheduleProvider},
+		"after":  map[string]string{"name": inst.Name, "schedule_provider": inst.ScheduleProvider},
+	})
+	return inst, nil
+}
+
+// Delete удаляет учреждение без пользователей, групп и предметов.
+func (s *Service) Delete(ctx context.Context, actor domainUser.Actor, id int) error {
+	if !actor.Role.Can(domainUser.PermSystemManage) {
+		return domainUser.ErrForbidden
+	}
+	inst, err := s.repo.ByID(ctx, id)
+	if err != nil {
+		s.log.Errorf("Ошибка получения учреждения %d: %v", id, err)
+		return fmt.Errorf("не удалось получить учреждение")
+	}
+	if inst == nil {
+		return domainInstitution.ErrNotFound
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, domainInstitution.ErrInUse) {
+			return err
+		}
+		s.log.Errorf("Ошибка удаления учреждения %d: %v", id, err)
+		return fmt.Errorf("не удалось удалить учреждение")
+	}
+	// Запись журнала не привязываем к удалённому учреждению
+	s.audit.Record(ctx, actor, 0, domainAudit.EntityInstitution, id, domainAudit.ActionDelete, map[string]string{
+		"name": inst.Name,
+	})
+	return nil
+}
+
+// validateBranding проверяет логотип и цвет, которые подставляются в HTML писем.
+// Пустые значения допустимы — тогда используется оформление EduSync.
+func validateBranding(logoURL, color string) error {
+	if logoURL != "" {
+		u, err := url.Parse(logoURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return domainInstitution.ErrInvalidBranding
+		}
+	}
+	if color != "" && !brandColorRe.MatchString(color) {
+		return domainInstitution.ErrInvalidBranding
+	}
+	return nil
+}
+
+var brandColorRe = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// validateProvider проверяет, что источник расписания зарегистрирован, а настройки — JSON-объект.
+func (s *Service) validateProvider(kind string, config json.RawMessage) error {
+	if kind != "" && !slices.Contains(s.providers.Kinds(), kind) {
+		return fmt.Errorf("%w %q, доступны: %s",
+			domainInstitution.ErrInvalidProvider, kind, strings.Join(s.providers.Kinds(), ", "))
+	}
+	if len(config) > 0 {
+		var obj map[string]interface{}
+		if err := json.Unmarshal(config, &obj); err != nil {
+			return fmt.Errorf("%w: настройки должны быть JSON-объектом", domainInstitution.ErrInvalidProvider)
+		}
+	}
+	return nil
+}
+
+// sameJSON сообщает, что a и b — одинаковый JSON с точностью до пробелов.
+func sameJSON(a, b json.RawMessage) bool {
+	var ca, cb bytes.Buffer
+	if json.Compact(&ca, a) != nil || json.Compact(&cb, b) != nil {
+		return false
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	domainRateLimit "EduSync/internal/domain/ratelimit"
+	"EduSync/internal/repository"
+	"EduSync/internal/service"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// limiter считает попытки по политикам и блокирует ключи с экспоненциальной выдержкой.
+type limiter struct {
+	repo repository.RateLimitRepository
+	log  *logrus.Logger
+}
+
+// NewLimiter создаёт ограничитель поверх хранилища счётчиков (в памяти или в Postgres).
+func NewLimiter(repo repository.RateLimitRepository, log *logrus.Logger) service.RateLimiter {
+	return &limiter{repo: repo, log: log}
+}
+
+// Check возвращает LimitedError, если ключ заблокирован. Попытка не засчитывается.
+func (l *limiter) Check(ctx context.Context, policy domainRateLimit.Policy, subject string) error {
+	s, err := l.repo.Get(ctx, policy.Key(subject))
+	if err != nil {
+		// Сбой хранилища не должен закрывать вход для всех
+		l.log.Errorf("Ошибка проверки ограничения %s: %v", policy.Name, err)
+		return nil
+	}
+	if s != nil {
+		if wait := time.Until(s.LockedUntil); wait > 0 {
+			return &domainRateLimit.LimitedError{RetryAfter: wait}
+		}
+	}
+	return nil
+}
+
+// Hit засчитывает попытку. Если ключ уже заблокирован, возвращает LimitedError
+// и попытку не считает. Попытка, исчерпавшая лимит, проходит, но блокирует следующие.
+func (l *limiter) Hit(ctx context.Context, policy domainRateLimit.Policy, subject string) error {
+	now := time.Now()
+	var limited *domainRateLimit.LimitedError
+	var locked time.Duration
+
+	_, err := l.repo.Update(ctx, policy.Key(subject), func(s *domainRateLimit.State) {
+		if wait := s.LockedUntil.Sub(now); wait > 0 {
+			limited = &domainRateLimit.LimitedError{RetryAfter: wait}
+			return
+		}
+		if now.Sub(s.UpdatedAt) > domainRateLimit.StrikesTTL {
+			s.Strikes = 0
+		}
+		if s.WindowStart.IsZero() || now.Sub(s.WindowStart) >= policy.Window {
+			s.Count = 0
+			s.WindowStart = now
+		}
+		s.Count++
+		if s.Count >= policy.Limit {
+			s.Strikes++
+			locked = policy.Lockout(s.Strikes)
+			s.LockedUntil = now.Add(locked)
+			s.Count = 0
+			s.WindowStart = time.Time{}
+		}
+	})
+	if err != nil {
+		l.log.Errorf("Ошибка учёта попытки %s: %v", policy.Name, err)
+		return nil
+	}
+	if locked > 0 {
+		l.log.Warnf("Превышен лимит %s для %q, блокировка на %s", policy.Name, subject, locked)
+	}
+	if limited != nil {
+		return limited
+	}
+	return nil
+}
+
+// Reset сбрасывает счётчик, например после успешного входа.
+func (l *limiter) Reset(ctx context.Context, policy domainRateLimit.Policy, subject string) error {
+	return l.repo.Delete(ctx, policy.Key(subject))
+}
+
+// StartCleanup периодически удаляет устаревшие счётчики.
+func (l *limiter) StartCleanup(interval time.Duration) {
+	go func() {
+		for {
+			time.Sleep(interval)
+			n, err := l.repo.DeleteStale(context.Background(), time.Now().Add(-domainRateLimit.StrikesTTL))
+			if err != nil {
+				l.log.Errorf("Ошибка очистки счётчиков попыток: %v", err)
+				continue
+			}
+			if n > 0 {
+				l.log.Debugf("Удалено устаревших счётчиков попыток: %d", n)
+			}
+		}
+	}()
+}
@@ -0,0 +1,59 @@
+package schedule
+
+import (
+	"EduSync/internal/repository"
+	"EduSync/internal/service"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+type calendarTokenService struct {
+	repo repository.CalendarTokenRepository
+	log  *logrus.Logger
+}
+
+// NewCalendarTokenService создает сервис токенов подписки на календарь.
+func NewCalendarTokenService(repo repository.CalendarTokenRepository, log *logrus.Logger) service.CalendarTokenService {
+	return &calendarTokenService{repo: repo, log: log}
+}
+
+// Token возвращает токен пользователя, выпуская новый при первом обращении.
+func (s *calendarTokenService) Token(ctx context.Context, userID int) (string, error) {
+	token, err := s.repo.ByUserID(ctx, userID)
+	if err != nil {
+		s.log.Errorf("calendarTokenRepo.ByUserID(%d): %v", userID, err)
+		return "", fmt.Errorf("не удалось получить токен календаря")
+	}
+	if token != "" {
+		return token, nil
+	}
+	return s.Regenerate(ctx, userID)
+}
+
+// Regenerate выпускает новый токен, старые ссылки на календарь перестают работать.
+func (s *calendarTokenService) Regenerate(ctx context.Context, userID int) (string, error) {
+	token, err := generateFeedToken()
+	if err != nil {
+		s.log.Errorf("generateFeedToken: %v", err)
+		return "", fmt.Errorf("не удалось создать токен календаря")
+	}
+	if err := s.repo.Save(ctx, userID, token); err != nil {
+		s.log.Errorf("calendarTokenRepo.Save(%d): %v", userID, err)
+		return "", fmt.Errorf("не удалось сохранить токен календаря")
+	}
+	s.log.Infof("Выпущен новый токен календаря для пользователя %d", userID)
+	return token, nil
+}
+
+// generateFeedToken генерирует криптографически стойкий токен из 32 случайных байт.
+func generateFeedToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,73 @@
+package schedule
+
+import (
+	domainSchedule "EduSync/internal/domain/schedule"
+	"EduSync/internal/repository"
+	"EduSync/internal/service"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+type classroomService struct {
+	repo repository.ClassroomRepository
+	log  *logrus.Logger
+}
+
+// NewClassroomService создает сервис справочника аудиторий.
+func NewClassroomService(repo repository.ClassroomRepository, log *logrus.Logger) service.ClassroomService {
+	return &classroomService{repo: repo, log: log}
+}
+
+// List возвращает аудитории учреждения.
+func (s *classroomService) List(ctx context.Context, institutionID int) ([]*domainSchedule.Classroom, error) {
+	list, err := s.repo.ByInstitutionID(ctx, institutionID)
+	if err != nil {
+		s.log.Errorf("Ошибка получения аудиторий учреждения %d: %v", institutionID, err)
+		return nil, fmt.Errorf("не удалось получить аудитории")
+	}
+	return list, nil
+}
+
+// Free возвращает аудитории, свободные на заданной паре.
+func (s *classroomService) Free(ctx context.Context, f domainSchedule.FreeClassroomsFilter) ([]*domainSchedule.Classroom, error) {
+	if f.PairNumber <= 0 {
+		return nil, fmt.Errorf("номер пары должен быть положительным")
+	}
+	list, err := s.repo.Free(ctx, f)
+	if err != nil {
+		s.log.Errorf("Ошибка поиска свободных аудиторий: %v", err)
+		return nil, fmt.Errorf("не удалось найти свободные аудитории")
+	}
+	return list, nil
+}
+
+// Update меняет корпус и вместимость аудитории своего учреждения.
+func (s *classroomService) Update(ctx context.Context, institutionID, id int, building *string, capacity *int) (*domainSchedule.Classroom, error) {
+	c, err := s.repo.ByID(ctx, id)
+	if err != nil {
+		s.log.Errorf("Ошибка получения аудитории %d: %v", id, err)
+		return nil, fmt.Errorf("не удалось получить аудиторию")
+	}
+	if c == nil || c.InstitutionID != institutionID {
+		return nil, domainSchedule.ErrClassroomNotFound
+	}
+
+	if building != nil {
+		c.Building = strings.TrimSpace(*building)
+	}
+	if capacity != nil {
+		if *capacity <= 0 {
+			c.Capacity = nil
+		} else {
+			c.Capacity = capacity
+		}
+	}
+	if err := s.repo.Update(ctx, c); err != nil {
+		s.log.Errorf("Ошибка обновления аудитории %d: %v", id, err)
+		return nil, fmt.Errorf("не удалось обновить аудиторию")
+	}
+	return c, nil
+}
@@ -0,0 +1,202 @@
+package schedule
+
+import (
+	domainSchedule "EduSync/internal/domain/schedule"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// checkConflicts проверяет запись перед сохранением: время пары, занятость группы,
+// преподавателя и аудитории, совпадение со звонками. Возвращает ID учреждения группы.
+// Ошибки блокируют сохранение всегда, предупреждения — если не передан force.
+func (s *scheduleService) checkConflicts(ctx context.Context, e *domainSchedule.Schedule, excludeID int, force bool) (int, error) {
+	grp, err := s.groupRepo.ById(ctx, e.GroupID)
+	if err != nil || grp == nil {
+		s.log.Errorf("groupRepo.ById(%d): %v", e.GroupID, err)
+		return 0, domainSchedule.ErrGroupNotFound
+	}
+
+	var conflicts []*domainSchedule.Conflict
+	if clock(e.EndTime) <= clock(e.StartTime) {
+		conflicts = append(conflicts, &domainSchedule.Conflict{
+			Type:     domainSchedule.ConflictInvalidTime,
+			Severity: domainSchedule.SeverityError,
+			Message: fmt.Sprintf("Время окончания %s не позже времени начала %s",
+				clock(e.EndTime), clock(e.StartTime)),
+		})
+	}
+
+	others, err := s.repo.Overlapping(ctx, grp.InstitutionID, e, excludeID)
+	if err != nil {
+		s.log.Errorf("repo.Overlapping: %v", err)
+		return 0, fmt.Errorf("не удалось проверить расписание на конфликты")
+	}
+	for _, o := range others {
+		conflicts = append(conflicts, s.conflictsWith(ctx, e, o)...)
+	}
+
+	bellConflict, err := s.bellConflict(ctx, grp.InstitutionID, e)
+	if err != nil {
+		return 0, err
+	}
+	if bellConflict != nil {
+		conflicts = append(conflicts, bellConflict)
+	}
+
+	if blocking(conflicts, force) {
+		return 0, &domainSchedule.ConflictError{Conflicts: conflicts}
+	}
+	return grp.InstitutionID, nil
+}
+
+// conflictsWith сравнивает запись с уже стоящей в тот же день парой.
+func (s *scheduleService) conflictsWith(ctx context.Context, e, o *domainSchedule.Schedule) []*domainSchedule.Conflict {
+	id := o.ID
+	when := fmt.Sprintf("%s–%s", clock(o.StartTime), clock(o.EndTime))
+
+	if o.GroupID == e.GroupID {
+		return []*domainSchedule.Conflict{{
+			Type:       domainSchedule.ConflictGroupBusy,
+			Severity:   domainSchedule.SeverityError,
+			Message:    fmt.Sprintf("У группы уже стоит пара %d (%s): %s", o.PairNumber, when, s.subjectName(ctx, o.SubjectID)),
+			ScheduleID: &id,
+		}}
+	}
+
+	// Пары других групп конфликтуют, только если пересекаются по времени:
+	// номера пар у разных групп одного дня могут не совпадать.
+	if clock(o.StartTime) >= clock(e.EndTime) || clock(o.EndTime) <= clock(e.StartTime) {
+		return nil
+	}
+
+	var out []*domainSchedule.Conflict
+	groupName := s.groupName(ctx, o.GroupID)
+	if e.TeacherInitialsID != nil && sameIntPtr(e.TeacherInitialsID, o.TeacherInitialsID) {
+		out = append(out, &domainSchedule.Conflict{
+			Type:     domainSchedule.ConflictTeacherBusy,
+			Severity: domainSchedule.SeverityError,
+			Message: fmt.Sprintf("Преподаватель %s в %s ведёт пару у группы %s",
+				s.initialsName(ctx, o.TeacherInitialsID), when, groupName),
+			ScheduleID: &id,
+		})
+	}
+	if isRealClassroom(e.Classroom) && e.Classroom == o.Classroom {
+		out = append(out, &domainSchedule.Conflict{
+			Type:       domainSchedule.ConflictClassroomBusy,
+			Severity:   domainSchedule.SeverityError,
+			Message:    fmt.Sprintf("Аудитория %s в %s занята группой %s", o.Classroom, when, groupName),
+			ScheduleID: &id,
+		})
+	}
+	return out
+}
+
+// bellConflict сверяет время пары с расписанием звонков, если оно задано для учреждения.
+func (s *scheduleService) bellConflict(ctx context.Context, institutionID int, e *domainSchedule.Schedule) (*domainSchedule.Conflict, error) {
+	bells, err := s.bellRepo.ByInstitutionID(ctx, institutionID)
+	if err != nil {
+		s.log.Errorf("bellRepo.ByInstitutionID(%d): %v", institutionID, err)
+		return nil, fmt.Errorf("не удалось получить расписание звонков")
+	}
+	if len(bells) == 0 {
+		return nil, nil
+	}
+	for _, b := range bells {
+		if b.PairNumber != e.PairNumber {
+			continue
+		}
+		if b.StartTime == clock(e.StartTime) && b.EndTime == clock(e.EndTime) {
+			return nil, nil
+		}
+		return &domainSchedule.Conflict{
+			Type:     domainSchedule.ConflictBellMismatch,
+			Severity: domainSchedule.SeverityWarning,
+			Message: fmt.Sprintf("По расписанию звонков пара %d идёт %s–%s, а не %s–%s",
+				b.PairNumber, b.StartTime, b.EndTime, clock(e.StartTime), clock(e.EndTime)),
+		}, nil
+	}
+	return &domainSchedule.Conflict{
+		Type:     domainSchedule.ConflictBellMismatch,
+		Severity: domainSchedule.SeverityWarning,
+		Message:  fmt.Sprintf("В расписании звонков нет пары %d", e.PairNumber),
+	}, nil
+}
+
+func blocking(conflicts []*domainSchedule.Conflict, force bool) bool {
+	for _, c := range conflicts {
+		if c.Severity == domainSchedule.SeverityError || !force {
+			return true
+		}
+	}
+	return false
+}
+
+// slotTaken превращает нарушение уникального индекса (group_id, date, pair_number),
+// пойманное при гонке двух запросов, в такой же структурированный конфликт.
+func slotTaken(e *domainSchedule.Schedule) error {
+	return &domainSchedule.ConflictError{Conflicts: []*domainSchedule.Conflict{{
+		Type:     domainSchedule.ConflictGroupBusy,
+		Severity: domainSchedule.SeverityError,
+		Message:  fmt.Sprintf("У группы уже стоит пара %d %s", e.PairNumber, e.Date.Format("02.01")),
+	}}}
+}
+
+func isRealClassroom(name string) bool {
+	return name != "" && name != "-"
+}
+
+func (s *scheduleService) groupName(ctx context.Context, id int) string {
+	grp, err := s.groupRepo.ById(ctx, id)
+	if err != nil || grp == nil {
+		return fmt.Sprintf("#%d", id)
+	}
+	return grp.Name
+}
+
+// Bells возвращает расписание звонков учреждения.
+func (s *scheduleService) Bells(ctx context.Context, institutionID int) ([]*domainSchedule.Bell, error) {
+	bells, err := s.bellRepo.ByInstitutionID(ctx, institutionID)
+	if err != nil {
+		s.log.Errorf("bellRepo.ByInstitutionID(%d): %v", institutionID, err)
+		return nil, fmt.Errorf("не удалось получить расписание звонков")
+	}
+	return bells, nil
+}
+
+// SetBells заменяет расписание звонков учреждения. Пустой список отключает проверку звонков.
+func (s *scheduleService) SetBells(ctx context.Context, institutionID int, bells []*domainSchedule.Bell) error {
+	for _, b := range bells {
+		if b == nil {
+			return fmt.Errorf("%w: пустая запись в списке звонков", domainSchedule.ErrInvalidBells)
+		}
+	}
+	sort.Slice(bells, func(i, j int) bool { return bells[i].PairNumber < bells[j].PairNumber })
+
+	prevEnd := ""
+	for i, b := range bells {
+		if b.PairNumber <= 0 || (i > 0 && bells[i-1].PairNumber == b.PairNumber) {
+			return fmt.Errorf("%w: номера пар должны быть положительными и не повторяться", domainSchedule.ErrInvalidBells)
+		}
+		start, errStart := time.Parse("15:04", b.StartTime)
+		end, errEnd := time.Parse("15:04", b.EndTime)
+		if errStart != nil || errEnd != nil {
+			return fmt.Errorf("%w: время пары %d должно быть в формате ЧЧ:ММ", domainSchedule.ErrInvalidBells, b.PairNumber)
+		}
+		b.StartTime, b.EndTime = clock(start), clock(end)
+		if b.EndTime <= b.StartTime {
+			return fmt.Errorf("%w: пара %d заканчивается раньше, чем начинается", domainSchedule.ErrInvalidBells, b.PairNumber)
+		}
+		if b.StartTime < prevEnd {
+			return fmt.Errorf("%w: пара %d начинается до окончания предыдущей", domainSchedule.ErrInvalidBells, b.PairNumber)
+		}
+		prevEnd = b.EndTime
+	}
+
+	if err := s.bellRepo.Replace(ctx, institutionID, bells); err != nil {
+		s.log.Errorf("bellRepo.Replace(%d): %v", institutionID, err)
+		return fmt.Errorf("не удалось сохранить расписание звонков")
+	}
+	return nil
+}
@@ -2,12 +2,17 @@ package schedule
 
 import (
 	dtoSchedule "EduSync/internal/delivery/http/schedule/dto"
+	"EduSync/internal/delivery/ws"
+	domainGroup "EduSync/internal/domain/group"
+	domainInstitution "EduSync/internal/domain/institution"
 	domainSchedule "EduSync/internal/domain/schedule"
-	"EduSync/internal/integration/parser/rksi/schedule"
-	"EduSync/internal/integration/parser/rksi/teacher"
+	domainUser "EduSync/internal/domain/user"
+	"EduSync/internal/integration/provider"
+	"EduSync/internal/integration/provider/upload"
 	"EduSync/internal/repository"
 	"EduSync/internal/service"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"sort"
@@ -17,148 +22,444 @@ import (
 
 type scheduleService struct {
 	repo                repository.ScheduleRepository
-	parserSchedule      *schedule.ScheduleParser
-	parserTeacher       *teacher.TeacherParser
+	importRepo          repository.ScheduleImportRepository
+	institutionRepo     repository.InstitutionRepository
+	providers           *provider.Registry
 	subjectSvc          service.SubjectService
 	userSvc             service.UserService
 	groupRepo           repository.GroupRepository
 	teacherInitialsRepo repository.TeacherInitialsRepository
+	classroomRepo       repository.ClassroomRepository
+	bellRepo            repository.BellScheduleRepository
+	hub                 *ws.Hub
 	log                 *logrus.Logger
 }
 
 // NewScheduleService создает новый сервис для расписания.
 func NewScheduleService(
 	repo repository.ScheduleRepository,
-	parserSchedule *schedule.ScheduleParser,
-	parserTeacher *teacher.TeacherParser,
+	importRepo repository.ScheduleImportRepository,
+	institutionRepo repository.InstitutionRepository,
+	providers *provider.Registry,
 	subjectSvc service.SubjectService,
 	userSvc service.UserService,
 	groupRepo repository.GroupRepository,
 	teacherInitialsRepo repository.TeacherInitialsRepository,
+	classroomRepo repository.ClassroomRepository,
+	bellRepo repository.BellScheduleRepository,
+	hub *ws.Hub,
 	log *logrus.Logger,
 ) service.ScheduleService {
 	return &scheduleService{
 		repo:                repo,
-		parserSchedule:      parserSchedule,
-		parserTeacher:       parserTeacher,
+		importRepo:          importRepo,
+		institutionRepo:     institutionRepo,
+		providers:           providers,
 		subjectSvc:          subjectSvc,
 		userSvc:             userSvc,
 		groupRepo:           groupRepo,
 		teacherInitialsRepo: teacherInitialsRepo,
+		classroomRepo:       classroomRepo,
+		bellRepo:            bellRepo,
+		hub:                 hub,
 		log:                 log,
 	}
 }
 
-func (s *scheduleService) Save(ctx context.Context, groupName string) error {
-	s.log.Infof("Сохраняем расписание для группы %q", groupName)
+// Save обновляет расписание группы учреждения institutionID из его источника.
+// Администратор учреждения может обновить только группы своего учреждения.
+func (s *scheduleService) Save(ctx context.Context, actor domainUser.Actor, institutionID int, groupName string) error {
+	if !actor.In(institutionID) {
+		return domainSchedule.ErrForbidden
+	}
+	s.log.Infof("Сохраняем расписание для группы %q учреждения %d", groupName, institutionID)
 
-	// 1) Парсим сырое расписание
-	parsed, err := s.parserSchedule.FetchSchedule(ctx, groupName)
+	group, err := s.groupRepo.ByName(ctx, institutionID, groupName)
 	if err != nil {
-		s.log.Errorf("парсер вернул ошибку: %v", err)
-		return fmt.Errorf("не удалось распарсить расписание")
+		s.log.Errorf("ошибка поиска группы %q: %v", groupName, err)
+		return fmt.Errorf("не удалось найти группу")
+	}
+	if group == nil {
+		return domainGroup.ErrNotFound
 	}
 
-	// 2) Получаем ID группы и института
-	group, err := s.groupRepo.ByName(ctx, groupName)
+	p, err := s.providerFor(ctx, group.InstitutionID)
 	if err != nil {
-		s.log.Errorf("ошибка поиска группы %q: %v", groupName, err)
-		return fmt.Errorf("группа не найдена")
+		return err
+	}
+	return s.sync(ctx, p, group)
+}
+
+// providerFor возвращает источник расписания учебного заведения.
+func (s *scheduleService) providerFor(ctx context.Context, institutionID int) (provider.Provider, error) {
+	inst, err := s.institutionRepo.ByID(ctx, institutionID)
+	if err != nil {
+		s.log.Errorf("institutionRepo.ByID(%d): %v", institutionID, err)
+		return nil, fmt.Errorf("не удалось получить учебное заведение")
+	}
+	if inst == nil {
+		return nil, fmt.Errorf("учебное заведение не найдено")
+	}
+	p, err := s.providers.ForInstitution(inst)
+	if err != nil {
+		s.log.Errorf("источник расписания учреждения %d: %v", institutionID, err)
+		return nil, fmt.Errorf("для учебного заведения не настроен источник расписания")
+	}
+	return p, nil
+}
+
+// sync забирает расписание группы из источника и сверяет его с сохранённым.
+func (s *scheduleService) sync(ctx context.Context, p provider.Provider, group *domainGroup.Group) error {
+	groupName := group.Name
+
+	// 1) Получаем сырое расписание из источника
+	parsed, err := p.Schedule(ctx, groupName)
+	if err != nil {
+		s.log.Errorf("источник вернул ошибку: %v", err)
+		return fmt.Errorf("не удалось получить расписание из источника")
 	}
 
+	// 2) ID группы и института
 	instID := group.InstitutionID
 	groupID := group.ID
 
-	// 3) Для каждой записи раскладываем Start/End в time.Time и собираем по дате
+	// 3) Для каждой записи раскладываем Start/End в time.Time и собираем по дате.
+	// Дата, в которой хоть одна запись не разобралась, не сверяется вовсе:
+	// иначе её пары сочлись бы отменёнными.
 	type raw struct {
-		pe    schedule.ScheduleEntry
+		pe    provider.Entry
 		start time.Time
 		end   time.Time
 	}
 
 	byDate := make(map[time.Time][]raw)
+	failed := make(map[time.Time]bool)
 	for _, pe := range parsed {
+		// обнуляем час/минута/секунды даты — чтобы ключом была ровно дата
+		d := pe.Date.Truncate(24 * time.Hour)
 		st, err := time.Parse("15:04", pe.StartTime)
 		if err != nil {
 			s.log.Warnf("некоректное время пары %q: %v", pe.StartTime, err)
+			failed[d] = true
 			continue
 		}
 		en, err := time.Parse("15:04", pe.EndTime)
 		if err != nil {
 			s.log.Warnf("некоректное время конца пары %q: %v", pe.EndTime, err)
+			failed[d] = true
 			continue
 		}
-		// обнуляем час/минута/секунды даты — чтобы ключом была ровно дата
-		d := pe.Date.Truncate(24 * time.Hour)
 		byDate[d] = append(byDate[d], raw{pe: pe, start: st, end: en})
 	}
 
-	// 4) Собираем финальный слайс domainSchedule.Schedule с правильно пронумерованными парами
+	// 4) Собираем финальный слайс domainSchedule.Schedule с пронумерованными парами
 	var toSave []*domainSchedule.Schedule
+	dates := make([]time.Time, 0, len(byDate))
 	for d, raws := range byDate {
+		if failed[d] {
+			continue
+		}
 		// сортируем по start asc
 		sort.Slice(raws, func(i, j int) bool {
 			return raws[i].start.Before(raws[j].start)
 		})
 
+		day := make([]*domainSchedule.Schedule, 0, len(raws))
 		for idx, r := range raws {
-			// idx==0 => pairNumber=1, и т.д.
-			pairNum := idx + 1
-
-			// subject: ищем или создаём
-			subj, err := s.subjectSvc.ByNameAndInstitution(ctx, r.pe.Discipline, instID)
+			// idx==0 => pairNumber=1, и т.д.; при сверке номер может смениться на свободный
+			e, err := s.entryOf(ctx, instID, r.pe)
 			if err != nil {
-				s.log.Errorf("subjectSvc.ByName %q: %v", r.pe.Discipline, err)
-				continue
-			}
-			if subj == nil {
-				id, err := s.subjectSvc.Create(ctx, r.pe.Discipline, instID)
-				if err != nil {
-					s.log.Errorf("subjectSvc.Create %q: %v", r.pe.Discipline, err)
-					continue
-				}
-				subj, _ = s.subjectSvc.ByID(ctx, id)
+				failed[d] = true
+				break
 			}
+			e.GroupID = groupID
+			e.Date = d
+			e.PairNumber = idx + 1
+			e.StartTime = combineTime(d, r.start)
+			e.EndTime = combineTime(d, r.end)
+			day = append(day, e)
+		}
+		if failed[d] {
+			continue
+		}
+		toSave = append(toSave, day...)
+		dates = append(dates, d)
+	}
+	if len(failed) > 0 {
+		s.log.Warnf("расписание группы %q: пропущено дат из-за ошибок: %d", groupName, len(failed))
+	}
+
+	// Пополняем справочник аудиторий тем, что встретилось в расписании
+	s.rememberClassrooms(ctx, instID, toSave)
+
+	// 5) Сверяем с сохранённым расписанием на те же даты
+	stored, err := s.repo.ByGroupAndDates(ctx, groupID, dates)
+	if err != nil {
+		s.log.Errorf("repo.ByGroupAndDates: %v", err)
+		return fmt.Errorf("не удалось получить сохранённое расписание")
+	}
+	changes := diffSchedule(stored, toSave)
+	if len(changes) == 0 {
+		s.log.Infof("расписание группы %q не изменилось", groupName)
+		return syncFailure(failed)
+	}
+	for _, ch := range changes {
+		ch.Description = s.describeChange(ctx, ch)
+	}
+
+	// 6) Применяем изменения и пишем историю одной транзакцией
+	if err := s.repo.ApplyChanges(ctx, changes); err != nil {
+		s.log.Errorf("repo.ApplyChanges: %v", err)
+		return fmt.Errorf("не удалось сохранить расписание")
+	}
+
+	// 7) Оповещаем подписчиков группы
+	s.hub.Broadcast(fmt.Sprintf("group_%d", groupID), "schedule:changed", changes)
+
+	s.log.Infof("расписание группы %q: применено %d изменений", groupName, len(changes))
+	return syncFailure(failed)
+}
+
+// entryOf находит или создаёт предмет и инициалы преподавателя записи источника.
+// Любая ошибка возвращается: запись без предмета или с потерянным преподавателем сохранять нельзя.
+func (s *scheduleService) entryOf(ctx context.Context, instID int, pe provider.Entry) (*domainSchedule.Schedule, error) {
+	// subject: ищем или создаём
+	subj, err := s.subjectSvc.ByNameAndInstitution(ctx, pe.Discipline, instID)
+	if err != nil {
+		s.log.Errorf("subjectSvc.ByName %q: %v", pe.Discipline, err)
+		return nil, err
+	}
+	if subj == nil {
+		id, err := s.subjectSvc.Create(ctx, pe.Discipline, instID)
+		if err != nil {
+			s.log.Errorf("subjectSvc.Create %q: %v", pe.Discipline, err)
+			return nil, err
+		}
+		if subj, err = s.subjectSvc.ByID(ctx, id); err != nil || subj == nil {
+			s.log.Errorf("subjectSvc.ByID(%d): %v", id, err)
+			return nil, fmt.Errorf("предмет %q не найден после создания", pe.Discipline)
+		}
+	}
+
+	// teacher_initials upsert
+	var tiID *int
+	initials := pe.Teacher
+	if initials != "" && initials != "-" {
+		// если нашли реального учителя
+		usr, err := s.userSvc.FindTeacherByName(ctx, initials)
+		var teacherPtr *int
+		if err == nil && usr != nil {
+			teacherPtr = &usr.ID
+		}
+		id, err := s.teacherInitialsRepo.Upsert(ctx, initials, teacherPtr, instID)
+		if err != nil {
+			s.log.Errorf("teacherInitialsRepo.Upsert %q: %v", initials, err)
+			return nil, err
+		}
+		tiID = &id
+	}
+
+	return &domainSchedule.Schedule{
+		SubjectID:         subj.ID,
+		Classroom:         pe.Classroom,
+		TeacherInitialsID: tiID,
+	}, nil
+}
+
+// syncFailure сообщает о датах, которые не удалось сверить.
+func syncFailure(failed map[time.Time]bool) error {
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("не удалось разобрать расписание на %d дат(ы), они не изменены", len(failed))
+}
+
+// scheduleKey идентифицирует пару группы: дата + время начала. Номер пары в ключ
+// не входит: отмена ранней пары сдвигает номера всех следующих.
+type scheduleKey struct {
+	date  string
+	start string
+}
+
+func keyOf(e *domainSchedule.Schedule) scheduleKey {
+	return scheduleKey{date: e.Date.Format("2006-01-02"), start: clock(e.StartTime)}
+}
+
+// diffSchedule сравнивает сохранённые и свежие записи одной группы
+// и возвращает список добавлений, изменений и удалений.
+// Записи, созданные вручную, не меняются и не удаляются; свежая пара в то же время,
+// что и ручная запись, пропускается. Изменённые пары сохраняют свой номер, новым
+// достаётся их номер по порядку или, если он занят, следующий за последним занятым.
+func diffSchedule(stored, fresh []*domainSchedule.Schedule) []*domainSchedule.Change {
+	old := make(map[scheduleKey]*domainSchedule.Schedule, len(stored))
+	manual := make(map[scheduleKey]bool)
+	for _, e := range stored {
+		if e.Synced {
+			old[keyOf(e)] = e
+		} else {
+			manual[keyOf(e)] = true
+		}
+	}
 
-			// teacher_initials upsert
-			var tiID *int
-			initials := r.pe.Teacher
-			if initials != "" && initials != "-" {
-				// если нашли реального учителя
-				usr, err := s.userSvc.FindTeacherByName(ctx, initials)
-				var teacherPtr *int
-				if err == nil && usr != nil {
-					teacherPtr = &usr.ID
-				}
-				id, err := s.teacherInitialsRepo.Upsert(ctx, initials, teacherPtr, instID)
-				if err != nil {
-					s.log.Errorf("teacherInitialsRepo.Upsert %q: %v", initials, err)
-				} else {
-					tiID = &id
-				}
+	var (
+		changes []*domainSchedule.Change
+		added   []*domainSchedule.Schedule
+	)
+	seen := make(map[scheduleKey]bool, len(fresh))
+	for _, n := range fresh {
+		k := keyOf(n)
+		if manual[k] {
+			continue
+		}
+		seen[k] = true
+		o, ok := old[k]
+		switch {
+		case !ok:
+			added = append(added, n)
+		case !sameEntry(o, n):
+			n.PairNumber = o.PairNumber
+			changes = append(changes, newChange(domainSchedule.ChangeUpdated, o, n))
+		}
+	}
+
+	// Номера пар, которые останутся занятыми после удалений
+	used := make(map[string]map[int]bool)
+	occupy := func(e *domainSchedule.Schedule) {
+		d := e.Date.Format("2006-01-02")
+		if used[d] == nil {
+			used[d] = make(map[int]bool)
+		}
+		used[d][e.PairNumber] = true
+	}
+	for _, o := range stored {
+		if o.Synced && !seen[keyOf(o)] {
+			changes = append(changes, newChange(domainSchedule.ChangeRemoved, o, nil))
+			continue
+		}
+		occupy(o)
+	}
+	for _, n := range added {
+		if taken := used[n.Date.Format("2006-01-02")]; taken[n.PairNumber] {
+			last := 0
+			for num := range taken {
+				last = max(last, num)
 			}
+			n.PairNumber = last + 1
+		}
+		occupy(n)
+		changes = append(changes, newChange(domainSchedule.ChangeAdded, nil, n))
+	}
 
-			toSave = append(toSave, &domainSchedule.Schedule{
-				GroupID:           groupID,
-				SubjectID:         subj.ID,
-				Date:              d,
-				PairNumber:        pairNum,
-				Classroom:         r.pe.Classroom,
-				TeacherInitialsID: tiID,
-				StartTime:         combineTime(d, r.start),
-				EndTime:           combineTime(d, r.end),
-			})
+	sort.SliceStable(changes, func(i, j int) bool {
+		if !changes[i].Date.Equal(changes[j].Date) {
+			return changes[i].Date.Before(changes[j].Date)
 		}
+		return changes[i].PairNumber < changes[j].PairNumber
+	})
+	return changes
+}
+
+func newChange(t domainSchedule.ChangeType, prev, next *domainSchedule.Schedule) *domainSchedule.Change {
+	ref := next
+	if ref == nil {
+		ref = prev
+	}
+	return &domainSchedule.Change{
+		GroupID:    ref.GroupID,
+		Date:       ref.Date,
+		PairNumber: ref.PairNumber,
+		Type:       t,
+		Old:        prev,
+		New:        next,
 	}
+}
 
-	// 5) Сохраняем всё пачкой
-	if err := s.repo.Save(ctx, toSave); err != nil {
-		s.log.Errorf("repo.Save: %v", err)
-		return fmt.Errorf("не удалось сохранить расписание")
+// sameEntry сравнивает содержательные поля пары. Время сравнивается только по часам и минутам:
+// из колонки TIME приходит нулевая дата, а у свежих записей дата занятия.
+func sameEntry(a, b *domainSchedule.Schedule) bool {
+	return a.SubjectID == b.SubjectID &&
+		a.Classroom == b.Classroom &&
+		sameIntPtr(a.TeacherInitialsID, b.TeacherInitialsID) &&
+		clock(a.StartTime) == clock(b.StartTime) &&
+		clock(a.EndTime) == clock(b.EndTime)
+}
+
+func sameIntPtr(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
 	}
+	return *a == *b
+}
 
-	s.log.Infof("успешно сохранено %d записей", len(toSave))
-	return nil
+func clock(t time.Time) string {
+	return t.Format("15:04")
+}
+
+// describeChange формирует текст вида «31.03: пара 3 (Математика): аудитория 101 → 204».
+func (s *scheduleService) describeChange(ctx context.Context, ch *domainSchedule.Change) string {
+	prefix := fmt.Sprintf("%s: ", ch.Date.Format("02.01"))
+	switch ch.Type {
+	case domainSchedule.ChangeAdded:
+		return prefix + fmt.Sprintf("добавлена пара %d — %s, %s, %s–%s",
+			ch.PairNumber, s.subjectName(ctx, ch.New.SubjectID), ch.New.Classroom,
+			clock(ch.New.StartTime), clock(ch.New.EndTime))
+	case domainSchedule.ChangeRemoved:
+		return prefix + fmt.Sprintf("отменена пара %d — %s", ch.PairNumber, s.subjectName(ctx, ch.Old.SubjectID))
+	}
+
+	var parts []string
+	if ch.Old.SubjectID != ch.New.SubjectID {
+		parts = append(parts, fmt.Sprintf("предмет %s → %s",
+			s.subjectName(ctx, ch.Old.SubjectID), s.subjectName(ctx, ch.New.SubjectID)))
+	}
+	if ch.Old.Classroom != ch.New.Classroom {
+		parts = append(parts, fmt.Sprintf("аудитория %s → %s", ch.Old.Classroom, ch.New.Classroom))
+	}
+	if !sameIntPtr(ch.Old.TeacherInitialsID, ch.New.TeacherInitialsID) {
+		parts = append(parts, fmt.Sprintf("преподаватель %s → %s",
+			s.initialsName(ctx, ch.Old.TeacherInitialsID), s.initialsName(ctx, ch.New.TeacherInitialsID)))
+	}
+	if clock(ch.Old.StartTime) != clock(ch.New.StartTime) || clock(ch.Old.EndTime) != clock(ch.New.EndTime) {
+		parts = append(parts, fmt.Sprintf("время %s–%s → %s–%s",
+			clock(ch.Old.StartTime), clock(ch.Old.EndTime), clock(ch.New.StartTime), clock(ch.New.EndTime)))
+	}
+	return prefix + fmt.Sprintf("пара %d (%s): %s",
+		ch.PairNumber, s.subjectName(ctx, ch.New.SubjectID), strings.Join(parts, "; "))
+}
+
+func (s *scheduleService) subjectName(ctx context.Context, id int) string {
+	sub, err := s.subjectSvc.ByID(ctx, id)
+	if err != nil || sub == nil {
+		return fmt.Sprintf("предмет #%d", id)
+	}
+	return sub.Name
+}
+
+func (s *scheduleService) initialsName(ctx context.Context, id *int) string {
+	if id == nil {
+		return "-"
+	}
+	ti, err := s.teacherInitialsRepo.GetByID(ctx, *id)
+	if err != nil || ti == nil {
+		return "-"
+	}
+	return ti.Initials
+}
+
+// Changes возвращает историю изменений расписания группы.
+func (s *scheduleService) Changes(ctx context.Context, groupID, limit, offset int) ([]*domainSchedule.Change, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	changes, err := s.repo.Changes(ctx, groupID, limit, offset)
+	if err != nil {
+		s.log.Errorf("Ошибка получения истории расписания группы %d: %v", groupID, err)
+		return nil, fmt.Errorf("не удалось получить историю изменений")
+	}
+	return changes, nil
 }
 
 // combineTime объединяет дату и время в один объект time.Time.
@@ -166,10 +467,10 @@ func combineTime(date, t time.Time) time.Time {
 	return time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), t.Second(), 0, date.Location())
 }
 
-// ByGroupID возвращает расписание для заданной группы.
-func (s *scheduleService) ByGroupID(ctx context.Context, groupID int) ([]*domainSchedule.Item, error) {
-	// 1) Забираем все записи расписания
-	entries, err := s.repo.ByGroupID(ctx, groupID)
+// ByGroupID возвращает расписание для заданной группы за период.
+func (s *scheduleService) ByGroupID(ctx context.Context, groupID int, period domainSchedule.Period) ([]*domainSchedule.Item, error) {
+	// 1) Забираем записи расписания за период
+	entries, err := s.repo.ByGroupID(ctx, groupID, period)
 	if err != nil {
 		s.log.Errorf("Ошибка получения расписания: %v", err)
 		return nil, err
@@ -234,14 +535,29 @@ func (s *scheduleService) ByGroupID(ctx context.Context, groupID int) ([]*domain
 	return out, nil
 }
 
-// ByTeacherInitialsID возвращает расписание по initials_id (для разных групп).
-func (s *scheduleService) ByTeacherInitialsID(ctx context.Context, initialsID int) ([]*domainSchedule.Item, error) {
-	entries, err := s.repo.ByTeacherInitialsID(ctx, initialsID)
+// ByTeacherInitialsID возвращает расписание по initials_id (для разных групп) за период.
+func (s *scheduleService) ByTeacherInitialsID(ctx context.Context, initialsID int, period domainSchedule.Period) ([]*domainSchedule.Item, error) {
+	entries, err := s.repo.ByTeacherInitialsID(ctx, initialsID, period)
 	if err != nil {
 		s.log.Errorf("Ошибка получения расписания по initials_id=%d: %v", initialsID, err)
 		return nil, fmt.Errorf("не удалось получить расписание")
 	}
 
+	return s.toItems(ctx, entries), nil
+}
+
+// ByClassroom возвращает занятия в аудитории учреждения за период.
+func (s *scheduleService) ByClassroom(ctx context.Context, institutionID int, classroom string, period domainSchedule.Period) ([]*domainSchedule.Item, error) {
+	entries, err := s.repo.ByClassroom(ctx, institutionID, classroom, period)
+	if err != nil {
+		s.log.Errorf("Ошибка получения расписания аудитории %q: %v", classroom, err)
+		return nil, fmt.Errorf("не удалось получить расписание")
+	}
+	return s.toItems(ctx, entries), nil
+}
+
+// toItems собирает элементы расписания для записей разных групп.
+func (s *scheduleService) toItems(ctx context.Context, entries []*domainSchedule.Schedule) []*domainSchedule.Item {
 	subjectIDs := make(map[int]struct{}, len(entries))
 	groupIDs := make(map[int]struct{}, len(entries))
 	for _, e := range entries {
@@ -306,20 +622,42 @@ func (s *scheduleService) ByTeacherInitialsID(ctx context.Context, initialsID in
 		})
 	}
 
-	return out, nil
+	return out
 }
 
+// updateInitials обновляет инициалы преподавателей всех учреждений с настроенным источником.
 func (s *scheduleService) updateInitials(ctx context.Context) error {
-	initials, id, err := s.parserTeacher.FetchTeacher(context.Background())
+	institutions, err := s.institutionRepo.WithScheduleProvider(ctx)
+	if err != nil {
+		s.log.Errorf("Ошибка получения учреждений: %v", err)
+		return err
+	}
+	var errs []error
+	for _, inst := range institutions {
+		p, err := s.providers.ForInstitution(inst)
+		if err != nil {
+			s.log.Errorf("источник расписания учреждения %d: %v", inst.ID, err)
+			errs = append(errs, err)
+			continue
+		}
+		if err := s.syncInitials(ctx, p, inst.ID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *scheduleService) syncInitials(ctx context.Context, p provider.Provider, institutionID int) error {
+	initials, err := p.Teachers(ctx)
 	if err != nil {
-		s.log.Errorf("Ошибка парсинга инициалов преподавателей: %v", err)
+		s.log.Errorf("Ошибка получения инициалов преподавателей учреждения %d: %v", institutionID, err)
 		return err
 	}
 	for _, initial := range initials {
 		if strings.HasPrefix(initial, "_") {
 			continue
 		}
-		_, err := s.teacherInitialsRepo.Upsert(ctx, initial, nil, id)
+		_, err := s.teacherInitialsRepo.Upsert(ctx, initial, nil, institutionID)
 		if err != nil {
 			s.log.Errorf("Ошибка сохраннеия инициала %v: %v", initial, err)
 			continue
@@ -328,43 +666,155 @@ func (s *scheduleService) updateInitials(ctx context.Context) error {
 	return nil
 }
 
-func (s *scheduleService) Update(ctx context.Context, id int, req *dtoSchedule.UpdateScheduleReq) error {
+// Import загружает расписание учреждения из шаблона CSV/XLSX и сразу применяет его.
+func (s *scheduleService) Import(ctx context.Context, institutionID int, filename string, data []byte) (*domainSchedule.ImportResult, error) {
+	inst, err := s.institutionRepo.ByID(ctx, institutionID)
+	if err != nil {
+		s.log.Errorf("institutionRepo.ByID(%d): %v", institutionID, err)
+		return nil, fmt.Errorf("не удалось получить учебное заведение")
+	}
+	if inst == nil || inst.ScheduleProvider != upload.Kind {
+		return nil, domainSchedule.ErrUploadNotAllowed
+	}
+
+	rows, err := upload.ParseTemplate(filename, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domainSchedule.ErrInvalidTemplate, err)
+	}
+	if err := s.importRepo.Replace(ctx, institutionID, rows); err != nil {
+		s.log.Errorf("importRepo.Replace(%d): %v", institutionID, err)
+		return nil, fmt.Errorf("не удалось сохранить загруженное расписание")
+	}
+
+	p, err := s.providers.ForInstitution(inst)
+	if err != nil {
+		s.log.Errorf("источник расписания учреждения %d: %v", institutionID, err)
+		return nil, fmt.Errorf("не удалось применить загруженное расписание")
+	}
+
+	// Группы и преподаватели из шаблона должны появиться до сверки расписания
+	names, err := p.Groups(ctx)
+	if err != nil {
+		s.log.Errorf("Ошибка получения групп из шаблона: %v", err)
+		return nil, fmt.Errorf("не удалось применить загруженное расписание")
+	}
+	newGroups := make([]*domainGroup.Group, 0, len(names))
+	for _, name := range names {
+		newGroups = append(newGroups, &domainGroup.Group{Name: name, InstitutionID: institutionID})
+	}
+	if err := s.groupRepo.Save(ctx, newGroups); err != nil {
+		s.log.Errorf("groupRepo.Save: %v", err)
+		return nil, fmt.Errorf("не удалось сохранить группы")
+	}
+	if err := s.syncInitials(ctx, p, institutionID); err != nil {
+		return nil, fmt.Errorf("не удалось сохранить преподавателей")
+	}
+
+	groups, err := s.groupRepo.ByInstitutionID(ctx, institutionID)
+	if err != nil {
+		s.log.Errorf("groupRepo.ByInstitutionID(%d): %v", institutionID, err)
+		return nil, fmt.Errorf("не удалось получить группы")
+	}
+	imported := make(map[string]bool, len(names))
+	for _, name := range names {
+		imported[name] = true
+	}
+
+	res := &domainSchedule.ImportResult{Rows: len(rows), Groups: len(names)}
+	for _, g := range groups {
+		if !imported[g.Name] {
+			continue
+		}
+		if err := s.sync(ctx, p, g); err != nil {
+			res.FailedGroups = append(res.FailedGroups, g.Name)
+		}
+	}
+	return res, nil
+}
+
+// Update изменяет запись расписания, предварительно проверив её на конфликты.
+// И исходная, и новая группа записи должны относиться к учреждению пользователя.
+func (s *scheduleService) Update(ctx context.Context, actor domainUser.Actor, id int, req *dtoSchedule.UpdateScheduleReq) error {
+	cur, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.log.Errorf("repo.GetByID(%d): %v", id, err)
+		return fmt.Errorf("не удалось получить запись расписания")
+	}
+	if cur == nil {
+		return domainSchedule.ErrNotFound
+	}
+	if err := s.authorize(ctx, actor, cur.GroupID); err != nil {
+		return err
+	}
+	if req.GroupID != nil && *req.GroupID != cur.GroupID {
+		if err := s.authorize(ctx, actor, *req.GroupID); err != nil {
+			return err
+		}
+	}
+
+	next := *cur
 	upd := make(map[string]interface{})
 	if req.GroupID != nil {
 		upd["group_id"] = *req.GroupID
+		next.GroupID = *req.GroupID
 	}
 	if req.SubjectID != nil {
 		upd["subject_id"] = *req.SubjectID
+		next.SubjectID = *req.SubjectID
 	}
 	if req.Date != nil {
 		upd["date"] = *req.Date
+		next.Date = *req.Date
 	}
 	if req.PairNumber != nil {
 		upd["pair_number"] = *req.PairNumber
+		next.PairNumber = *req.PairNumber
 	}
 	if req.Classroom != nil {
 		upd["classroom"] = *req.Classroom
+		next.Classroom = *req.Classroom
 	}
 	if req.TeacherInitialsID != nil {
 		upd["teacher_initials_id"] = *req.TeacherInitialsID
+		next.TeacherInitialsID = req.TeacherInitialsID
 	}
 	if req.StartTime != nil {
 		upd["start_time"] = *req.StartTime
+		next.StartTime = *req.StartTime
 	}
 	if req.EndTime != nil {
 		upd["end_time"] = *req.EndTime
+		next.EndTime = *req.EndTime
 	}
 	if len(upd) == 0 {
 		return nil
 	}
+	// Правленная вручную запись больше не принадлежит провайдеру:
+	// синхронизация не откатит правку и не пересоздаст пару
+	upd["synced"] = false
+
+	instID, err := s.checkConflicts(ctx, &next, id, req.Force)
+	if err != nil {
+		return err
+	}
 	if err := s.repo.Update(ctx, id, upd); err != nil {
+		if errors.Is(err, domainSchedule.ErrSlotTaken) {
+			return slotTaken(&next)
+		}
 		s.log.Errorf("Ошибка: %v", err)
 		return err
 	}
+	if req.Classroom != nil {
+		s.rememberClassrooms(ctx, instID, []*domainSchedule.Schedule{&next})
+	}
 	return nil
 }
 
-func (s *scheduleService) Create(ctx context.Context, req *dtoSchedule.CreateScheduleReq) (int, error) {
+// Create добавляет запись расписания, предварительно проверив её на конфликты.
+func (s *scheduleService) Create(ctx context.Context, actor domainUser.Actor, req *dtoSchedule.CreateScheduleReq) (int, error) {
+	if err := s.authorize(ctx, actor, req.GroupID); err != nil {
+		return 0, err
+	}
 	entry := &domainSchedule.Schedule{
 		GroupID:           req.GroupID,
 		SubjectID:         req.SubjectID,
@@ -375,41 +825,111 @@ func (s *scheduleService) Create(ctx context.Context, req *dtoSchedule.CreateSch
 		StartTime:         req.StartTime,
 		EndTime:           req.EndTime,
 	}
-	return s.repo.Create(ctx, entry)
+	instID, err := s.checkConflicts(ctx, entry, 0, req.Force)
+	if err != nil {
+		return 0, err
+	}
+	id, err := s.repo.Create(ctx, entry)
+	if errors.Is(err, domainSchedule.ErrSlotTaken) {
+		return 0, slotTaken(entry)
+	}
+	if err != nil {
+		return 0, err
+	}
+	s.rememberClassrooms(ctx, instID, []*domainSchedule.Schedule{entry})
+	return id, nil
 }
 
-func (s *scheduleService) Delete(ctx context.Context, id int) error {
+// rememberClassrooms добавляет аудитории записей в справочник учреждения.
+// Ошибка не мешает сохранению расписания, поэтому только логируется.
+func (s *scheduleService) rememberClassrooms(ctx context.Context, institutionID int, entries []*domainSchedule.Schedule) {
+	seen := make(map[string]bool)
+	var names []string
+	for _, e := range entries {
+		name := strings.TrimSpace(e.Classroom)
+		if name == "" || name == "-" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	if err := s.classroomRepo.EnsureExist(ctx, institutionID, names); err != nil {
+		s.log.Errorf("classroomRepo.EnsureExist(%d): %v", institutionID, err)
+	}
+}
+
+// Delete удаляет запись расписания группы из учреждения пользователя.
+func (s *scheduleService) Delete(ctx context.Context, actor domainUser.Actor, id int) error {
+	cur, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.log.Errorf("repo.GetByID(%d): %v", id, err)
+		return fmt.Errorf("не удалось получить запись расписания")
+	}
+	if cur == nil {
+		return domainSchedule.ErrNotFound
+	}
+	if err := s.authorize(ctx, actor, cur.GroupID); err != nil {
+		return err
+	}
 	return s.repo.Delete(ctx, id)
 }
 
+// authorize проверяет, что группа относится к учреждению пользователя.
+func (s *scheduleService) authorize(ctx context.Context, actor domainUser.Actor, groupID int) error {
+	grp, err := s.groupRepo.ById(ctx, groupID)
+	if err != nil || grp == nil {
+		s.log.Errorf("groupRepo.ById(%d): %v", groupID, err)
+		return domainSchedule.ErrGroupNotFound
+	}
+	if !actor.In(grp.InstitutionID) {
+		return domainSchedule.ErrForbidden
+	}
+	return nil
+}
+
 func (s *scheduleService) ByID(ctx context.Context, id int) (*domainSchedule.Schedule, error) {
 	return s.repo.GetByID(ctx, id)
 }
 
-// StartWorker запускает периодическое обновление расписания и инициалов.
+// StartWorker запускает периодическое обновление расписания всех учреждений с настроенным источником.
 func (s *scheduleService) StartWorker(interval time.Duration) {
 	go func() {
 		ctx := context.Background()
 		for {
-
-			groups, err := s.groupRepo.ByInstitutionID(ctx, s.parserSchedule.InstitutionID)
+			institutions, err := s.institutionRepo.WithScheduleProvider(ctx)
 			if err != nil {
-				s.log.Errorf("Ошибка получения групп: %v", err)
+				s.log.Errorf("Ошибка получения учреждений: %v", err)
 			}
-			for _, group := range groups {
-				err = s.Save(ctx, group.Name)
-				if err != nil {
-					s.log.Errorf("Ошибка обновления расписания: %v", err)
-				} else {
-					s.log.Info("Расписание успешно обновлено")
-				}
-				time.Sleep(time.Second * 10)
+			for _, inst := range institutions {
+				s.syncInstitution(ctx, inst)
 			}
 			time.Sleep(interval)
 		}
 	}()
 }
 
+func (s *scheduleService) syncInstitution(ctx context.Context, inst *domainInstitution.Institution) {
+	p, err := s.providers.ForInstitution(inst)
+	if err != nil {
+		s.log.Errorf("источник расписания учреждения %d: %v", inst.ID, err)
+		return
+	}
+	groups, err := s.groupRepo.ByInstitutionID(ctx, inst.ID)
+	if err != nil {
+		s.log.Errorf("Ошибка получения групп: %v", err)
+		return
+	}
+	for _, group := range groups {
+		err = s.sync(ctx, p, group)
+		if err != nil {
+			s.log.Errorf("Ошибка обновления расписания: %v", err)
+		} else {
+			s.log.Info("Расписание успешно обновлено")
+		}
+		time.Sleep(time.Second * 10)
+	}
+}
+
 // StartWorkerInitials запускает периодическое обновление расписания и инициалов.
 func (s *scheduleService) StartWorkerInitials(interval time.Duration) {
 	go func() {
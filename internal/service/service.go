@@ -5,15 +5,20 @@ import (
 	dtoChat2 "EduSync/internal/delivery/http/chat/dto"
 	dtoFavorite "EduSync/internal/delivery/http/favorite/dto"
 	dtoSchedule "EduSync/internal/delivery/http/schedule/dto"
+	domainAudit "EduSync/internal/domain/audit"
 	domainChat "EduSync/internal/domain/chat"
+	domainEmail "EduSync/internal/domain/email"
 	domainGroup "EduSync/internal/domain/group"
 	domainInstitution "EduSync/internal/domain/institution"
+	domainRateLimit "EduSync/internal/domain/ratelimit"
 	deliverSchedule "EduSync/internal/domain/schedule"
 	domainSchedule "EduSync/internal/domain/schedule"
 	domainSubject "EduSync/internal/domain/subject"
 	domainUser "EduSync/internal/domain/user"
 	"context"
+	"database/sql"
 	"github.com/gin-gonic/gin"
+	"io"
 	"mime/multipart"
 	"os"
 	"time"
@@ -21,12 +26,34 @@ import (
 
 type UserService interface {
 	Register(ctx context.Context, user domainUser.CreateUser) (int, error)
-	Login(ctx context.Context, email, password, userAgent, ipAddress string) (accessToken, refreshToken string, err error)
+	Login(ctx context.Context, email, password, userAgent, ipAddress string) (*domainUser.LoginResult, error)
 	UpdateProfile(ctx context.Context, u domainUser.UpdateUser, userAgent string, ipAddress string) (string, string, error)
 	Logout(ctx context.Context, accessToken string) error
 	RefreshToken(ctx context.Context, inputRefreshToken, userAgent, ipAddress string) (accessToken, refreshToken string, err error)
 	FindTeacherByName(ctx context.Context, teacher string) (*domainUser.User, error)
-	DeleteAccount(ctx context.Context, userID int) error
+	RequestAccountDeletion(ctx context.Context, userID int) error
+	DeleteAccount(ctx context.Context, userID int, code string) error
+	ExportData(ctx context.Context, userID int, w io.Writer) error
+	RequestEmailChange(ctx context.Context, userID int, newEmail, password string) error
+	ConfirmEmailChange(ctx context.Context, userID int, code, userAgent, ipAddress string) (string, string, error)
+	StartPurgeWorker(interval time.Duration)
+	ChangeRole(ctx context.Context, actor domainUser.Actor, targetID int, role domainUser.Role) error
+	Sessions(ctx context.Context, userID int, accessToken string) ([]*domainUser.Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID int) error
+	RevokeOtherSessions(ctx context.Context, userID int, accessToken string) (int, error)
+	PublicKeys() domainUser.JWKS
+
+	LoginTwoFactor(ctx context.Context, challengeToken, code, userAgent, ipAddress string) (*domainUser.LoginResult, error)
+	EnrollForChallenge(ctx context.Context, challengeToken string) (*domainUser.TwoFactorEnrollment, error)
+	RequestTwoFactorReset(ctx context.Context, challengeToken string) error
+	ResetTwoFactor(ctx context.Context, challengeToken, emailCode, userAgent, ipAddress string) (*domainUser.LoginResult, error)
+	TwoFactorStatus(ctx context.Context, actor domainUser.Actor) (*domainUser.TwoFactorStatus, error)
+	EnrollTwoFactor(ctx context.Context, userID int) (*domainUser.TwoFactorEnrollment, error)
+	EnableTwoFactor(ctx context.Context, userID int, code string) ([]string, error)
+	DisableTwoFactor(ctx context.Context, actor domainUser.Actor, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID int, code string) ([]string, error)
+	TwoFactorPolicy(ctx context.Context, actor domainUser.Actor, institutionID int) ([]domainUser.Role, error)
+	SetTwoFactorPolicy(ctx context.Context, actor domainUser.Actor, institutionID int, roles []domainUser.Role) ([]domainUser.Role, error)
 }
 
 type TeacherInitialsService interface {
@@ -39,18 +66,26 @@ type GroupService interface {
 	ById(ctx context.Context, groupId int) (*domainGroup.Group, error)
 	StartWorker(interval time.Duration)
 	Update(ctx context.Context) error
+	Create(ctx context.Context, actor domainUser.Actor, name string, institutionID int) (*domainGroup.Group, error)
+	Rename(ctx context.Context, actor domainUser.Actor, id int, name string) (*domainGroup.Group, error)
+	Delete(ctx context.Context, actor domainUser.Actor, id int) error
 }
 
 // InstitutionService описывает методы работы с учебными заведениями.
 type InstitutionService interface {
 	ByID(ctx context.Context, id int) (*domainInstitution.Institution, error)
 	All(ctx context.Context) ([]*domainInstitution.Institution, error)
+	Create(ctx context.Context, actor domainUser.Actor, inst *domainInstitution.Institution) (int, error)
+	Update(ctx context.Context, actor domainUser.Actor, id int, upd domainInstitution.Update) (*domainInstitution.Institution, error)
+	Delete(ctx context.Context, actor domainUser.Actor, id int) error
 }
 
 // EmailMaskService описывает бизнес-логику для работы с почтовыми масками.
 type EmailMaskService interface {
 	AllMasks(ctx context.Context) ([]*domainInstitution.EmailMask, error)
 	MaskByValue(ctx context.Context, mask string) (*domainInstitution.EmailMask, error)
+	Create(ctx context.Context, actor domainUser.Actor, institutionID int, mask string) (*domainInstitution.EmailMask, error)
+	Delete(ctx context.Context, actor domainUser.Actor, id int) error
 }
 
 type SubjectService interface {
@@ -59,21 +94,49 @@ type SubjectService interface {
 	ByInstitutionID(ctx context.Context, institutionID int) ([]*domainSubject.Subject, error)
 	ByGroupID(ctx context.Context, groupID int) ([]*domainSubject.Subject, error)
 	ByNameAndInstitution(ctx context.Context, discipline string, id int) (*domainSubject.Subject, error)
+	Add(ctx context.Context, actor domainUser.Actor, name string, institutionID int) (*domainSubject.Subject, error)
+	Rename(ctx context.Context, actor domainUser.Actor, id int, name string) (*domainSubject.Subject, error)
+	Delete(ctx context.Context, actor domainUser.Actor, id int) error
+	Merge(ctx context.Context, actor domainUser.Actor, targetID int, sourceIDs []int) (*domainSubject.Subject, error)
+}
+
+// AuditService ведёт журнал административных действий.
+type AuditService interface {
+	Record(ctx context.Context, actor domainUser.Actor, institutionID int, entity domainAudit.Entity, entityID int, action domainAudit.Action, details interface{})
+	List(ctx context.Context, actor domainUser.Actor, f domainAudit.Filter) ([]*domainAudit.Entry, error)
 }
 
 // ScheduleService описывает методы работы с расписанием.
 type ScheduleService interface {
-	Save(ctx context.Context, groupName string) error
-	Create(ctx context.Context, req *dtoSchedule.CreateScheduleReq) (int, error)
-	ByGroupID(ctx context.Context, groupID int) ([]*deliverSchedule.Item, error)
-	ByTeacherInitialsID(ctx context.Context, initialsID int) ([]*deliverSchedule.Item, error)
+	Save(ctx context.Context, actor domainUser.Actor, institutionID int, groupName string) error
+	Import(ctx context.Context, institutionID int, filename string, data []byte) (*domainSchedule.ImportResult, error)
+	Create(ctx context.Context, actor domainUser.Actor, req *dtoSchedule.CreateScheduleReq) (int, error)
+	ByGroupID(ctx context.Context, groupID int, period domainSchedule.Period) ([]*deliverSchedule.Item, error)
+	ByTeacherInitialsID(ctx context.Context, initialsID int, period domainSchedule.Period) ([]*deliverSchedule.Item, error)
+	ByClassroom(ctx context.Context, institutionID int, classroom string, period domainSchedule.Period) ([]*deliverSchedule.Item, error)
 	ByID(ctx context.Context, id int) (*domainSchedule.Schedule, error)
-	Update(ctx context.Context, id int, req *dtoSchedule.UpdateScheduleReq) error
-	Delete(ctx context.Context, id int) error
+	Changes(ctx context.Context, groupID, limit, offset int) ([]*domainSchedule.Change, error)
+	Update(ctx context.Context, actor domainUser.Actor, id int, req *dtoSchedule.UpdateScheduleReq) error
+	Delete(ctx context.Context, actor domainUser.Actor, id int) error
+	Bells(ctx context.Context, institutionID int) ([]*domainSchedule.Bell, error)
+	SetBells(ctx context.Context, institutionID int, bells []*domainSchedule.Bell) error
 	StartWorker(interval time.Duration)
 	StartWorkerInitials(interval time.Duration)
 }
 
+// ClassroomService описывает работу со справочником аудиторий.
+type ClassroomService interface {
+	List(ctx context.Context, institutionID int) ([]*domainSchedule.Classroom, error)
+	Free(ctx context.Context, f domainSchedule.FreeClassroomsFilter) ([]*domainSchedule.Classroom, error)
+	Update(ctx context.Context, institutionID, id int, building *string, capacity *int) (*domainSchedule.Classroom, error)
+}
+
+// CalendarTokenService управляет секретными токенами подписки на календарь.
+type CalendarTokenService interface {
+	Token(ctx context.Context, userID int) (string, error)
+	Regenerate(ctx context.Context, userID int) (string, error)
+}
+
 type ChatService interface {
 	CreateChat(ctx context.Context, c domainChat.Chat) (*domainChat.Chat, error)
 	ListForUser(ctx context.Context, userID int, isTeacher bool) ([]*dtoChat.ChatInfo, error)
@@ -83,17 +146,29 @@ type ChatService interface {
 	DeleteChat(ctx context.Context, chatID int, ownerID int) error
 	RemoveParticipant(ctx context.Context, chatID int, ownerID int, participantID int) error
 	LeaveChat(ctx context.Context, chatID int, userID int) error
+	MarkRead(ctx context.Context, chatID, userID, messageID int) (*domainChat.ReadReceipt, error)
 }
 
 type MessageService interface {
-	Messages(ctx context.Context, chatID, limit, offset int) ([]*domainChat.Message, error)
+	Messages(ctx context.Context, chatID int, q domainChat.MessageQuery) ([]*domainChat.Message, error)
+	Sync(ctx context.Context, chatID, userID int, since int64, limit int) (*domainChat.SyncResult, error)
+	Thread(ctx context.Context, chatID, messageID int, q domainChat.MessageQuery) (*domainChat.Thread, error)
 	SendMessage(ctx context.Context, msg domainChat.Message) (int, error)
 	DeleteMessage(ctx context.Context, messageID int, requesterID int) error
 	ReplyMessage(ctx context.Context, parentMessageID int, msg domainChat.Message) (int, error)
-	SearchMessages(ctx context.Context, chatID int, query string, limit, offset int) ([]*domainChat.Message, error)
+	SearchMessages(ctx context.Context, chatID, userID int, query string, limit, offset int) ([]*domainChat.Message, error)
+	Search(ctx context.Context, f domainChat.SearchFilter) ([]*domainChat.SearchHit, error)
 	MessageFiles(ctx context.Context, messageID int) ([]*domainChat.FileInfo, error)
 	SendMessageWithFiles(ctx context.Context, msg domainChat.Message, files []*multipart.FileHeader, c *gin.Context) (int, error)
 	UpdateMessage(ctx context.Context, messageID int, requesterID int, newText *string) (*domainChat.Message, error)
+	Revisions(ctx context.Context, chatID, messageID, requesterID int) ([]*domainChat.MessageRevision, error)
+	PurgeMessage(ctx context.Context, actor domainUser.Actor, messageID int) error
+	AddReaction(ctx context.Context, chatID, messageID, userID int, emoji string) ([]domainChat.Reaction, error)
+	RemoveReaction(ctx context.Context, chatID, messageID, userID int, emoji string) ([]domainChat.Reaction, error)
+	Pins(ctx context.Context, chatID, userID int) ([]*domainChat.Message, error)
+	PinMessage(ctx context.Context, chatID, messageID, userID int) (*domainChat.Message, error)
+	UnpinMessage(ctx context.Context, chatID, messageID, userID int) (*domainChat.Message, error)
+	Mentions(ctx context.Context, userID int, q domainChat.MessageQuery) ([]*domainChat.Message, error)
 }
 
 // FileService отдаёт файл по id, проверяя, что пользователь — участник чата.
@@ -115,13 +190,34 @@ type PollService interface {
 	Unvote(ctx context.Context, userID, pollID, optionID int) error
 }
 
+// EmailService доставляет готовые письма.
 type EmailService interface {
-	SendCode(ctx context.Context, toEmail, subject, body string) error
+	Send(ctx context.Context, msg *domainEmail.Message) error
+}
+
+// Mailer ставит в очередь письма по шаблонам на языке пользователя и с оформлением его учреждения.
+type Mailer interface {
+	Send(ctx context.Context, tx *sql.Tx, to string, user *domainUser.User, institutionID int, template string, data domainEmail.Data) error
+}
+
+// OutboxService доставляет письма из очереди.
+type OutboxService interface {
+	StartWorker(interval time.Duration)
+	List(ctx context.Context, actor domainUser.Actor, f domainEmail.OutboxFilter) ([]*domainEmail.OutboxMessage, error)
+}
+
+// RateLimiter ограничивает число попыток по политикам domainRateLimit.
+type RateLimiter interface {
+	Check(ctx context.Context, policy domainRateLimit.Policy, subject string) error
+	Hit(ctx context.Context, policy domainRateLimit.Policy, subject string) error
+	Reset(ctx context.Context, policy domainRateLimit.Policy, subject string) error
+	StartCleanup(interval time.Duration)
 }
 
 type ConfirmationService interface {
 	RequestCode(ctx context.Context, email, action string) error
-	VerifyCode(ctx context.Context, action, code string, userID *int) error
-	ResetPassword(ctx context.Context, code, newPassword string) error
-	UserIDByCode(ctx context.Context, action, code string) (int, error)
+	IssueCode(ctx context.Context, tx *sql.Tx, user *domainUser.User, institutionID int, action string) error
+	VerifyCode(ctx context.Context, userID int, action, code string) error
+	VerifyEmailCode(ctx context.Context, email, action, code string) error
+	ResetPassword(ctx context.Context, email, code, newPassword string) error
 }
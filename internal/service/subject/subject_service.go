@@ -1,23 +1,29 @@
 package subject
 
 import (
+	domainAudit "EduSync/internal/domain/audit"
 	"EduSync/internal/domain/subject"
+	domainUser "EduSync/internal/domain/user"
 	"EduSync/internal/repository"
 	"EduSync/internal/service"
 	"context"
 	"errors"
+	"fmt"
 	"github.com/sirupsen/logrus"
+	"slices"
+	"strings"
 )
 
 // subjectService предоставляет бизнес-логику для предметов.
 type subjectService struct {
 	subjectRepo repository.SubjectRepository
+	audit       service.AuditService
 	log         *logrus.Logger
 }
 
 // NewSubjectService создаёт новый экземпляр сервиса предметов.
-func NewSubjectService(subjectRepo repository.SubjectRepository, log *logrus.Logger) service.SubjectService {
-	return &subjectService{subjectRepo: subjectRepo, log: log}
+func NewSubjectService(subjectRepo repository.SubjectRepository, audit service.AuditService, log *logrus.Logger) service.SubjectService {
+	return &subjectService{subjectRepo: subjectRepo, audit: audit, log: log}
 }
 
 // Create создаёт предмет, если его ещё нет.
@@ -47,3 +53,137 @@ func (s *subjectService) ByGroupID(ctx context.Context, groupID int) ([]*subject
 func (s *subjectService) ByNameAndInstitution(ctx context.Context, discipline string, id int) (*subject.Subject, error) {
 	return s.subjectRepo.ByNameAndInstitution(ctx, discipline, id)
 }
+
+// Add добавляет предмет вручную. В отличие от Create, существующий предмет считается ошибкой.
+func (s *subjectService) Add(ctx context.Context, actor domainUser.Actor, name string, institutionID int) (*subject.Subject, error) {
+	if !actor.Manages(institutionID) {
+		return nil, domainUser.ErrForbidden
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, subject.ErrInvalidName
+	}
+	existing, err := s.subjectRepo.ByNameAndInstitution(ctx, name, institutionID)
+	if err != nil {
+		s.log.Errorf("Ошибка поиска предмета %s: %v", name, err)
+		return nil, fmt.Errorf("не удалось создать предмет")
+	}
+	if existing != nil {
+		return nil, subject.ErrExists
+	}
+	id, err := s.subjectRepo.Create(ctx, name, institutionID)
+	if err != nil {
+		s.log.Errorf("Ошибка создания предмета %s: %v", name, err)
+		return nil, fmt.Errorf("не удалось создать предмет")
+	}
+	s.audit.Record(ctx, actor, institutionID, domainAudit.EntitySubject, id, domainAudit.ActionCreate, map[string]string{
+		"name": name,
+	})
+	return &subject.Subject{ID: id, Name: name, InstitutionID: institutionID}, nil
+}
+
+// Rename исправляет название предмета.
+func (s *subjectService) Rename(ctx context.Context, actor domainUser.Actor, id int, name string) (*subject.Subject, error) {
+	subj, err := s.managedSubject(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, subject.ErrInvalidName
+	}
+	if err := s.subjectRepo.Rename(ctx, id, name); err != nil {
+		if errors.Is(err, subject.ErrExists) {
+			return nil, err
+		}
+		s.log.Errorf("Ошибка переименования предмета %d: %v", id, err)
+		return nil, fmt.Errorf("не удалось переименовать предмет")
+	}
+	s.audit.Record(ctx, actor, subj.InstitutionID, domainAudit.EntitySubject, id, domainAudit.ActionUpdate, map[string]string{
+		"before": subj.Name,
+		"after":  name,
+	})
+	subj.Name = name
+	return subj, nil
+}
+
+// Delete удаляет предмет, который не используется в расписании и чатах.
+func (s *subjectService) Delete(ctx context.Context, actor domainUser.Actor, id int) error {
+	subj, err := s.managedSubject(ctx, actor, id)
+	if err != nil {
+		return err
+	}
+	if err := s.subjectRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, subject.ErrInUse) {
+			return err
+		}
+		s.log.Errorf("Ошибка удаления предмета %d: %v", id, err)
+		return fmt.Errorf("не удалось удалить предмет")
+	}
+	s.audit.Record(ctx, actor, subj.InstitutionID, domainAudit.EntitySubject, id, domainAudit.ActionDelete, map[string]string{
+		"name": subj.Name,
+	})
+	return nil
+}
+
+// Merge объединяет дубликаты предмета, которые парсер создал под немного разными
+// названиями: расписание и чаты переносятся на targetID, дубликаты удаляются.
+// Их названия остаются прежними названиями targetID, чтобы синхронизация не создала их снова.
+func (s *subjectService) Merge(ctx context.Context, actor domainUser.Actor, targetID int, sourceIDs []int) (*subject.Subject, error) {
+	target, err := s.managedSubject(ctx, actor, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ids   []int
+		names []string
+	)
+	for _, id := range sourceIDs {
+		if id == targetID || slices.Contains(ids, id) {
+			continue
+		}
+		src, err := s.managedSubject(ctx, actor, id)
+		if err != nil {
+			return nil, err
+		}
+		if src.InstitutionID != target.InstitutionID {
+			return nil, subject.ErrMergeInstitution
+		}
+		ids = append(ids, id)
+		names = append(names, src.Name)
+	}
+	if len(ids) == 0 {
+		return target, nil
+	}
+
+	if err := s.subjectRepo.Merge(ctx, targetID, ids); err != nil {
+		if errors.Is(err, subject.ErrMergeChatConflict) {
+			return nil, err
+		}
+		s.log.Errorf("Ошибка объединения предметов %v в %d: %v", ids, targetID, err)
+		return nil, fmt.Errorf("не удалось объединить предметы")
+	}
+	s.audit.Record(ctx, actor, target.InstitutionID, domainAudit.EntitySubject, targetID, domainAudit.ActionMerge, map[string]interface{}{
+		"target":       target.Name,
+		"merged_ids":   ids,
+		"merged_names": names,
+	})
+	return target, nil
+}
+
+// managedSubject возвращает предмет, если пользователь управляет его учреждением.
+func (s *subjectService) managedSubject(ctx context.Context, actor domainUser.Actor, id int) (*subject.Subject, error) {
+	subj, err := s.subjectRepo.ByID(ctx, id)
+	if err != nil {
+		s.log.Errorf("Ошибка получения предмета %d: %v", id, err)
+		return nil, fmt.Errorf("не удалось получить предмет")
+	}
+	if subj == nil {
+		return nil, subject.ErrNotFound
+	}
+	if !actor.Manages(subj.InstitutionID) {
+		return nil, domainUser.ErrForbidden
+	}
+	return subj, nil
+}
@@ -0,0 +1,244 @@
+package user
+
+import (
+	domainUser "EduSync/internal/domain/user"
+	"EduSync/internal/repository"
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+const deleteAccountEmail = "delete_account" // действие ConfirmationService для удаления аккаунта
+
+// RequestAccountDeletion отправляет пользователю код для удаления аккаунта.
+// Код запрашивается и тратится только из его сессии: публичные /confirm/* его не принимают.
+func (s *AuthService) RequestAccountDeletion(ctx context.Context, userID int) error {
+	owned, err := s.teacherRepo.OwnedChats(ctx, userID)
+	if err != nil {
+		s.log.Errorf("RequestAccountDeletion: OwnedChats: %v", err)
+		return fmt.Errorf("не удалось отправить код")
+	}
+	if owned > 0 {
+		return domainUser.ErrOwnsChats
+	}
+	user, err := s.userRepo.ByID(ctx, userID)
+	if err != nil {
+		s.log.Errorf("RequestAccountDeletion: ByID: %v", err)
+		return fmt.Errorf("не удалось получить пользователя")
+	}
+	if user == nil {
+		return domainUser.ErrUserNotFound
+	}
+	return s.confirmationService.RequestCode(ctx, user.Email, deleteAccountEmail)
+}
+
+// DeleteAccount удаляет аккаунт по коду из письма. Аккаунт помечается удалённым,
+// все сессии завершаются; в течение DeletionGracePeriod вход восстанавливает аккаунт,
+// после него данные стирает фоновый обработчик. Владелец чатов получает ErrOwnsChats:
+// стереть такой аккаунт, не удалив чужую переписку, нельзя.
+func (s *AuthService) DeleteAccount(ctx context.Context, userID int, code string) error {
+	// Проверяем до кода, чтобы не расходовать его впустую
+	owned, err := s.teacherRepo.OwnedChats(ctx, userID)
+	if err != nil {
+		s.log.Errorf("DeleteAccount: OwnedChats: %v", err)
+		return fmt.Errorf("не удалось удалить аккаунт")
+	}
+	if owned > 0 {
+		return domainUser.ErrOwnsChats
+	}
+	if err := s.confirmationService.VerifyCode(ctx, userID, deleteAccountEmail, code); err != nil {
+		return domainUser.ErrInvalidOTP
+	}
+	if err := s.userRepo.SoftDelete(ctx, userID); err != nil {
+		s.log.Errorf("DeleteAccount: SoftDelete: %v", err)
+		return fmt.Errorf("не удалось удалить аккаунт")
+	}
+	if err := s.tokenRepo.DeleteForUser(ctx, userID); err != nil {
+		s.log.Errorf("DeleteAccount: ошибка удаления токенов пользователя: %v", err)
+		return fmt.Errorf("не удалось удалить сессии")
+	}
+	s.log.Infof("Аккаунт %d удалён, будет стёрт после %s", userID,
+		time.Now().Add(domainUser.DeletionGracePeriod).Format(time.DateOnly))
+	return nil
+}
+
+// restore отменяет удаление аккаунта.
+func (s *AuthService) restore(ctx context.Context, user *domainUser.User) error {
+	restored, err := s.userRepo.Restore(ctx, user.ID)
+	if err != nil {
+		s.log.Errorf("userRepo.Restore: %v", err)
+		return fmt.Errorf("не удалось восстановить аккаунт")
+	}
+	if restored {
+		s.log.Infof("Аккаунт %d восстановлен входом", user.ID)
+	}
+	user.DeletedAt = nil
+	return nil
+}
+
+// StartPurgeWorker периодически стирает аккаунты, срок восстановления которых истёк.
+func (s *AuthService) StartPurgeWorker(interval time.Duration) {
+	go func() {
+		for {
+			s.purgeDeleted(context.Background())
+			time.Sleep(interval)
+		}
+	}()
+}
+
+func (s *AuthService) purgeDeleted(ctx context.Context) {
+	ids, err := s.userRepo.DeletedBefore(ctx, time.Now().Add(-domainUser.DeletionGracePeriod))
+	if err != nil {
+		s.log.Errorf("Ошибка получения удалённых аккаунтов: %v", err)
+		return
+	}
+	for _, id := range ids {
+		if err := s.purge(ctx, id); err != nil {
+			s.log.Errorf("Аккаунт %d не стёрт: %v", id, err)
+		}
+	}
+}
+
+// purge окончательно удаляет пользователя вместе с сообщениями, голосами и загруженными файлами.
+func (s *AuthService) purge(ctx context.Context, userID int) error {
+	files, err := s.exportRepo.Files(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.userRepo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := s.userRepo.DeleteByID(ctx, tx, userID); err != nil {
+		if repository.IsForeignKeyViolation(err) {
+			// Чаты преподавателя нельзя удалить вместе с ним: в них переписка студентов.
+			// DeleteAccount не даёт удалить такой аккаунт, сюда попадают только помеченные раньше
+			return fmt.Errorf("аккаунт владеет чатами, их должен удалить владелец или администратор: %w", err)
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if err := os.Remove(f.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			s.log.Errorf("Ошибка удаления файла %s: %v", f.Path, err)
+		}
+	}
+	s.log.Infof("Аккаунт %d стёрт", userID)
+	return nil
+}
+
+// ExportData пишет в w ZIP-архив с профилем, сообщениями, загруженными файлами и голосами пользователя.
+func (s *AuthService) ExportData(ctx context.Context, userID int, w io.Writer) error {
+	user, err := s.userRepo.ByID(ctx, userID)
+	if err != nil {
+		s.log.Errorf("userRepo.ByID: %v", err)
+		return fmt.Errorf("не удалось получить пользователя")
+	}
+	if user == nil {
+		return domainUser.ErrUserNotFound
+	}
+	institutionID, groupID, err := s.placement(ctx, user)
+	if err != nil {
+		return err
+	}
+	messages, err := s.exportRepo.Messages(ctx, userID)
+	if err != nil {
+		s.log.Errorf("exportRepo.Messages: %v", err)
+		return fmt.Errorf("не удалось получить сообщения")
+	}
+	files, err := s.exportRepo.Files(ctx, userID)
+	if err != nil {
+		s.log.Errorf("exportRepo.Files: %v", err)
+		return fmt.Errorf("не удалось получить файлы")
+	}
+	votes, err := s.exportRepo.Votes(ctx, userID)
+	if err != nil {
+		s.log.Errorf("exportRepo.Votes: %v", err)
+		return fmt.Errorf("не удалось получить голоса")
+	}
+
+	// Файлы кладутся в files/ под именем "<id>_<имя>", сообщения ссылаются на эти пути
+	byMessage := make(map[int]*domainUser.ExportMessage, len(messages))
+	for _, m := range messages {
+		byMessage[m.ID] = m
+	}
+	archived := make(map[*domainUser.ExportFile]string, len(files))
+	for _, f := range files {
+		name := path.Join("files", fmt.Sprintf("%d_%s", f.ID, filepath.Base(f.Path)))
+		archived[f] = name
+		if m := byMessage[f.MessageID]; m != nil {
+			m.Files = append(m.Files, name)
+		}
+	}
+
+	zw := zip.NewWriter(w)
+	profile := domainUser.ExportProfile{
+		ID:            user.ID,
+		Email:         user.Email,
+		FullName:      user.FullName,
+		IsTeacher:     user.IsTeacher,
+		Role:          user.Role,
+		Locale:        user.Locale,
+		InstitutionID: institutionID,
+		GroupID:       groupID,
+		ExportedAt:    time.Now(),
+	}
+	if messages == nil {
+		messages = []*domainUser.ExportMessage{}
+	}
+	if votes == nil {
+		votes = []*domainUser.ExportVote{}
+	}
+	for name, v := range map[string]interface{}{
+		"profile.json":    profile,
+		"messages.json":   messages,
+		"poll_votes.json": votes,
+	} {
+		if err := writeZipJSON(zw, name, v); err != nil {
+			return err
+		}
+	}
+	for _, f := range files {
+		if err := copyToZip(zw, archived[f], f.Path); err != nil {
+			// Файл мог быть удалён с диска — архив всё равно нужен
+			s.log.Warnf("Файл %s не добавлен в архив пользователя %d: %v", f.Path, userID, err)
+		}
+	}
+	return zw.Close()
+}
+
+func writeZipJSON(zw *zip.Writer, name string, v interface{}) error {
+	fw, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(fw)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func copyToZip(zw *zip.Writer, name, src string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fw, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, f)
+	return err
+}
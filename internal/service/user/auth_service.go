@@ -1,6 +1,8 @@
 package user
 
 import (
+	domainAudit "EduSync/internal/domain/audit"
+	domainRateLimit "EduSync/internal/domain/ratelimit"
 	domainUser "EduSync/internal/domain/user"
 	"EduSync/internal/repository"
 	"EduSync/internal/service"
@@ -22,8 +24,14 @@ type AuthService struct {
 	studentRepo         repository.StudentRepository
 	teacherRepo         repository.TeacherRepository
 	tokenRepo           repository.TokenRepository
+	twoFactorRepo       repository.TwoFactorRepository
+	exportRepo          repository.UserExportRepository
+	emailChangeRepo     repository.EmailChangeRepository
 	instEmailMaskRepo   repository.EmailMaskRepository
 	confirmationService service.ConfirmationService
+	mailer              service.Mailer
+	audit               service.AuditService
+	limiter             service.RateLimiter
 	jwtManager          *util.JWTManager
 	log                 *logrus.Logger
 }
@@ -34,8 +42,14 @@ func NewAuthService(
 	studentRepo repository.StudentRepository,
 	teacherRepo repository.TeacherRepository,
 	tokenRepo repository.TokenRepository,
+	twoFactorRepo repository.TwoFactorRepository,
+	exportRepo repository.UserExportRepository,
+	emailChangeRepo repository.EmailChangeRepository,
 	instEmailMaskRepo repository.EmailMaskRepository,
 	confirmationService service.ConfirmationService,
+	mailer service.Mailer,
+	audit service.AuditService,
+	limiter service.RateLimiter,
 	jwtManager *util.JWTManager,
 	log *logrus.Logger,
 ) service.UserService {
@@ -43,8 +57,14 @@ func NewAuthService(
 		studentRepo:         studentRepo,
 		teacherRepo:         teacherRepo,
 		tokenRepo:           tokenRepo,
+		twoFactorRepo:       twoFactorRepo,
+		exportRepo:          exportRepo,
+		emailChangeRepo:     emailChangeRepo,
 		instEmailMaskRepo:   instEmailMaskRepo,
 		confirmationService: confirmationService,
+		mailer:              mailer,
+		audit:               audit,
+		limiter:             limiter,
 		jwtManager:          jwtManager,
 		log:                 log,
 	}
@@ -66,7 +86,7 @@ func (s *AuthService) Register(ctx context.Context, user domainUser.CreateUser)
 		}
 		if maskValid == nil {
 			s.log.Errorf("Ошибка, маски не сущетсвует: %v", err)
-			return 0, errors.New("email не поддерживается учреждением")
+			return 0, domainUser.ErrEmailNotAllowed
 		}
 	}
 
@@ -102,9 +122,10 @@ func (s *AuthService) Register(ctx context.Context, user domainUser.CreateUser)
 	}()
 
 	// Создаем пользователя.
+	newUser := user.ConvertToUser(hashedPassword)
 	userID, err := s.userRepo.(interface {
 		Create(ctx context.Context, tx *sql.Tx, user *domainUser.User) (int, error)
-	}).Create(ctx, tx, user.ConvertToUser(hashedPassword))
+	}).Create(ctx, tx, newUser)
 	// Если студент, сохраняем данные в таблице студентов
 	if !user.IsTeacher {
 		err = s.studentRepo.(interface {
@@ -119,83 +140,101 @@ func (s *AuthService) Register(ctx context.Context, user domainUser.CreateUser)
 		s.log.Errorf("Ошибка создания пользователя: %v", err)
 		return 0, err
 	}
+	// Письмо активации ставится в очередь в той же транзакции: без него аккаунт не активировать
+	newUser.ID = userID
+	if err = s.confirmationService.IssueCode(ctx, tx, newUser, user.InstitutionID, "register"); err != nil {
+		s.log.Errorf("Ошибка постановки письма активации в очередь: %v", err)
+		return 0, err
+	}
 	// Фиксируем транзакцию
 	if err = tx.Commit(); err != nil {
 		s.log.Errorf("ошибка коммита транзакции: %v", err)
 		return 0, domainUser.ErrInvalidCredentials
 	}
-
-	if err := s.confirmationService.RequestCode(ctx, user.Email, "register"); err != nil {
-		s.log.Errorf("Ошибка отправки письма активации: %v", err)
-		// не фатально — просто логируем
-	}
 	return userID, nil
 }
 
 // Login выполняет авторизацию пользователя: сравнивает пароль и генерирует токены.
-func (s *AuthService) Login(ctx context.Context, email, password, userAgent, ipAddress string) (string, string, error) {
+// Если у пользователя включена 2FA или учреждение требует её для его роли,
+// вместо токенов возвращается Challenge для второго шага входа.
+func (s *AuthService) Login(ctx context.Context, email, password, userAgent, ipAddress string) (*domainUser.LoginResult, error) {
 	s.log.Infof("Регистрация пользователя с email: %s", email)
+	// Неудачные попытки считаются по email, чтобы перебор пароля с разных IP тоже упирался в лимит
+	account := strings.ToLower(strings.TrimSpace(email))
+	if err := s.limiter.Check(ctx, domainRateLimit.LoginAccount, account); err != nil {
+		return nil, err
+	}
 	user, err := s.userRepo.ByEmail(ctx, email)
 	if err != nil {
 		s.log.Errorf("Ошибка поиска пользователя: %v", err)
-		return "", "", err
+		return nil, err
 	}
 	if user == nil {
-		return "", "", errors.New("неверный email или пароль")
+		if err := s.limiter.Hit(ctx, domainRateLimit.LoginAccount, account); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("неверный email или пароль")
 	}
 	if !user.IsActive {
-		return "", "", fmt.Errorf("активируйте аккаунт с помощью email")
+		return nil, fmt.Errorf("активируйте аккаунт с помощью email")
 	}
 	// Сравниваем пароль.
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
 	if err != nil {
 		s.log.Errorf("Ошибка хэширования %v", err)
-		return "", "", errors.New("неверный email или пароль")
+		if err := s.limiter.Hit(ctx, domainRateLimit.LoginAccount, account); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("неверный email или пароль")
+	}
+	if err := s.limiter.Reset(ctx, domainRateLimit.LoginAccount, account); err != nil {
+		s.log.Errorf("Ошибка сброса счётчика попыток: %v", err)
 	}
 
 	// Получаем информацию об учебном заведении и группе
-	var institutionId, groupId int
-
-	if user.IsTeacher {
-		teacher, err := s.teacherRepo.ByUserID(ctx, user.ID)
-		if err != nil {
-			s.log.Errorf("Ошибка получения информации о преподавателе: %v", err)
-			return "", "", err
-		}
-		institutionId = teacher.InstitutionID
-		groupId = 0 // У преподавателя нет группы
-	} else {
-		student, err := s.studentRepo.ByUserID(ctx, user.ID)
-		if err != nil {
-			s.log.Errorf("Ошибка получения информации о студенте: %v", err)
-			return "", "", err
-		}
-		institutionId = student.InstitutionID
-		groupId = student.GroupID
+	institutionId, groupId, err := s.placement(ctx, user)
+	if err != nil {
+		return nil, err
 	}
 
-	// Удаляем предыдущие токены для пользователя, чтобы не было дублирования.
-	if err := s.tokenRepo.DeleteForUser(ctx, user.ID); err != nil {
-		s.log.Errorf("Ошибка удаления токенов: %v", err)
-		return "", "", err
+	challenge, err := s.secondFactorChallenge(ctx, user, institutionId)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &domainUser.LoginResult{Challenge: challenge}, nil
 	}
 
-	// Генерируем access token.
-	accessToken, err := s.jwtManager.GenerateJWT(user.ID, user.IsTeacher, user.Email, user.FullName, institutionId, groupId, time.Hour*24)
+	accessToken, refreshToken, err := s.issueTokens(ctx, user, institutionId, groupId, userAgent, ipAddress)
 	if err != nil {
-		s.log.Errorf("Ошибка генерации токена: %v", err)
+		return nil, err
+	}
+	return &domainUser.LoginResult{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// issueTokens открывает сессию устройства и выдаёт пару токенов.
+func (s *AuthService) issueTokens(ctx context.Context, user *domainUser.User, institutionID, groupID int, userAgent, ipAddress string) (string, string, error) {
+	// Вход в течение срока ожидания отменяет удаление аккаунта
+	if user.DeletedAt != nil {
+		if err := s.restore(ctx, user); err != nil {
+			return "", "", err
+		}
+	}
+	// Повторный вход с того же устройства заменяет его сессию, остальные устройства остаются в системе.
+	if err := s.tokenRepo.DeleteForDevice(ctx, user.ID, userAgent); err != nil {
+		s.log.Errorf("Ошибка удаления токенов: %v", err)
 		return "", "", err
 	}
 
-	// Генерируем refresh token.
-	refreshToken, err := s.jwtManager.GenerateJWT(user.ID, user.IsTeacher, user.Email, user.FullName, institutionId, groupId, 7*24*time.Hour)
+	// Генерируем пару access/refresh токенов.
+	accessToken, refreshToken, err := s.jwtManager.GenerateTokenPair(user.ID, user.IsTeacher, user.Role, user.Email, user.FullName, institutionID, groupID)
 	if err != nil {
-		s.log.Errorf("Ошибка генерации рефреш токена: %v", err)
+		s.log.Errorf("Ошибка генерации токенов: %v", err)
 		return "", "", err
 	}
 
-	// Сохраняем токены в БД.
-	expiresAt := time.Now().Add(7 * 24 * time.Hour)
+	// Сохраняем хэши токенов в БД.
+	expiresAt := time.Now().Add(util.RefreshTokenTTL)
 	if err := s.tokenRepo.Save(ctx, user.ID, accessToken, refreshToken, userAgent, ipAddress, expiresAt); err != nil {
 		s.log.Errorf("Ошибка сохранения токенов: %v", err)
 		return "", "", err
@@ -230,10 +269,14 @@ func (s *AuthService) UpdateProfile(ctx context.Context, u domainUser.UpdateUser
 	updateUser := &domainUser.User{
 		ID:       u.ID,
 		FullName: existing.FullName,
+		Locale:   existing.Locale,
 	}
 	if u.FullName != nil {
 		updateUser.FullName = *u.FullName
 	}
+	if u.Locale != nil {
+		updateUser.Locale = *u.Locale
+	}
 	if err = s.userRepo.Update(ctx, tx, updateUser); err != nil {
 		s.log.Errorf("userRepo.Update: %v", err)
 		return "", "", fmt.Errorf("не удалось обновить пользователя")
@@ -242,9 +285,17 @@ func (s *AuthService) UpdateProfile(ctx context.Context, u domainUser.UpdateUser
 	// 4) обновляем students/teachers
 	if existing.IsTeacher {
 		if u.InstitutionID != nil {
-			if err = s.teacherRepo.Update(ctx, tx, u.ID, *u.InstitutionID); err != nil {
-				s.log.Errorf("teacherRepo.Update: %v", err)
-				return "", "", fmt.Errorf("не удалось обновить данные преподавателя")
+			// Сотрудник получил бы права в выбранном учреждении, а email проверялся бы
+			// по его маскам, поэтому сменить учреждение сам он не может
+			t, err2 := s.teacherRepo.ByUserID(ctx, u.ID)
+			if err2 != nil {
+				s.log.Errorf("teacherRepo.ByUserID: %v", err2)
+				err = fmt.Errorf("не удалось получить данные преподавателя")
+				return "", "", err
+			}
+			if t == nil || t.InstitutionID != *u.InstitutionID {
+				err = domainUser.ErrForbidden
+				return "", "", err
 			}
 		}
 	} else {
@@ -273,57 +324,25 @@ func (s *AuthService) UpdateProfile(ctx context.Context, u domainUser.UpdateUser
 		return "", "", fmt.Errorf("не удалось сохранить изменения")
 	}
 
-	// 6) достаём всё для новой пачки claim’ов
+	// 6) выдаём новую пару с актуальными claim’ами как при входе: сессия этого
+	// устройства заменяется, другие получат новые данные при обновлении токена
 	user, err2 := s.userRepo.ByID(ctx, u.ID)
 	if err2 != nil {
 		return "", "", err2
 	}
-	var institutionID, groupID int
-	if user.IsTeacher {
-		t, _ := s.teacherRepo.ByUserID(ctx, u.ID)
-		institutionID = t.InstitutionID
-	} else {
-		st, _ := s.studentRepo.ByUserID(ctx, u.ID)
-		institutionID = st.InstitutionID
-		groupID = st.GroupID
-	}
-
-	// 7) генерим новые токены
-	newAccess, err := s.jwtManager.GenerateJWT(
-		user.ID,
-		user.IsTeacher,
-		user.Email,
-		user.FullName,
-		institutionID,
-		groupID,
-		24*time.Hour,
-	)
-	if err != nil {
-		s.log.Errorf("GenerateTokens: %v", err)
-		return "", "", fmt.Errorf("не удалось обновить токен")
-	}
-	newRefresh, err := s.jwtManager.GenerateJWT(
-		user.ID,
-		user.IsTeacher,
-		user.Email,
-		user.FullName,
-		institutionID,
-		groupID,
-		7*24*time.Hour,
-	)
-
-	// 8) чистим старые и сохраняем новые в БД
-	if err = s.tokenRepo.DeleteForUser(ctx, user.ID); err != nil {
-		s.log.Errorf("tokenRepo.DeleteForUser: %v", err)
-		return "", "", err
+	if user == nil {
+		return "", "", domainUser.ErrUserNotFound
 	}
-	expiresAt := time.Now().Add(7 * 24 * time.Hour)
-	if err = s.tokenRepo.Save(ctx, user.ID, newAccess, newRefresh, userAgent, ipAddress, expiresAt); err != nil {
-		s.log.Errorf("tokenRepo.Save: %v", err)
-		return "", "", err
+	institutionID, groupID, err2 := s.placement(ctx, user)
+	if err2 != nil {
+		return "", "", err2
 	}
+	return s.issueTokens(ctx, user, institutionID, groupID, userAgent, ipAddress)
+}
 
-	return newAccess, newRefresh, nil
+// PublicKeys возвращает открытые ключи, которыми другие сервисы проверяют токены.
+func (s *AuthService) PublicKeys() domainUser.JWKS {
+	return s.jwtManager.JWKS()
 }
 
 // Logout отзываёт токен: удаляет токен из БД.
@@ -331,44 +350,58 @@ func (s *AuthService) Logout(ctx context.Context, accessToken string) error {
 	return s.tokenRepo.Revoke(ctx, accessToken)
 }
 
-// RefreshToken обновляет access-токен, если refresh-токен валиден.
+// RefreshToken обновляет пару токенов, если refresh-токен валиден.
+// Повторное предъявление уже обменянного refresh-токена завершает всю сессию:
+// значит, токен мог быть украден.
 func (s *AuthService) RefreshToken(ctx context.Context, inputRefreshToken, userAgent, ipAddress string) (string, string, error) {
-	// Проверяем, существует ли refresh-токен в БД (используем отдельную функцию для refresh-токенов)
-	exists, err := s.tokenRepo.IsRefreshValid(ctx, inputRefreshToken)
-	if err != nil || !exists {
-		s.log.Errorf("Ошибка провки токенов или токена нет в БД: %v", err)
-		return "", "", errors.New("недействительный refresh-токен")
-	}
-
-	claims, err := s.jwtManager.ParseJWT(inputRefreshToken, s.log)
+	claims, err := s.jwtManager.ParseTyped(inputRefreshToken, domainUser.TokenRefresh, s.log)
 	if err != nil {
 		return "", "", errors.New("недействительный или просроченный refresh-токен")
 	}
 
-	// Генерируем новый access-токен
-	accessToken, err := s.jwtManager.GenerateJWT(claims.ID, claims.IsTeacher, claims.Email, claims.FullName, claims.InstitutionId, claims.GroupId, time.Hour)
+	// Данные для claims берём из БД: роль и профиль могли измениться
+	// после выдачи refresh-токена, в том числе с другого устройства
+	user, err := s.userRepo.ByID(ctx, claims.ID)
 	if err != nil {
-		s.log.Errorf("Ошибка генерации токенов: %v", err)
+		s.log.Errorf("Ошибка получения пользователя: %v", err)
 		return "", "", err
 	}
-
-	// Генерируем новый refresh-токен
-	newRefreshToken, err := s.jwtManager.GenerateJWT(claims.ID, claims.IsTeacher, claims.Email, claims.FullName, claims.InstitutionId, claims.GroupId, 7*24*time.Hour)
+	if user == nil {
+		return "", "", errors.New("недействительный refresh-токен")
+	}
+	institutionID, groupID, err := s.placement(ctx, user)
 	if err != nil {
-		s.log.Errorf("Ошибка генерации рефреш токена: %v", err)
 		return "", "", err
 	}
 
-	// Обновляем токены в БД: удаляем старые токены и сохраняем новые с актуальными значениями userAgent и ipAddress
-	if err := s.tokenRepo.DeleteForUser(ctx, claims.ID); err != nil {
-		s.log.Errorf("Ошибка удаления токенов: %v", err)
+	accessToken, newRefreshToken, err := s.jwtManager.GenerateTokenPair(user.ID, user.IsTeacher, user.Role, user.Email, user.FullName, institutionID, groupID)
+	if err != nil {
+		s.log.Errorf("Ошибка генерации токенов: %v", err)
 		return "", "", err
 	}
-	expiresAt := time.Now().Add(7 * 24 * time.Hour)
-	if err := s.tokenRepo.Save(ctx, claims.ID, accessToken, newRefreshToken, userAgent, ipAddress, expiresAt); err != nil {
+
+	// Обновляем только сессию, которой принадлежит refresh-токен: остальные устройства не затрагиваются
+	expiresAt := time.Now().Add(util.RefreshTokenTTL)
+	sessionID, err := s.tokenRepo.Rotate(ctx, inputRefreshToken, accessToken, newRefreshToken, ipAddress, expiresAt)
+	if errors.Is(err, domainUser.ErrTokenReuse) {
+		s.log.Warnf("Повторное использование refresh-токена: пользователь %d, сессия %d, IP %s, устройство %q; сессия завершена",
+			user.ID, sessionID, ipAddress, userAgent)
+		actor := domainUser.Actor{ID: user.ID, Role: user.Role, InstitutionID: institutionID}
+		s.audit.Record(ctx, actor, institutionID, domainAudit.EntityUser, user.ID, domainAudit.ActionTokenReuse, map[string]interface{}{
+			"session_id": sessionID,
+			"ip_address": ipAddress,
+			"user_agent": userAgent,
+		})
+		return "", "", errors.New("недействительный refresh-токен")
+	}
+	if err != nil {
 		s.log.Errorf("Ошибка сохранения токена: %v", err)
 		return "", "", err
 	}
+	if sessionID == 0 {
+		// Сессию завершили, пока выпускались новые токены
+		return "", "", errors.New("недействительный refresh-токен")
+	}
 
 	return accessToken, newRefreshToken, nil
 }
@@ -415,35 +448,133 @@ func (s *AuthService) FindTeacherByName(ctx context.Context, teacherStr string)
 	return nil, fmt.Errorf("преподаватель с инициалами %s не найден", teacherStr)
 }
 
-// DeleteAccount удаляет пользователя
-func (s *AuthService) DeleteAccount(ctx context.Context, userID int) error {
-	// Отключаем все токены
-	if err := s.tokenRepo.DeleteForUser(ctx, userID); err != nil {
-		s.log.Errorf("DeleteAccount: ошибка удаленя токенов пользователя: %v", err)
-		return fmt.Errorf("не удалось удалить сессии")
+// ChangeRole назначает пользователю роль.
+// Администратор учреждения управляет ролями только внутри своего учреждения
+// и не может выдавать или отзывать роль системного администратора.
+// После смены роли все сессии пользователя завершаются, чтобы новые токены
+// получили актуальную роль.
+func (s *AuthService) ChangeRole(ctx context.Context, actor domainUser.Actor, targetID int, role domainUser.Role) error {
+	if !actor.Role.Can(domainUser.PermRolesManage) {
+		return domainUser.ErrForbidden
+	}
+	if !role.Valid() {
+		return domainUser.ErrInvalidRole
 	}
 
-	// Удаляем профиль в транзакции
-	tx, err := s.userRepo.BeginTx(ctx)
+	target, err := s.userRepo.ByID(ctx, targetID)
 	if err != nil {
-		s.log.Errorf("DeleteAccount: BeginTx: %v", err)
-		return fmt.Errorf("internal error")
+		s.log.Errorf("ChangeRole: ByID: %v", err)
+		return fmt.Errorf("не удалось получить пользователя")
 	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
+	if target == nil {
+		return domainUser.ErrUserNotFound
+	}
+	// Студент не может стать сотрудником и наоборот: роль не заменяет регистрацию преподавателя
+	if (role == domainUser.RoleStudent) == target.IsTeacher {
+		return domainUser.ErrInvalidRole
+	}
+
+	institutionID, _, err := s.placement(ctx, target)
+	if err != nil {
+		return err
+	}
+	if actor.Role != domainUser.RoleSystemAdmin {
+		if role == domainUser.RoleSystemAdmin || target.Role == domainUser.RoleSystemAdmin {
+			return domainUser.ErrForbidden
 		}
-	}()
+		if !actor.Manages(institutionID) {
+			return domainUser.ErrForbidden
+		}
+	}
 
-	if err = s.userRepo.DeleteByID(ctx, tx, userID); err != nil {
-		s.log.Errorf("DeleteAccount: DeleteByID: %v", err)
-		return fmt.Errorf("не удалось удалить аккаунт")
+	if target.Role == role {
+		return nil
+	}
+	if err := s.userRepo.SetRole(ctx, targetID, role); err != nil {
+		s.log.Errorf("ChangeRole: SetRole: %v", err)
+		return fmt.Errorf("не удалось изменить роль")
 	}
+	s.audit.Record(ctx, actor, institutionID, domainAudit.EntityUser, targetID, domainAudit.ActionRoleChange, map[string]domainUser.Role{
+		"before": target.Role,
+		"after":  role,
+	})
 
-	if err = tx.Commit(); err != nil {
-		s.log.Errorf("DeleteAccount: Commit: %v", err)
-		return fmt.Errorf("internal error")
+	if err := s.tokenRepo.DeleteForUser(ctx, targetID); err != nil {
+		s.log.Errorf("ChangeRole: DeleteForUser: %v", err)
+		return fmt.Errorf("не удалось завершить сессии пользователя")
 	}
+	return nil
+}
 
+// placement возвращает учебное заведение и группу пользователя; у преподавателя группы нет.
+func (s *AuthService) placement(ctx context.Context, u *domainUser.User) (institutionID, groupID int, err error) {
+	if u.IsTeacher {
+		t, err := s.teacherRepo.ByUserID(ctx, u.ID)
+		if err != nil {
+			s.log.Errorf("teacherRepo.ByUserID: %v", err)
+			return 0, 0, fmt.Errorf("не удалось получить данные преподавателя")
+		}
+		if t == nil {
+			return 0, 0, domainUser.ErrUserNotFound
+		}
+		return t.InstitutionID, 0, nil
+	}
+	st, err := s.studentRepo.ByUserID(ctx, u.ID)
+	if err != nil {
+		s.log.Errorf("studentRepo.ByUserID: %v", err)
+		return 0, 0, fmt.Errorf("не удалось получить данные студента")
+	}
+	if st == nil {
+		return 0, 0, domainUser.ErrUserNotFound
+	}
+	return st.InstitutionID, st.GroupID, nil
+}
+
+// Sessions возвращает активные сессии пользователя и отмечает ту, из которой пришёл запрос.
+func (s *AuthService) Sessions(ctx context.Context, userID int, accessToken string) ([]*domainUser.Session, error) {
+	sessions, err := s.tokenRepo.Sessions(ctx, userID)
+	if err != nil {
+		s.log.Errorf("Sessions: %v", err)
+		return nil, fmt.Errorf("не удалось получить сессии")
+	}
+	currentID, err := s.tokenRepo.SessionID(ctx, accessToken)
+	if err != nil {
+		s.log.Errorf("Sessions: SessionID: %v", err)
+		return nil, fmt.Errorf("не удалось получить сессии")
+	}
+	for _, session := range sessions {
+		session.Current = session.ID == currentID
+	}
+	return sessions, nil
+}
+
+// RevokeSession завершает одну сессию пользователя.
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID int) error {
+	deleted, err := s.tokenRepo.DeleteSession(ctx, userID, sessionID)
+	if err != nil {
+		s.log.Errorf("RevokeSession: %v", err)
+		return fmt.Errorf("не удалось завершить сессию")
+	}
+	if !deleted {
+		return domainUser.ErrSessionNotFound
+	}
 	return nil
 }
+
+// RevokeOtherSessions завершает все сессии пользователя, кроме текущей, и возвращает их количество.
+func (s *AuthService) RevokeOtherSessions(ctx context.Context, userID int, accessToken string) (int, error) {
+	currentID, err := s.tokenRepo.SessionID(ctx, accessToken)
+	if err != nil {
+		s.log.Errorf("RevokeOtherSessions: SessionID: %v", err)
+		return 0, fmt.Errorf("не удалось завершить сессии")
+	}
+	if currentID == 0 {
+		return 0, domainUser.ErrSessionNotFound
+	}
+	n, err := s.tokenRepo.DeleteOtherSessions(ctx, userID, currentID)
+	if err != nil {
+		s.log.Errorf("RevokeOtherSessions: %v", err)
+		return 0, fmt.Errorf("не удалось завершить сессии")
+	}
+	return n, nil
+}
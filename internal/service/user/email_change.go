@@ -0,0 +1,186 @@
+package user
+
+import (
+	domainAudit "EduSync/internal/domain/audit"
+	domainEmail "EduSync/internal/domain/email"
+	domainUser "EduSync/internal/domain/user"
+	"EduSync/internal/util"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	emailChangeTTL      = 2 * time.Hour   // срок действия кода смены email
+	emailChangeThrottle = 2 * time.Minute // не чаще одного письма за этот интервал
+	emailChangeAttempts = 5               // неверных попыток до аннулирования кода
+)
+
+// RequestEmailChange отправляет код подтверждения на новый адрес. Email меняется
+// только после ConfirmEmailChange; повторный запрос заменяет предыдущий.
+func (s *AuthService) RequestEmailChange(ctx context.Context, userID int, newEmail, password string) error {
+	user, err := s.userRepo.ByID(ctx, userID)
+	if err != nil {
+		s.log.Errorf("RequestEmailChange: ByID: %v", err)
+		return fmt.Errorf("не удалось получить пользователя")
+	}
+	if user == nil {
+		return domainUser.ErrUserNotFound
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return domainUser.ErrInvalidCredentials
+	}
+
+	newEmail = strings.ToLower(strings.TrimSpace(newEmail))
+	if newEmail == strings.ToLower(user.Email) {
+		return domainUser.ErrSameEmail
+	}
+	taken, err := s.userRepo.ByEmail(ctx, newEmail)
+	if err != nil {
+		s.log.Errorf("RequestEmailChange: ByEmail: %v", err)
+		return fmt.Errorf("не удалось проверить email")
+	}
+	if taken != nil {
+		return domainUser.ErrUserAlreadyExists
+	}
+	if err := s.checkTeacherEmail(ctx, user, newEmail); err != nil {
+		return err
+	}
+
+	last, err := s.emailChangeRepo.CreatedAt(ctx, userID)
+	if err != nil {
+		s.log.Errorf("RequestEmailChange: CreatedAt: %v", err)
+		return fmt.Errorf("не удалось отправить код")
+	}
+	if time.Since(last) < emailChangeThrottle {
+		return domainUser.ErrCodeRecentlySent
+	}
+
+	code, err := util.NewEmailCode()
+	if err != nil {
+		return err
+	}
+	tx, err := s.userRepo.BeginTx(ctx)
+	if err != nil {
+		s.log.Errorf("BeginTx: %v", err)
+		return fmt.Errorf("не удалось начать транзакцию")
+	}
+	defer tx.Rollback()
+
+	if err := s.emailChangeRepo.Create(ctx, tx, userID, newEmail, code, time.Now().Add(emailChangeTTL)); err != nil {
+		s.log.Errorf("RequestEmailChange: %v", err)
+		return fmt.Errorf("не удалось отправить код")
+	}
+	data := domainEmail.Data{Code: code, ExpiresIn: int(emailChangeTTL.Hours())}
+	if err := s.mailer.Send(ctx, tx, newEmail, user, 0, "email_change", data); err != nil {
+		s.log.Errorf("RequestEmailChange: mailer.Send: %v", err)
+		return fmt.Errorf("не удалось отправить код")
+	}
+	if err := tx.Commit(); err != nil {
+		s.log.Errorf("tx.Commit: %v", err)
+		return fmt.Errorf("не удалось отправить код")
+	}
+	return nil
+}
+
+// ConfirmEmailChange меняет email по коду с нового адреса, уведомляет старый адрес
+// и завершает все сессии: email входит в claims токена. Возвращает новую пару токенов
+// для текущего устройства.
+func (s *AuthService) ConfirmEmailChange(ctx context.Context, userID int, code, userAgent, ipAddress string) (string, string, error) {
+	newEmail, err := s.emailChangeRepo.Verify(ctx, userID, code, emailChangeAttempts)
+	if err != nil {
+		s.log.Errorf("ConfirmEmailChange: Verify: %v", err)
+		return "", "", fmt.Errorf("не удалось проверить код")
+	}
+	if newEmail == "" {
+		return "", "", domainUser.ErrInvalidOTP
+	}
+
+	user, err := s.userRepo.ByID(ctx, userID)
+	if err != nil {
+		s.log.Errorf("ConfirmEmailChange: ByID: %v", err)
+		return "", "", fmt.Errorf("не удалось получить пользователя")
+	}
+	if user == nil {
+		return "", "", domainUser.ErrUserNotFound
+	}
+	// Маски и учреждение преподавателя могли измениться, пока письмо шло
+	if err := s.checkTeacherEmail(ctx, user, newEmail); err != nil {
+		return "", "", err
+	}
+
+	tx, err := s.userRepo.BeginTx(ctx)
+	if err != nil {
+		s.log.Errorf("BeginTx: %v", err)
+		return "", "", fmt.Errorf("не удалось начать транзакцию")
+	}
+	defer tx.Rollback()
+
+	if err := s.userRepo.UpdateEmail(ctx, tx, userID, newEmail); err != nil {
+		if errors.Is(err, domainUser.ErrUserAlreadyExists) {
+			return "", "", err
+		}
+		s.log.Errorf("ConfirmEmailChange: UpdateEmail: %v", err)
+		return "", "", fmt.Errorf("не удалось изменить email")
+	}
+	if err := s.emailChangeRepo.Delete(ctx, tx, userID); err != nil {
+		s.log.Errorf("ConfirmEmailChange: Delete: %v", err)
+		return "", "", fmt.Errorf("не удалось изменить email")
+	}
+	data := domainEmail.Data{Vars: map[string]interface{}{"NewEmail": newEmail}}
+	if err := s.mailer.Send(ctx, tx, user.Email, user, 0, "email_changed", data); err != nil {
+		s.log.Errorf("ConfirmEmailChange: mailer.Send: %v", err)
+		return "", "", fmt.Errorf("не удалось изменить email")
+	}
+	if err := tx.Commit(); err != nil {
+		s.log.Errorf("tx.Commit: %v", err)
+		return "", "", fmt.Errorf("не удалось сохранить изменения")
+	}
+
+	oldEmail := user.Email
+	user.Email = newEmail
+	if err := s.tokenRepo.DeleteForUser(ctx, userID); err != nil {
+		s.log.Errorf("ConfirmEmailChange: DeleteForUser: %v", err)
+		return "", "", fmt.Errorf("не удалось завершить сессии")
+	}
+	institutionID, groupID, err := s.placement(ctx, user)
+	if err != nil {
+		return "", "", err
+	}
+	actor := domainUser.Actor{ID: user.ID, Role: user.Role, InstitutionID: institutionID}
+	s.audit.Record(ctx, actor, institutionID, domainAudit.EntityUser, user.ID, domainAudit.ActionEmailChange, map[string]string{
+		"before": oldEmail,
+		"after":  newEmail,
+	})
+	return s.issueTokens(ctx, user, institutionID, groupID, userAgent, ipAddress)
+}
+
+// checkTeacherEmail проверяет, что домен нового email преподавателя зарегистрирован
+// за его учреждением. Для студентов ограничений нет.
+func (s *AuthService) checkTeacherEmail(ctx context.Context, user *domainUser.User, email string) error {
+	if !user.IsTeacher {
+		return nil
+	}
+	mask, err := (&domainUser.CreateUser{Email: email}).EmailMask()
+	if err != nil {
+		return err
+	}
+	emailMask, err := s.instEmailMaskRepo.ByEmailMask(ctx, mask)
+	if err != nil {
+		s.log.Errorf("Ошибка получения маски: %v", err)
+		return fmt.Errorf("не удалось проверить email")
+	}
+	teacher, err := s.teacherRepo.ByUserID(ctx, user.ID)
+	if err != nil {
+		s.log.Errorf("teacherRepo.ByUserID: %v", err)
+		return fmt.Errorf("не удалось получить данные преподавателя")
+	}
+	if emailMask == nil || teacher == nil || emailMask.InstitutionID != teacher.InstitutionID {
+		return domainUser.ErrEmailNotAllowed
+	}
+	return nil
+}
@@ -0,0 +1,446 @@
+package user
+
+import (
+	domainAudit "EduSync/internal/domain/audit"
+	domainInstitution "EduSync/internal/domain/institution"
+	domainRateLimit "EduSync/internal/domain/ratelimit"
+	domainUser "EduSync/internal/domain/user"
+	"EduSync/internal/util"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	totpIssuer          = "EduSync"
+	challengeTTL        = 5 * time.Minute
+	challengeAttempts   = 5 // попыток ввода кода на один вход
+	recoveryCodesCount  = 10
+	twoFactorResetEmail = "two_factor_reset" // действие ConfirmationService для сброса 2FA по почте
+)
+
+// secondFactorChallenge начинает второй шаг входа, если он нужен пользователю.
+func (s *AuthService) secondFactorChallenge(ctx context.Context, user *domainUser.User, institutionID int) (*domainUser.Challenge, error) {
+	tf, err := s.twoFactorRepo.ByUserID(ctx, user.ID)
+	if err != nil {
+		s.log.Errorf("twoFactorRepo.ByUserID: %v", err)
+		return nil, fmt.Errorf("не удалось проверить настройки 2FA")
+	}
+	if tf != nil && tf.Enabled {
+		return s.newChallenge(ctx, user.ID, false)
+	}
+	required, err := s.twoFactorRequired(ctx, user.Role, institutionID)
+	if err != nil {
+		return nil, err
+	}
+	if required {
+		return s.newChallenge(ctx, user.ID, true)
+	}
+	return nil, nil
+}
+
+func (s *AuthService) newChallenge(ctx context.Context, userID int, enrollmentRequired bool) (*domainUser.Challenge, error) {
+	token, err := util.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(challengeTTL)
+	if err := s.twoFactorRepo.CreateChallenge(ctx, util.HashToken(token), userID, expiresAt); err != nil {
+		s.log.Errorf("twoFactorRepo.CreateChallenge: %v", err)
+		return nil, fmt.Errorf("не удалось начать вход")
+	}
+	return &domainUser.Challenge{Token: token, ExpiresAt: expiresAt, EnrollmentRequired: enrollmentRequired}, nil
+}
+
+// challengeUser засчитывает попытку по незавершённому входу и возвращает его пользователя.
+func (s *AuthService) challengeUser(ctx context.Context, challengeToken string) (*domainUser.User, error) {
+	userID, err := s.twoFactorRepo.ChallengeAttempt(ctx, util.HashToken(challengeToken), challengeAttempts)
+	if err != nil {
+		s.log.Errorf("twoFactorRepo.ChallengeAttempt: %v", err)
+		return nil, fmt.Errorf("не удалось проверить вход")
+	}
+	if userID == 0 {
+		return nil, domainUser.ErrChallengeInvalid
+	}
+	user, err := s.userRepo.ByID(ctx, userID)
+	if err != nil {
+		s.log.Errorf("userRepo.ByID: %v", err)
+		return nil, fmt.Errorf("не удалось получить пользователя")
+	}
+	if user == nil {
+		return nil, domainUser.ErrChallengeInvalid
+	}
+	return user, nil
+}
+
+// twoFactorRequired сообщает, требует ли учреждение 2FA для роли.
+func (s *AuthService) twoFactorRequired(ctx context.Context, role domainUser.Role, institutionID int) (bool, error) {
+	roles, err := s.twoFactorRepo.RequiredRoles(ctx, institutionID)
+	if err != nil {
+		s.log.Errorf("twoFactorRepo.RequiredRoles: %v", err)
+		return false, fmt.Errorf("не удалось получить политику 2FA")
+	}
+	for _, r := range roles {
+		if r == role {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// LoginTwoFactor завершает вход кодом из приложения или кодом восстановления.
+// Если 2FA подключалась во время входа, код её подтверждает, а в ответе
+// возвращаются коды восстановления.
+func (s *AuthService) LoginTwoFactor(ctx context.Context, challengeToken, code, userAgent, ipAddress string) (*domainUser.LoginResult, error) {
+	user, err := s.challengeUser(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	tf, err := s.twoFactorRepo.ByUserID(ctx, user.ID)
+	if err != nil {
+		s.log.Errorf("twoFactorRepo.ByUserID: %v", err)
+		return nil, fmt.Errorf("не удалось проверить настройки 2FA")
+	}
+	if tf == nil {
+		return nil, domainUser.ErrTwoFactorNotEnrolled
+	}
+
+	result := &domainUser.LoginResult{}
+	if tf.Enabled {
+		if err := s.verifySecondFactor(ctx, tf, code, true); err != nil {
+			return nil, err
+		}
+	} else {
+		if result.RecoveryCodes, err = s.enable(ctx, tf, code); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.twoFactorRepo.DeleteChallenge(ctx, util.HashToken(challengeToken)); err != nil {
+		s.log.Errorf("twoFactorRepo.DeleteChallenge: %v", err)
+	}
+	institutionID, groupID, err := s.placement(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	result.AccessToken, result.RefreshToken, err = s.issueTokens(ctx, user, institutionID, groupID, userAgent, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// EnrollForChallenge подключает 2FA во время входа, когда учреждение её требует.
+func (s *AuthService) EnrollForChallenge(ctx context.Context, challengeToken string) (*domainUser.TwoFactorEnrollment, error) {
+	user, err := s.challengeUser(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	return s.enroll(ctx, user)
+}
+
+// RequestTwoFactorReset отправляет на почту код для сброса 2FA,
+// если пользователь потерял и приложение, и коды восстановления.
+func (s *AuthService) RequestTwoFactorReset(ctx context.Context, challengeToken string) error {
+	user, err := s.challengeUser(ctx, challengeToken)
+	if err != nil {
+		return err
+	}
+	return s.confirmationService.RequestCode(ctx, user.Email, twoFactorResetEmail)
+}
+
+// ResetTwoFactor отключает 2FA по коду из письма и завершает вход.
+// Если учреждение требует 2FA, вместо токенов возвращается новый Challenge
+// для повторного подключения.
+func (s *AuthService) ResetTwoFactor(ctx context.Context, challengeToken, emailCode, userAgent, ipAddress string) (*domainUser.LoginResult, error) {
+	user, err := s.challengeUser(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.confirmationService.VerifyCode(ctx, user.ID, twoFactorResetEmail, emailCode); err != nil {
+		return nil, domainUser.ErrInvalidOTP
+	}
+	if err := s.twoFactorRepo.Disable(ctx, user.ID); err != nil {
+		s.log.Errorf("twoFactorRepo.Disable: %v", err)
+		return nil, fmt.Errorf("не удалось сбросить 2FA")
+	}
+	if err := s.twoFactorRepo.DeleteChallenge(ctx, util.HashToken(challengeToken)); err != nil {
+		s.log.Errorf("twoFactorRepo.DeleteChallenge: %v", err)
+	}
+
+	institutionID, groupID, err := s.placement(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	s.log.Warnf("2FA пользователя %d сброшена по почте, IP %s", user.ID, ipAddress)
+	actor := domainUser.Actor{ID: user.ID, Role: user.Role, InstitutionID: institutionID}
+	s.audit.Record(ctx, actor, institutionID, domainAudit.EntityUser, user.ID, domainAudit.ActionTwoFactorReset, map[string]string{
+		"ip_address": ipAddress,
+		"user_agent": userAgent,
+	})
+
+	challenge, err := s.secondFactorChallenge(ctx, user, institutionID)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &domainUser.LoginResult{Challenge: challenge}, nil
+	}
+	accessToken, refreshToken, err := s.issueTokens(ctx, user, institutionID, groupID, userAgent, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &domainUser.LoginResult{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// TwoFactorStatus возвращает состояние 2FA пользователя.
+func (s *AuthService) TwoFactorStatus(ctx context.Context, actor domainUser.Actor) (*domainUser.TwoFactorStatus, error) {
+	tf, err := s.twoFactorRepo.ByUserID(ctx, actor.ID)
+	if err != nil {
+		s.log.Errorf("twoFactorRepo.ByUserID: %v", err)
+		return nil, fmt.Errorf("не удалось получить настройки 2FA")
+	}
+	status := &domainUser.TwoFactorStatus{Enabled: tf != nil && tf.Enabled}
+	if status.Required, err = s.twoFactorRequired(ctx, actor.Role, actor.InstitutionID); err != nil {
+		return nil, err
+	}
+	if status.Enabled {
+		if status.RecoveryCodesLeft, err = s.twoFactorRepo.RecoveryCodesLeft(ctx, actor.ID); err != nil {
+			s.log.Errorf("twoFactorRepo.RecoveryCodesLeft: %v", err)
+			return nil, fmt.Errorf("не удалось получить настройки 2FA")
+		}
+	}
+	return status, nil
+}
+
+// EnrollTwoFactor создаёт секрет TOTP. 2FA включится после EnableTwoFactor.
+func (s *AuthService) EnrollTwoFactor(ctx context.Context, userID int) (*domainUser.TwoFactorEnrollment, error) {
+	user, err := s.userRepo.ByID(ctx, userID)
+	if err != nil {
+		s.log.Errorf("userRepo.ByID: %v", err)
+		return nil, fmt.Errorf("не удалось получить пользователя")
+	}
+	if user == nil {
+		return nil, domainUser.ErrUserNotFound
+	}
+	return s.enroll(ctx, user)
+}
+
+func (s *AuthService) enroll(ctx context.Context, user *domainUser.User) (*domainUser.TwoFactorEnrollment, error) {
+	tf, err := s.twoFactorRepo.ByUserID(ctx, user.ID)
+	if err != nil {
+		s.log.Errorf("twoFactorRepo.ByUserID: %v", err)
+		return nil, fmt.Errorf("не удалось получить настройки 2FA")
+	}
+	if tf != nil && tf.Enabled {
+		return nil, domainUser.ErrTwoFactorEnabled
+	}
+	secret, err := util.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactorRepo.SaveSecret(ctx, user.ID, secret); err != nil {
+		s.log.Errorf("twoFactorRepo.SaveSecret: %v", err)
+		return nil, fmt.Errorf("не удалось подключить 2FA")
+	}
+	return &domainUser.TwoFactorEnrollment{
+		Secret: secret,
+		URI:    util.TOTPURI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+// EnableTwoFactor подтверждает подключение первым кодом и выдаёт коды восстановления.
+func (s *AuthService) EnableTwoFactor(ctx context.Context, userID int, code string) ([]string, error) {
+	tf, err := s.twoFactorRepo.ByUserID(ctx, userID)
+	if err != nil {
+		s.log.Errorf("twoFactorRepo.ByUserID: %v", err)
+		return nil, fmt.Errorf("не удалось получить настройки 2FA")
+	}
+	if tf == nil {
+		return nil, domainUser.ErrTwoFactorNotEnrolled
+	}
+	if tf.Enabled {
+		return nil, domainUser.ErrTwoFactorEnabled
+	}
+	return s.enable(ctx, tf, code)
+}
+
+func (s *AuthService) enable(ctx context.Context, tf *domainUser.TwoFactor, code string) ([]string, error) {
+	step, ok := util.ValidateTOTP(tf.Secret, code, time.Now(), tf.LastStep)
+	if !ok {
+		return nil, domainUser.ErrInvalidOTP
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactorRepo.Enable(ctx, tf.UserID, step, hashes); err != nil {
+		s.log.Errorf("twoFactorRepo.Enable: %v", err)
+		return nil, fmt.Errorf("не удалось включить 2FA")
+	}
+	s.log.Infof("Пользователь %d включил 2FA", tf.UserID)
+	return codes, nil
+}
+
+// DisableTwoFactor отключает 2FA после проверки кода, если учреждение её не требует.
+func (s *AuthService) DisableTwoFactor(ctx context.Context, actor domainUser.Actor, code string) error {
+	tf, err := s.enabledTwoFactor(ctx, actor.ID)
+	if err != nil {
+		return err
+	}
+	required, err := s.twoFactorRequired(ctx, actor.Role, actor.InstitutionID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return domainUser.ErrTwoFactorRequired
+	}
+	if err := s.verifySecondFactor(ctx, tf, code, true); err != nil {
+		return err
+	}
+	if err := s.twoFactorRepo.Disable(ctx, actor.ID); err != nil {
+		s.log.Errorf("twoFactorRepo.Disable: %v", err)
+		return fmt.Errorf("не удалось отключить 2FA")
+	}
+	s.log.Infof("Пользователь %d отключил 2FA", actor.ID)
+	return nil
+}
+
+// RegenerateRecoveryCodes выдаёт новые коды восстановления взамен прежних.
+// Нужен код из приложения: кодом восстановления новые коды не получить.
+func (s *AuthService) RegenerateRecoveryCodes(ctx context.Context, userID int, code string) ([]string, error) {
+	tf, err := s.enabledTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifySecondFactor(ctx, tf, code, false); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		s.log.Errorf("twoFactorRepo.ReplaceRecoveryCodes: %v", err)
+		return nil, fmt.Errorf("не удалось обновить коды восстановления")
+	}
+	return codes, nil
+}
+
+func (s *AuthService) enabledTwoFactor(ctx context.Context, userID int) (*domainUser.TwoFactor, error) {
+	tf, err := s.twoFactorRepo.ByUserID(ctx, userID)
+	if err != nil {
+		s.log.Errorf("twoFactorRepo.ByUserID: %v", err)
+		return nil, fmt.Errorf("не удалось получить настройки 2FA")
+	}
+	if tf == nil || !tf.Enabled {
+		return nil, domainUser.ErrTwoFactorNotEnrolled
+	}
+	return tf, nil
+}
+
+// verifySecondFactor проверяет код TOTP, а при allowRecovery — и код восстановления.
+// Неверные коды считаются по пользователю, после лимита ввод временно блокируется.
+func (s *AuthService) verifySecondFactor(ctx context.Context, tf *domainUser.TwoFactor, code string, allowRecovery bool) error {
+	subject := strconv.Itoa(tf.UserID)
+	if err := s.limiter.Check(ctx, domainRateLimit.TwoFactorAccount, subject); err != nil {
+		return err
+	}
+	err := s.checkSecondFactor(ctx, tf, code, allowRecovery)
+	switch {
+	case errors.Is(err, domainUser.ErrInvalidOTP):
+		if err := s.limiter.Hit(ctx, domainRateLimit.TwoFactorAccount, subject); err != nil {
+			return err
+		}
+	case err == nil:
+		if err := s.limiter.Reset(ctx, domainRateLimit.TwoFactorAccount, subject); err != nil {
+			s.log.Errorf("Ошибка сброса счётчика попыток: %v", err)
+		}
+	}
+	return err
+}
+
+// checkSecondFactor сверяет код из приложения или код восстановления.
+func (s *AuthService) checkSecondFactor(ctx context.Context, tf *domainUser.TwoFactor, code string, allowRecovery bool) error {
+	if step, ok := util.ValidateTOTP(tf.Secret, code, time.Now(), tf.LastStep); ok {
+		// Шаг сохраняется атомарно: параллельный запрос с тем же кодом не пройдёт
+		used, err := s.twoFactorRepo.UseStep(ctx, tf.UserID, step)
+		if err != nil {
+			s.log.Errorf("twoFactorRepo.UseStep: %v", err)
+			return fmt.Errorf("не удалось проверить код")
+		}
+		if !used {
+			return domainUser.ErrInvalidOTP
+		}
+		return nil
+	}
+	if !allowRecovery {
+		return domainUser.ErrInvalidOTP
+	}
+	used, err := s.twoFactorRepo.UseRecoveryCode(ctx, tf.UserID, util.HashRecoveryCode(code))
+	if err != nil {
+		s.log.Errorf("twoFactorRepo.UseRecoveryCode: %v", err)
+		return fmt.Errorf("не удалось проверить код")
+	}
+	if !used {
+		return domainUser.ErrInvalidOTP
+	}
+	s.log.Infof("Пользователь %d вошёл по коду восстановления", tf.UserID)
+	return nil
+}
+
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	codes, err = util.GenerateRecoveryCodes(recoveryCodesCount)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, c := range codes {
+		hashes = append(hashes, util.HashRecoveryCode(c))
+	}
+	return codes, hashes, nil
+}
+
+// TwoFactorPolicy возвращает роли, для которых учреждение требует 2FA.
+func (s *AuthService) TwoFactorPolicy(ctx context.Context, actor domainUser.Actor, institutionID int) ([]domainUser.Role, error) {
+	if !actor.Manages(institutionID) {
+		return nil, domainUser.ErrForbidden
+	}
+	return s.twoFactorRepo.RequiredRoles(ctx, institutionID)
+}
+
+// SetTwoFactorPolicy задаёт роли, для которых учреждение требует 2FA.
+// Пользователи этих ролей без 2FA подключат её при следующем входе.
+func (s *AuthService) SetTwoFactorPolicy(ctx context.Context, actor domainUser.Actor, institutionID int, roles []domainUser.Role) ([]domainUser.Role, error) {
+	if !actor.Manages(institutionID) {
+		return nil, domainUser.ErrForbidden
+	}
+	for _, r := range roles {
+		if !r.Valid() {
+			return nil, domainUser.ErrInvalidRole
+		}
+	}
+	before, err := s.twoFactorRepo.RequiredRoles(ctx, institutionID)
+	if err != nil {
+		s.log.Errorf("twoFactorRepo.RequiredRoles: %v", err)
+		return nil, fmt.Errorf("не удалось получить политику 2FA")
+	}
+	if err := s.twoFactorRepo.SetRequiredRoles(ctx, institutionID, roles); err != nil {
+		if errors.Is(err, domainInstitution.ErrNotFound) {
+			return nil, err
+		}
+		s.log.Errorf("twoFactorRepo.SetRequiredRoles: %v", err)
+		return nil, fmt.Errorf("не удалось обновить политику 2FA")
+	}
+	after, err := s.twoFactorRepo.RequiredRoles(ctx, institutionID)
+	if err != nil {
+		s.log.Errorf("twoFactorRepo.RequiredRoles: %v", err)
+		return nil, fmt.Errorf("не удалось получить политику 2FA")
+	}
+	s.audit.Record(ctx, actor, institutionID, domainAudit.EntityInstitution, institutionID, domainAudit.ActionUpdate, map[string][]domainUser.Role{
+		"two_factor_roles_before": before,
+		"two_factor_roles_after":  after,
+	})
+	return after, nil
+}
@@ -2,57 +2,161 @@ package util
 
 import (
 	"EduSync/internal/domain/user"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/sirupsen/logrus"
 	"time"
 )
 
-// JWTManager управляет созданием и верификацией JWT-токенов
-// ТЫ ДУМАЛ ЗДЕСЬ ЧТО НИБУДЬ БУДЕТ? ООО ЧУВАК
+const (
+	// AccessTokenTTL — время жизни access-токена, одинаковое для входа и обновления.
+	AccessTokenTTL = time.Hour
+	// RefreshTokenTTL — время жизни refresh-токена и сессии.
+	RefreshTokenTTL = 7 * 24 * time.Hour
+)
+
+// ErrWrongTokenType возвращается, если вместо access-токена передан refresh-токен и наоборот.
+var ErrWrongTokenType = errors.New("неверный тип токена")
+
+// JWTManager управляет созданием и верификацией JWT-токенов.
+// Подписывает либо общим секретом HS256, либо ключами из KeySet (RS256/EdDSA).
 type JWTManager struct {
 	secretKey []byte
+	keys      *KeySet // nil — подпись общим секретом
 }
 
-// NewJWTManager создаёт новый экземпляр JWTManager
+// NewJWTManager создаёт JWTManager, подписывающий токены общим секретом HS256.
 func NewJWTManager(secretKey []byte) *JWTManager {
 	return &JWTManager{secretKey: secretKey}
 }
 
-// GenerateJWT генерирует JWT с заданным временем жизни.
-func (jm *JWTManager) GenerateJWT(
+// NewKeySetJWTManager создаёт JWTManager, подписывающий токены ключами набора
+// с заголовком kid.
+func NewKeySetJWTManager(keys *KeySet) *JWTManager {
+	return &JWTManager{keys: keys}
+}
+
+// GenerateTokenPair выпускает access- и refresh-токен для пользователя.
+func (jm *JWTManager) GenerateTokenPair(
 	id int,
 	isTeacher bool,
+	role user.Role,
 	email, fullName string,
 	institutionId, groupId int,
-	expiresIn time.Duration,
-) (string, error) {
-	expirationTime := time.Now().Add(expiresIn)
-	claims := &user.TokenClaims{
+) (accessToken, refreshToken string, err error) {
+	claims := user.TokenClaims{
 		ID:            id,
 		IsTeacher:     isTeacher,
+		Role:          role,
 		Email:         email,
 		FullName:      fullName,
 		InstitutionId: institutionId,
 		GroupId:       groupId,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-		},
+	}
+	if accessToken, err = jm.GenerateJWT(claims, user.TokenAccess, AccessTokenTTL); err != nil {
+		return "", "", err
+	}
+	if refreshToken, err = jm.GenerateJWT(claims, user.TokenRefresh, RefreshTokenTTL); err != nil {
+		return "", "", err
+	}
+	return accessToken, refreshToken, nil
+}
+
+// GenerateJWT генерирует JWT заданного типа с заданным временем жизни.
+// Каждый токен получает случайный jti, поэтому два токена с одинаковыми
+// данными, выпущенные в одну секунду, всё равно различаются.
+func (jm *JWTManager) GenerateJWT(claims user.TokenClaims, tokenType user.TokenType, expiresIn time.Duration) (string, error) {
+	jti, err := randomID()
+	if err != nil {
+		return "", err
+	}
+	claims.Type = tokenType
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        jti,
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jm.secretKey)
+	if jm.keys == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, &claims)
+		return token.SignedString(jm.secretKey)
+	}
+	key := jm.keys.currentKey()
+	token := jwt.NewWithClaims(key.method, &claims)
+	token.Header["kid"] = key.id
+	return token.SignedString(key.private)
 }
 
 // ParseJWT разбирает и проверяет токен.
 func (jm *JWTManager) ParseJWT(tokenStr string, log *logrus.Logger) (*user.TokenClaims, error) {
-	log.Infof("Парсинг JWT: %s", tokenStr)
+	log.Debug("Парсинг JWT")
 	claims := &user.TokenClaims{}
-	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
+	token, err := jwt.ParseWithClaims(tokenStr, claims, jm.verificationKey, jwt.WithExpirationRequired())
+	if err != nil {
+		log.Debugf("Ошибка проверки токена JWT: %s", err)
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	return claims, nil
+}
+
+// verificationKey подбирает ключ проверки подписи. Алгоритм токена должен
+// совпадать с алгоритмом ключа, иначе открытый ключ можно было бы выдать за секрет HS256.
+func (jm *JWTManager) verificationKey(token *jwt.Token) (interface{}, error) {
+	if jm.keys == nil {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, jwt.ErrTokenSignatureInvalid
+		}
 		return jm.secretKey, nil
-	})
-	if err != nil || !token.Valid || claims.ExpiresAt.Time.Before(time.Now()) {
-		log.Errorf("Ошибка проверки токена JWT: %s", err)
+	}
+	kid, _ := token.Header["kid"].(string)
+	key := jm.keys.key(kid)
+	if key == nil {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, jwt.ErrTokenSignatureInvalid
+	}
+	return key.private.Public(), nil
+}
+
+// JWKS возвращает открытые ключи проверки токенов. При подписи общим секретом набор пуст.
+func (jm *JWTManager) JWKS() user.JWKS {
+	if jm.keys == nil {
+		return user.JWKS{Keys: []user.JWK{}}
+	}
+	return jm.keys.JWKS()
+}
+
+// ParseTyped разбирает токен и проверяет, что он нужного типа:
+// refresh-токен нельзя использовать для доступа к API, а access-токен — для обновления.
+func (jm *JWTManager) ParseTyped(tokenStr string, tokenType user.TokenType, log *logrus.Logger) (*user.TokenClaims, error) {
+	claims, err := jm.ParseJWT(tokenStr, log)
+	if err != nil {
 		return nil, err
 	}
+	if claims.Type != tokenType {
+		log.Warnf("Передан токен типа %q вместо %q, пользователь %d", claims.Type, tokenType, claims.ID)
+		return nil, ErrWrongTokenType
+	}
 	return claims, nil
 }
+
+// HashToken возвращает SHA-256 токена: в БД хранятся только хэши.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,345 @@
+package util
+
+import (
+	"EduSync/internal/domain/user"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// Алгоритмы подписи JWT.
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// ErrUnknownKey возвращается, если токен подписан ключом, которого нет в наборе.
+var ErrUnknownKey = errors.New("неизвестный ключ подписи")
+
+const (
+	keyFileExt = ".pem"
+	// keyCreatedHeader — PEM-заголовок с временем создания ключа: время изменения
+	// файла сбрасывается при копировании или восстановлении каталога.
+	keyCreatedHeader = "Created-At"
+	// keyIDTimeLayout — формат метки времени в начале kid.
+	keyIDTimeLayout = "20060102T150405"
+	// keyReloadInterval ограничивает перечитывание каталога при встрече неизвестного kid.
+	keyReloadInterval = 10 * time.Second
+)
+
+// signingKey — закрытый ключ из каталога ключей.
+type signingKey struct {
+	id        string
+	method    jwt.SigningMethod
+	private   crypto.Signer
+	createdAt time.Time
+}
+
+// KeySet хранит ключи подписи из каталога: по одному PEM-файлу (PKCS#8) на ключ,
+// имя файла без расширения — kid. Подписывает самый новый ключ выбранного
+// алгоритма, проверять токены можно любым ключом из каталога.
+// Каталог можно разделять между несколькими экземплярами сервера.
+type KeySet struct {
+	mu      sync.RWMutex
+	dir     string
+	alg     string
+	keys    map[string]*signingKey
+	current *signingKey
+	log     *logrus.Logger
+
+	reloadMu   sync.Mutex
+	lastReload time.Time
+}
+
+// LoadKeySet загружает ключи из dir. Если ключей алгоритма alg нет, создаёт новый.
+func LoadKeySet(dir, alg string, log *logrus.Logger) (*KeySet, error) {
+	if alg != AlgRS256 && alg != AlgEdDSA {
+		return nil, fmt.Errorf("неподдерживаемый алгоритм подписи: %s", alg)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("ошибка создания каталога ключей: %w", err)
+	}
+	ks := &KeySet{dir: dir, alg: alg, log: log}
+	if err := ks.Reload(); err != nil {
+		return nil, err
+	}
+	if ks.currentKey() == nil {
+		if err := ks.generate(); err != nil {
+			return nil, err
+		}
+	}
+	return ks, nil
+}
+
+// Reload перечитывает каталог ключей, чтобы подхватить ключи других экземпляров.
+func (ks *KeySet) Reload() error {
+	entries, err := os.ReadDir(ks.dir)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения каталога ключей: %w", err)
+	}
+	keys := make(map[string]*signingKey, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != keyFileExt {
+			continue
+		}
+		k, err := readKey(filepath.Join(ks.dir, e.Name()))
+		if err != nil {
+			return fmt.Errorf("ключ %s: %w", e.Name(), err)
+		}
+		keys[k.id] = k
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys = keys
+	ks.current = nil
+	for _, k := range keys {
+		if k.method.Alg() == ks.alg && (ks.current == nil || k.createdAt.After(ks.current.createdAt)) {
+			ks.current = k
+		}
+	}
+	return nil
+}
+
+// currentKey возвращает ключ, которым подписываются новые токены.
+func (ks *KeySet) currentKey() *signingKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.current
+}
+
+// key возвращает ключ по kid. Неизвестный kid мог появиться после ротации
+// на другом экземпляре, поэтому каталог перечитывается — не чаще keyReloadInterval,
+// чтобы токены с произвольным kid не заставляли читать диск на каждый запрос.
+func (ks *KeySet) key(kid string) *signingKey {
+	ks.mu.RLock()
+	k := ks.keys[kid]
+	ks.mu.RUnlock()
+	if k != nil || !ks.reloadAllowed() {
+		return k
+	}
+	if err := ks.Reload(); err != nil {
+		ks.log.Errorf("Ошибка перечитывания ключей JWT: %v", err)
+		return nil
+	}
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.keys[kid]
+}
+
+// reloadAllowed сообщает, можно ли перечитать каталог из-за неизвестного kid.
+func (ks *KeySet) reloadAllowed() bool {
+	ks.reloadMu.Lock()
+	defer ks.reloadMu.Unlock()
+	if time.Since(ks.lastReload) < keyReloadInterval {
+		return false
+	}
+	ks.lastReload = time.Now()
+	return true
+}
+
+// Rotate создаёт новый ключ подписи, если текущему больше interval,
+// и удаляет ключи, которыми уже не может быть подписан ни один действующий токен.
+func (ks *KeySet) Rotate(interval time.Duration) error {
+	if err := ks.Reload(); err != nil {
+		return err
+	}
+	if cur := ks.currentKey(); cur == nil || time.Since(cur.createdAt) >= interval {
+		if err := ks.generate(); err != nil {
+			return err
+		}
+	}
+	return ks.prune(interval)
+}
+
+// rotationCheck возвращает период проверки ротации для interval.
+func rotationCheck(interval time.Duration) time.Duration {
+	if interval < time.Hour {
+		return interval
+	}
+	return time.Hour
+}
+
+// StartRotation запускает периодическую ротацию ключей.
+func (ks *KeySet) StartRotation(interval time.Duration) {
+	check := rotationCheck(interval)
+	go func() {
+		for {
+			time.Sleep(check)
+			if err := ks.Rotate(interval); err != nil {
+				ks.log.Errorf("Ошибка ротации ключей JWT: %v", err)
+			}
+		}
+	}()
+}
+
+// JWKS возвращает открытые части всех ключей набора.
+func (ks *KeySet) JWKS() user.JWKS {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	set := user.JWKS{Keys: make([]user.JWK, 0, len(ks.keys))}
+	for _, k := range ks.keys {
+		jwk := user.JWK{Kid: k.id, Use: "sig", Alg: k.method.Alg()}
+		switch pub := k.private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+// generate создаёт ключ алгоритма набора, сохраняет его в каталог и делает текущим.
+func (ks *KeySet) generate() error {
+	var private crypto.Signer
+	var err error
+	switch ks.alg {
+	case AlgRS256:
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case AlgEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка генерации ключа: %w", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования ключа: %w", err)
+	}
+	suffix, err := randomID()
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	id := now.Format(keyIDTimeLayout) + "-" + suffix[:4]
+
+	// Пишем во временный файл и переименовываем, чтобы другие экземпляры
+	// не прочитали ключ наполовину
+	path := filepath.Join(ks.dir, id+keyFileExt)
+	tmp := path + ".tmp"
+	data := pem.EncodeToMemory(&pem.Block{
+		Type:    "PRIVATE KEY",
+		Headers: map[string]string{keyCreatedHeader: now.Format(time.RFC3339)},
+		Bytes:   der,
+	})
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("ошибка сохранения ключа: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("ошибка сохранения ключа: %w", err)
+	}
+
+	k, err := readKey(path)
+	if err != nil {
+		return err
+	}
+	ks.mu.Lock()
+	if ks.keys == nil {
+		ks.keys = make(map[string]*signingKey)
+	}
+	ks.keys[k.id] = k
+	ks.current = k
+	ks.mu.Unlock()
+
+	ks.log.Infof("Создан ключ подписи JWT %s (%s)", k.id, ks.alg)
+	return nil
+}
+
+// prune удаляет ключи старше interval + RefreshTokenTTL с запасом на период проверки:
+// ключ подписывает токены не дольше interval (пока другие экземпляры не заметят
+// сменщика), а подписанное им истекает через RefreshTokenTTL.
+func (ks *KeySet) prune(interval time.Duration) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	retention := interval + rotationCheck(interval) + RefreshTokenTTL
+	for id, k := range ks.keys {
+		if k == ks.current || time.Since(k.createdAt) <= retention {
+			continue
+		}
+		if err := os.Remove(filepath.Join(ks.dir, id+keyFileExt)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("ошибка удаления ключа %s: %w", id, err)
+		}
+		delete(ks.keys, id)
+		ks.log.Infof("Ключ подписи JWT %s выведен из оборота", id)
+	}
+	return nil
+}
+
+// readKey читает закрытый ключ PKCS#8 из PEM-файла.
+func readKey(path string) (*signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("файл не содержит PEM-блока")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &signingKey{id: strings.TrimSuffix(filepath.Base(path), keyFileExt)}
+	if k.createdAt, err = keyCreatedAt(path, k.id, block); err != nil {
+		return nil, err
+	}
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		k.method, k.private = jwt.SigningMethodRS256, key
+	case ed25519.PrivateKey:
+		k.method, k.private = jwt.SigningMethodEdDSA, key
+	default:
+		return nil, errors.New("поддерживаются только ключи RSA и Ed25519")
+	}
+	return k, nil
+}
+
+// keyCreatedAt определяет время создания ключа: из PEM-заголовка, для ключей
+// без заголовка — из метки времени в kid. На время изменения файла опираются
+// только ключи, положенные в каталог вручную под произвольным именем.
+func keyCreatedAt(path, id string, block *pem.Block) (time.Time, error) {
+	if v, ok := block.Headers[keyCreatedHeader]; ok {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("некорректный заголовок %s: %w", keyCreatedHeader, err)
+		}
+		return t, nil
+	}
+	if stamp, _, ok := strings.Cut(id, "-"); ok {
+		if t, err := time.Parse(keyIDTimeLayout, stamp); err == nil {
+			return t, nil
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
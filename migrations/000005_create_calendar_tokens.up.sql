@@ -0,0 +1,10 @@
+-- ================================================
+-- Секретные токены для подписки на календарь (iCalendar)
+-- ================================================
+CREATE TABLE calendar_tokens
+(
+    user_id    INT PRIMARY KEY,
+    token      VARCHAR(64) UNIQUE NOT NULL,
+    created_at TIMESTAMP          NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
@@ -0,0 +1,18 @@
+-- ================================================
+-- История изменений расписания
+-- ================================================
+CREATE TABLE schedule_changes
+(
+    id          SERIAL PRIMARY KEY,
+    group_id    INT         NOT NULL,
+    date        DATE        NOT NULL,
+    pair_number INT         NOT NULL,
+    change_type VARCHAR(10) NOT NULL,
+    old_data    JSONB,
+    new_data    JSONB,
+    description TEXT        NOT NULL,
+    created_at  TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (group_id) REFERENCES groups (id) ON DELETE CASCADE
+);
+
+CREATE INDEX schedule_changes_group_idx ON schedule_changes (group_id, created_at DESC);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS schedule_imports;
+
+ALTER TABLE institutions
+    DROP COLUMN IF EXISTS provider_config,
+    DROP COLUMN IF EXISTS schedule_provider;
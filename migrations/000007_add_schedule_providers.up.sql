@@ -0,0 +1,30 @@
+-- ================================================
+-- Источники расписания учебных заведений
+-- ================================================
+ALTER TABLE institutions
+    ADD COLUMN schedule_provider VARCHAR(32),
+    ADD COLUMN provider_config   JSONB NOT NULL DEFAULT '{}';
+
+UPDATE institutions
+SET schedule_provider = 'rksi'
+WHERE name = 'rk';
+
+-- ================================================
+-- Расписание, загруженное из шаблона CSV/XLSX
+-- ================================================
+CREATE TABLE schedule_imports
+(
+    id             SERIAL PRIMARY KEY,
+    institution_id INT          NOT NULL,
+    group_name     VARCHAR(255) NOT NULL,
+    date           DATE         NOT NULL,
+    start_time     TIME         NOT NULL,
+    end_time       TIME         NOT NULL,
+    discipline     VARCHAR(255) NOT NULL,
+    teacher        VARCHAR(255) NOT NULL DEFAULT '',
+    classroom      VARCHAR(255) NOT NULL DEFAULT '',
+    uploaded_at    TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (institution_id) REFERENCES institutions (id) ON DELETE CASCADE
+);
+
+CREATE INDEX schedule_imports_group_idx ON schedule_imports (institution_id, group_name);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS schedule_classroom_date_idx;
+DROP TABLE IF EXISTS classrooms;
@@ -0,0 +1,24 @@
+-- ================================================
+-- Справочник аудиторий
+-- ================================================
+CREATE TABLE classrooms
+(
+    id             SERIAL PRIMARY KEY,
+    institution_id INT          NOT NULL,
+    name           VARCHAR(50)  NOT NULL,
+    building       VARCHAR(255) NOT NULL DEFAULT '',
+    capacity       INT,
+    FOREIGN KEY (institution_id) REFERENCES institutions (id) ON DELETE CASCADE,
+    UNIQUE (institution_id, name)
+);
+
+-- Заполняем справочник аудиториями из уже сохранённого расписания
+INSERT INTO classrooms (institution_id, name)
+SELECT DISTINCT g.institution_id, s.classroom
+FROM schedule s
+         JOIN groups g ON g.id = s.group_id
+WHERE s.classroom <> ''
+  AND s.classroom <> '-'
+ON CONFLICT (institution_id, name) DO NOTHING;
+
+CREATE INDEX schedule_classroom_date_idx ON schedule (classroom, date);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS schedule_teacher_date_idx;
+DROP TABLE IF EXISTS bell_schedule;
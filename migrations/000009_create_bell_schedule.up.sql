@@ -0,0 +1,15 @@
+-- ================================================
+-- Расписание звонков учебного заведения
+-- ================================================
+CREATE TABLE bell_schedule
+(
+    institution_id INT  NOT NULL,
+    pair_number    INT  NOT NULL,
+    start_time     TIME NOT NULL,
+    end_time       TIME NOT NULL,
+    PRIMARY KEY (institution_id, pair_number),
+    FOREIGN KEY (institution_id) REFERENCES institutions (id) ON DELETE CASCADE,
+    CHECK (end_time > start_time)
+);
+
+CREATE INDEX schedule_teacher_date_idx ON schedule (teacher_initials_id, date);
@@ -0,0 +1,15 @@
+-- ================================================
+-- Роли пользователей: student, teacher, institution_admin, system_admin.
+-- Первого системного администратора назначают вручную:
+--   UPDATE users SET role = 'system_admin' WHERE email = '...';
+-- ================================================
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(32) NOT NULL DEFAULT 'student';
+
+UPDATE users
+SET role = 'teacher'
+WHERE is_teacher;
+
+ALTER TABLE users
+    ADD CONSTRAINT users_role_check
+        CHECK (role IN ('student', 'teacher', 'institution_admin', 'system_admin'));
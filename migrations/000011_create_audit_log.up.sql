@@ -0,0 +1,19 @@
+-- ================================================
+-- Журнал административных действий: кто, что и когда изменил
+-- ================================================
+CREATE TABLE audit_log
+(
+    id             SERIAL PRIMARY KEY,
+    user_id        INT,
+    institution_id INT,
+    entity         VARCHAR(32) NOT NULL,
+    entity_id      INT         NOT NULL,
+    action         VARCHAR(32) NOT NULL,
+    details        JSONB       NOT NULL DEFAULT '{}',
+    created_at     TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL,
+    FOREIGN KEY (institution_id) REFERENCES institutions (id) ON DELETE SET NULL
+);
+
+CREATE INDEX audit_log_institution_idx ON audit_log (institution_id, created_at DESC);
+CREATE INDEX audit_log_entity_idx ON audit_log (entity, entity_id);
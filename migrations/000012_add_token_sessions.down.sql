@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS tokens_refresh_idx;
+DROP INDEX IF EXISTS tokens_access_idx;
+DROP INDEX IF EXISTS tokens_user_idx;
+
+ALTER TABLE tokens
+    DROP COLUMN IF EXISTS last_used_at;
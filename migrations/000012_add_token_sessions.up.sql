@@ -0,0 +1,9 @@
+-- ================================================
+-- Каждая строка tokens — сессия одного устройства пользователя
+-- ================================================
+ALTER TABLE tokens
+    ADD COLUMN last_used_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX tokens_user_idx ON tokens (user_id);
+CREATE INDEX tokens_access_idx ON tokens (access_token);
+CREATE INDEX tokens_refresh_idx ON tokens (refresh_token);
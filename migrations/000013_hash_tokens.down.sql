@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS used_refresh_tokens;
+
+DELETE FROM tokens;
+
+DROP INDEX IF EXISTS tokens_refresh_hash_idx;
+DROP INDEX IF EXISTS tokens_access_hash_idx;
+
+ALTER TABLE tokens
+    DROP COLUMN access_hash,
+    DROP COLUMN refresh_hash,
+    ADD COLUMN access_token  TEXT NOT NULL,
+    ADD COLUMN refresh_token TEXT NOT NULL;
+
+CREATE INDEX tokens_access_idx ON tokens (access_token);
+CREATE INDEX tokens_refresh_idx ON tokens (refresh_token);
@@ -0,0 +1,33 @@
+-- ================================================
+-- Токены хранятся только в виде SHA-256.
+-- Сырые токены захэшировать нельзя без их типа и подписи, поэтому
+-- действующие сессии сбрасываются: пользователи войдут заново.
+-- ================================================
+DELETE FROM tokens;
+
+DROP INDEX IF EXISTS tokens_access_idx;
+DROP INDEX IF EXISTS tokens_refresh_idx;
+
+ALTER TABLE tokens
+    DROP COLUMN access_token,
+    DROP COLUMN refresh_token,
+    ADD COLUMN access_hash  CHAR(64) NOT NULL,
+    ADD COLUMN refresh_hash CHAR(64) NOT NULL;
+
+CREATE UNIQUE INDEX tokens_access_hash_idx ON tokens (access_hash);
+CREATE UNIQUE INDEX tokens_refresh_hash_idx ON tokens (refresh_hash);
+
+-- ================================================
+-- Семейство refresh-токенов — это сессия (строка tokens): при обновлении
+-- её токены заменяются, а прежний refresh-токен запоминается здесь.
+-- Повторное предъявление такого токена означает утечку, и сессия отзывается.
+-- ================================================
+CREATE TABLE used_refresh_tokens
+(
+    refresh_hash CHAR(64) PRIMARY KEY,
+    session_id   INT       NOT NULL,
+    used_at      TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (session_id) REFERENCES tokens (id) ON DELETE CASCADE
+);
+
+CREATE INDEX used_refresh_tokens_session_idx ON used_refresh_tokens (session_id);
@@ -0,0 +1,48 @@
+-- ================================================
+-- Двухфакторная аутентификация (TOTP, RFC 6238).
+-- Секрет сохраняется при подключении и начинает действовать
+-- после подтверждения первым кодом (enabled = TRUE).
+-- ================================================
+CREATE TABLE user_two_factor
+(
+    user_id    INT PRIMARY KEY,
+    secret     VARCHAR(64) NOT NULL,
+    enabled    BOOLEAN     NOT NULL DEFAULT FALSE,
+    last_step  BIGINT      NOT NULL DEFAULT 0, -- последний принятый шаг TOTP, защита от повтора кода
+    created_at TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    enabled_at TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+-- Одноразовые коды восстановления, хранятся только SHA-256
+CREATE TABLE two_factor_recovery_codes
+(
+    id        SERIAL PRIMARY KEY,
+    user_id   INT      NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at   TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    UNIQUE (user_id, code_hash)
+);
+
+-- Первый шаг входа с 2FA: пароль проверен, ждём код
+CREATE TABLE two_factor_challenges
+(
+    token_hash CHAR(64) PRIMARY KEY,
+    user_id    INT       NOT NULL,
+    attempts   INT       NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX two_factor_challenges_user_idx ON two_factor_challenges (user_id);
+
+-- Роли, для которых учреждение требует 2FA
+CREATE TABLE two_factor_policies
+(
+    institution_id INT         NOT NULL,
+    role           VARCHAR(32) NOT NULL,
+    PRIMARY KEY (institution_id, role),
+    FOREIGN KEY (institution_id) REFERENCES institutions (id) ON DELETE CASCADE,
+    CHECK (role IN ('student', 'teacher', 'institution_admin', 'system_admin'))
+);
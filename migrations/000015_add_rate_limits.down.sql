@@ -0,0 +1,4 @@
+ALTER TABLE email_confirmations
+    DROP COLUMN IF EXISTS attempts;
+
+DROP TABLE IF EXISTS rate_limits;
@@ -0,0 +1,21 @@
+-- ================================================
+-- Счётчики попыток для ограничения частоты запросов
+-- (используются при RATE_LIMIT_BACKEND=postgres).
+-- ================================================
+CREATE TABLE rate_limits
+(
+    key          VARCHAR(255) PRIMARY KEY, -- политика и субъект: "login_ip:10.0.0.1"
+    count        INT          NOT NULL DEFAULT 0,
+    window_start TIMESTAMP,
+    strikes      INT          NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP,
+    updated_at   TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX rate_limits_updated_idx ON rate_limits (updated_at);
+
+-- ================================================
+-- Неверные попытки ввода кода: после нескольких ошибок код перестаёт действовать
+-- ================================================
+ALTER TABLE email_confirmations
+    ADD COLUMN attempts INT NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+ALTER TABLE institutions
+    DROP COLUMN IF EXISTS brand_color,
+    DROP COLUMN IF EXISTS logo_url;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS locale;
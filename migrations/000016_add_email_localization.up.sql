@@ -0,0 +1,13 @@
+-- ================================================
+-- Язык писем пользователя
+-- ================================================
+ALTER TABLE users
+    ADD COLUMN locale VARCHAR(2) NOT NULL DEFAULT 'ru'
+        CHECK (locale IN ('ru', 'en'));
+
+-- ================================================
+-- Оформление писем учреждения
+-- ================================================
+ALTER TABLE institutions
+    ADD COLUMN logo_url    TEXT,
+    ADD COLUMN brand_color VARCHAR(7); -- "#1a73e8"
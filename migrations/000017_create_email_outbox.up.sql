@@ -0,0 +1,25 @@
+-- ================================================
+-- Очередь исходящих писем. Письмо записывается в той же транзакции,
+-- что и изменение, из-за которого оно отправляется; доставляет его фоновый обработчик.
+-- ================================================
+CREATE TABLE email_outbox
+(
+    id              SERIAL PRIMARY KEY,
+    user_id         INT          REFERENCES users (id) ON DELETE SET NULL,
+    institution_id  INT          REFERENCES institutions (id) ON DELETE SET NULL,
+    template        VARCHAR(64)  NOT NULL,
+    recipient       VARCHAR(255) NOT NULL,
+    subject         TEXT         NOT NULL,
+    body_text       TEXT         NOT NULL,
+    body_html       TEXT         NOT NULL DEFAULT '',
+    status          VARCHAR(16)  NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts        INT          NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error      TEXT,
+    created_at      TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at         TIMESTAMP
+);
+
+CREATE INDEX email_outbox_pending_idx ON email_outbox (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX email_outbox_failed_idx ON email_outbox (institution_id, created_at) WHERE status = 'failed';
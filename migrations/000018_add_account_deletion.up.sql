@@ -0,0 +1,8 @@
+-- ================================================
+-- Мягкое удаление аккаунта: в течение срока ожидания вход восстанавливает аккаунт,
+-- после него данные удаляет фоновый обработчик.
+-- ================================================
+ALTER TABLE users
+    ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX users_deleted_at_idx ON users (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,14 @@
+-- ================================================
+-- Запросы на смену email: код отправляется на новый адрес,
+-- адрес меняется только после его подтверждения.
+-- ================================================
+CREATE TABLE email_changes
+(
+    user_id    INT PRIMARY KEY,
+    new_email  VARCHAR(255) NOT NULL,
+    code       CHAR(6)      NOT NULL,
+    attempts   INT          NOT NULL DEFAULT 0,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP    NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
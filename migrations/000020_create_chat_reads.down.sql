@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_messages_chat_id;
+DROP TABLE IF EXISTS chat_reads;
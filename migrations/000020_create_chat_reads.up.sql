@@ -0,0 +1,17 @@
+-- ================================================
+-- Курсоры прочтения: последнее прочитанное сообщение
+-- каждого участника в каждом чате.
+-- ================================================
+CREATE TABLE chat_reads
+(
+    chat_id              INT       NOT NULL,
+    user_id              INT       NOT NULL,
+    last_read_message_id INT       NOT NULL,
+    read_at              TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (chat_id, user_id),
+    FOREIGN KEY (chat_id) REFERENCES chats (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+-- Непрочитанные и последнее сообщение считаются по чату в порядке id
+CREATE INDEX idx_messages_chat_id ON messages (chat_id, id);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_messages_chat_version;
+
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS version,
+    DROP COLUMN IF EXISTS deleted_at,
+    DROP COLUMN IF EXISTS edited_at;
+
+DROP SEQUENCE IF EXISTS messages_version_seq;
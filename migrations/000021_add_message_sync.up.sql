@@ -0,0 +1,15 @@
+-- ================================================
+-- Синхронизация сообщений: каждое создание, правка и удаление
+-- получает новую версию из общей последовательности; клиент
+-- запрашивает изменения после последней известной ему версии.
+-- ================================================
+CREATE SEQUENCE messages_version_seq;
+
+ALTER TABLE messages
+    ADD COLUMN edited_at  TIMESTAMP,
+    ADD COLUMN deleted_at TIMESTAMP,
+    ADD COLUMN version    BIGINT NOT NULL DEFAULT nextval('messages_version_seq');
+
+ALTER SEQUENCE messages_version_seq OWNED BY messages.version;
+
+CREATE INDEX idx_messages_chat_version ON messages (chat_id, version);
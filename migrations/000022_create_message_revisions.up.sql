@@ -0,0 +1,20 @@
+-- ================================================
+-- История сообщений: прежний текст сохраняется при каждой
+-- правке и при удалении; кто удалил — в messages.deleted_by.
+-- ================================================
+ALTER TABLE messages
+    ADD COLUMN deleted_by INT REFERENCES users (id) ON DELETE SET NULL;
+
+CREATE TABLE message_revisions
+(
+    id         SERIAL PRIMARY KEY,
+    message_id INT         NOT NULL,
+    text       TEXT,
+    action     VARCHAR(16) NOT NULL CHECK (action IN ('edit', 'delete')),
+    user_id    INT,
+    created_at TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (message_id) REFERENCES messages (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_message_revisions_message ON message_revisions (message_id, id);
@@ -0,0 +1,3 @@
+-- Ответы выбираются и считаются по родительскому сообщению
+CREATE INDEX idx_messages_parent ON messages (parent_message_id, id)
+    WHERE parent_message_id IS NOT NULL;
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_message_files_message;
+DROP INDEX IF EXISTS idx_message_files_search;
+ALTER TABLE message_files
+    DROP COLUMN IF EXISTS search_vector;
+
+DROP INDEX IF EXISTS idx_messages_search;
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,16 @@
+-- ================================================
+-- Полнотекстовый поиск: текст сообщений — с русской морфологией,
+-- имена файлов — без неё, разбитые на слова по разделителям пути.
+-- ================================================
+ALTER TABLE messages
+    ADD COLUMN search_vector tsvector
+        GENERATED ALWAYS AS (to_tsvector('russian', COALESCE(text, ''))) STORED;
+
+CREATE INDEX idx_messages_search ON messages USING GIN (search_vector);
+
+ALTER TABLE message_files
+    ADD COLUMN search_vector tsvector
+        GENERATED ALWAYS AS (to_tsvector('simple', regexp_replace(file_url, '[/_.-]+', ' ', 'g'))) STORED;
+
+CREATE INDEX idx_message_files_search ON message_files USING GIN (search_vector);
+CREATE INDEX idx_message_files_message ON message_files (message_id);
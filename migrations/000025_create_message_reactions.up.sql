@@ -0,0 +1,14 @@
+-- ================================================
+-- Реакции на сообщения: один эмодзи от пользователя
+-- на сообщение учитывается один раз.
+-- ================================================
+CREATE TABLE message_reactions
+(
+    message_id INT         NOT NULL,
+    user_id    INT         NOT NULL,
+    emoji      VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (message_id, user_id, emoji),
+    FOREIGN KEY (message_id) REFERENCES messages (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
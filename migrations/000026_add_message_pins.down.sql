@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_messages_pinned;
+
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS pinned_by,
+    DROP COLUMN IF EXISTS pinned_at,
+    DROP COLUMN IF EXISTS kind;
@@ -0,0 +1,11 @@
+-- ================================================
+-- Вид сообщения (обычное или объявление владельца чата)
+-- и закрепление сообщений.
+-- ================================================
+ALTER TABLE messages
+    ADD COLUMN kind      VARCHAR(20) NOT NULL DEFAULT 'message'
+        CHECK (kind IN ('message', 'announcement')),
+    ADD COLUMN pinned_at TIMESTAMP,
+    ADD COLUMN pinned_by INT REFERENCES users (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_messages_pinned ON messages (chat_id, pinned_at DESC) WHERE pinned_at IS NOT NULL;
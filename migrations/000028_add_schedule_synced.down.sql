@@ -0,0 +1,2 @@
+ALTER TABLE schedule
+    DROP COLUMN IF EXISTS synced;
@@ -0,0 +1,11 @@
+-- ================================================
+-- Признак записи расписания, созданной сверкой с источником.
+-- Ручные записи сверка не изменяет и не удаляет. Уже существующие
+-- записи не отличить от ручных, поэтому они считаются загруженными
+-- из источника, как и раньше.
+-- ================================================
+ALTER TABLE schedule
+    ADD COLUMN synced BOOLEAN NOT NULL DEFAULT TRUE;
+
+ALTER TABLE schedule
+    ALTER COLUMN synced SET DEFAULT FALSE;
@@ -0,0 +1,16 @@
+DELETE FROM used_refresh_tokens;
+
+DROP INDEX IF EXISTS used_refresh_tokens_expires_idx;
+
+ALTER TABLE used_refresh_tokens
+    DROP COLUMN expires_at,
+    DROP COLUMN family,
+    DROP COLUMN user_id,
+    ADD COLUMN session_id INT NOT NULL REFERENCES tokens (id) ON DELETE CASCADE;
+
+CREATE INDEX used_refresh_tokens_session_idx ON used_refresh_tokens (session_id);
+
+DROP INDEX IF EXISTS tokens_family_idx;
+
+ALTER TABLE tokens
+    DROP COLUMN family;
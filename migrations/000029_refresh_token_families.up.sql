@@ -0,0 +1,22 @@
+-- ================================================
+-- Семейство refresh-токенов отделено от строки сессии: история обменянных
+-- токенов переживает выход, повторный вход с того же устройства и смену роли,
+-- поэтому повторно предъявленный украденный токен по-прежнему распознаётся.
+-- Записи истории хранятся до истечения срока исходного refresh-токена.
+-- ================================================
+ALTER TABLE tokens
+    ADD COLUMN family UUID NOT NULL DEFAULT gen_random_uuid();
+
+CREATE INDEX tokens_family_idx ON tokens (family);
+
+DELETE FROM used_refresh_tokens;
+
+DROP INDEX IF EXISTS used_refresh_tokens_session_idx;
+
+ALTER TABLE used_refresh_tokens
+    DROP COLUMN session_id,
+    ADD COLUMN user_id    INT       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    ADD COLUMN family     UUID      NOT NULL,
+    ADD COLUMN expires_at TIMESTAMP NOT NULL;
+
+CREATE INDEX used_refresh_tokens_expires_idx ON used_refresh_tokens (expires_at);
@@ -0,0 +1,9 @@
+-- ================================================
+-- Версия сообщения берётся из последовательности при выполнении запроса,
+-- а не при фиксации транзакции, поэтому транзакции могут зафиксироваться
+-- не в порядке версий. versioned_at — момент выдачи версии: синхронизация
+-- отдаёт только версии старше небольшой задержки, за которую все
+-- транзакции с меньшими версиями успевают завершиться.
+-- ================================================
+ALTER TABLE messages
+    ADD COLUMN versioned_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp();
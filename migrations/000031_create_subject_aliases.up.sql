@@ -0,0 +1,16 @@
+-- ================================================
+-- Прежние названия объединённых предметов: источник расписания продолжает
+-- присылать их, и синхронизация должна находить предмет, в который их слили,
+-- а не создавать дубликат заново.
+-- ================================================
+CREATE TABLE subject_aliases
+(
+    institution_id INT          NOT NULL,
+    name           VARCHAR(255) NOT NULL,
+    subject_id     INT          NOT NULL,
+    PRIMARY KEY (institution_id, name),
+    FOREIGN KEY (institution_id) REFERENCES institutions (id) ON DELETE CASCADE,
+    FOREIGN KEY (subject_id) REFERENCES subjects (id) ON DELETE CASCADE
+);
+
+CREATE INDEX subject_aliases_subject_idx ON subject_aliases (subject_id);
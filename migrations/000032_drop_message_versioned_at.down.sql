@@ -0,0 +1,2 @@
+ALTER TABLE messages
+    ADD COLUMN versioned_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp();
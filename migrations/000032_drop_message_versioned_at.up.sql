@@ -0,0 +1,7 @@
+-- ================================================
+-- Задержка синхронизации по versioned_at заменена блокировкой версий чата:
+-- версию выдаёт транзакция, держащая pg_advisory_xact_lock на чат, поэтому
+-- версии одного чата фиксируются строго по порядку.
+-- ================================================
+ALTER TABLE messages
+    DROP COLUMN versioned_at;